@@ -0,0 +1,257 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/huboh/go-rest-api/internal/app/user"
+	"github.com/huboh/go-rest-api/internal/migrations"
+	"github.com/huboh/go-rest-api/internal/pkg/database"
+	"github.com/huboh/go-rest-api/internal/pkg/env"
+	"github.com/huboh/go-rest-api/internal/pkg/mail"
+	"github.com/huboh/go-rest-api/internal/pkg/migrate"
+	"github.com/huboh/go-rest-api/internal/pkg/router"
+)
+
+// command is a subcommand of the binary, called with the arguments following its name.
+type command func(args []string) error
+
+// commands are the subcommands of the binary. running it without one starts the server.
+var commands = map[string]command{
+	"migrate": runMigrate,
+	"role":    runRole,
+	"routes":  runRoutes,
+}
+
+const migrateUsage = "usage: migrate <up | down [n] | status | goto <version>>"
+
+// runMigrate applies, reverts or reports the schema migrations of the configured database.
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	if err := env.Load(); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	configs := database.NewConfig()
+
+	if configs.Driver == database.DriverMemory {
+		return errors.New("the memory database driver has no schema to migrate")
+	}
+
+	db, err := database.Open(ctx, configs)
+	if err != nil {
+		return err
+	}
+
+	defer db.Close()
+
+	migrator, err := newMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		return migrator.Up(ctx)
+
+	case "down":
+		n := 1
+
+		if len(args) > 1 {
+			if n, err = strconv.Atoi(args[1]); err != nil {
+				return fmt.Errorf("invalid migration count: %w", err)
+			}
+		}
+
+		return migrator.Down(ctx, n)
+
+	case "goto":
+		if len(args) < 2 {
+			return errors.New(migrateUsage)
+		}
+
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid migration version: %w", err)
+		}
+
+		return migrator.Goto(ctx, version)
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT\tMODIFIED")
+
+		for _, s := range statuses {
+			appliedAt := "pending"
+
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format(time.RFC3339)
+			}
+
+			fmt.Fprintf(w, "%d\t%s\t%s\t%t\n", s.Version, s.Name, appliedAt, s.Modified)
+		}
+
+		return w.Flush()
+	}
+
+	return errors.New(migrateUsage)
+}
+
+// newMigrator creates a migrate.Migrator for db using the embedded migrations of its driver.
+func newMigrator(db *database.DB) (*migrate.Migrator, error) {
+	fsys, err := migrations.FS(db.Driver)
+	if err != nil {
+		return nil, err
+	}
+
+	return migrate.New(db, fsys)
+}
+
+const roleUsage = "usage: role <email> <user | admin>"
+
+// runRole sets the role of the user with the given email, e.g. to create the first admin.
+func runRole(args []string) error {
+	if (len(args) != 2) || !user.Role(args[1]).Valid() {
+		return errors.New(roleUsage)
+	}
+
+	if err := env.Load(); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	configs := database.NewConfig()
+
+	if configs.Driver == database.DriverMemory {
+		return errors.New("the memory database driver doesn't persist users")
+	}
+
+	db, err := database.Open(ctx, configs)
+	if err != nil {
+		return err
+	}
+
+	defer db.Close()
+
+	users := user.NewSQLRepository(db)
+
+	u, err := users.GetByEmail(ctx, user.NormalizeEmail(args[0]))
+	if err != nil {
+		return err
+	}
+
+	u.Role = user.Role(args[1])
+
+	return users.Update(ctx, u)
+}
+
+// runRoutes prints the routes served by the server. they don't depend on the database, so the
+// router is built on top of in-memory stores.
+func runRoutes(args []string) error {
+	if len(args) != 0 {
+		return errors.New("usage: routes")
+	}
+
+	if err := env.Load(); err != nil {
+		return err
+	}
+
+	stores, err := openStores(context.Background(), &database.Config{Driver: database.DriverMemory})
+	if err != nil {
+		return err
+	}
+
+	mailer, err := mail.NewSender()
+	if err != nil {
+		return err
+	}
+
+	// the routers log their registration
+	log.SetOutput(io.Discard)
+
+	return printRoutes(os.Stdout, getRouter(stores, newServices(stores, mailer)))
+}
+
+// printRoutes writes the table of the routes served by r to w.
+func printRoutes(w io.Writer, r *router.Router) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tPATH\tAUTH\tMIDDLEWARES\tTAGS")
+
+	err := r.Walk(func(info router.RouteInfo) error {
+		method := info.Method
+
+		if method == "" {
+			method = "*"
+		}
+
+		auth := listOrDash(info.Auth)
+
+		if info.Public && (len(info.Auth) == 0) {
+			auth = "public"
+		}
+
+		_, err := fmt.Fprintf(
+			tw, "%s\t%s\t%s\t%s\t%s\n",
+			method, info.Path, auth, listOrDash(info.Middlewares), listOrDash(info.Tags),
+		)
+
+		return err
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return tw.Flush()
+}
+
+// checkRoutesAuth returns an error listing the routes of r that declare no auth requirement, see
+// router.Route.Auth, without being public, see router.Route.Public. since the auth guard doesn't wrap every
+// route, a router missing it fails the start of the server rather than serving its routes unguarded.
+func checkRoutesAuth(r *router.Router) error {
+	unguarded := []string{}
+
+	err := r.Walk(func(info router.RouteInfo) error {
+		if (len(info.Auth) == 0) && !info.Public {
+			unguarded = append(unguarded, strings.TrimSpace(info.Method+" "+info.Path))
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if len(unguarded) > 0 {
+		return fmt.Errorf("routes neither guarded nor public: %s", strings.Join(unguarded, ", "))
+	}
+
+	return nil
+}
+
+// listOrDash joins list with commas, or returns "-" when it is empty.
+func listOrDash(list []string) string {
+	if len(list) == 0 {
+		return "-"
+	}
+
+	return strings.Join(list, ",")
+}
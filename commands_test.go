@@ -0,0 +1,38 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/huboh/go-rest-api/internal/pkg/middleware"
+	"github.com/huboh/go-rest-api/internal/pkg/router"
+)
+
+func testGuard(next http.Handler) http.Handler {
+	return next
+}
+
+func TestCheckRoutesAuth(t *testing.T) {
+	var (
+		ok      = http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+		guarded = router.New("/guarded", []middleware.Middleware{testGuard}, []router.Route{{Path: "/a", Method: http.MethodGet, Handler: ok}})
+		public  = router.New("/public", nil, []router.Route{{Path: "/b", Method: http.MethodGet, Handler: ok}})
+		root    = router.New("/", nil, []router.Route{{Path: "/healthz", Method: http.MethodGet, Handler: ok, Public: true}})
+	)
+
+	root.Mount("/guarded", guarded, router.Guarded("authenticated"))
+	root.Mount("/public", public, router.Public())
+
+	if err := checkRoutesAuth(root); err != nil {
+		t.Fatalf("checkRoutesAuth() = %s, want nil", err)
+	}
+
+	root.Add(router.Route{Path: "/open", Method: http.MethodPost, Handler: ok})
+
+	err := checkRoutesAuth(root)
+
+	if (err == nil) || !strings.Contains(err.Error(), "POST /open") || strings.Contains(err.Error(), "/healthz") {
+		t.Fatalf("checkRoutesAuth() = %v, want an error naming POST /open only", err)
+	}
+}
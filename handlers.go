@@ -4,11 +4,54 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/huboh/go-rest-api/internal/pkg/database"
 	"github.com/huboh/go-rest-api/internal/pkg/json"
+	"github.com/huboh/go-rest-api/internal/pkg/router"
 )
 
-func handleGetHealthz(w http.ResponseWriter, r *http.Request) {
-	json.Write(w, json.Response{
-		Data: time.Now().Format(time.RFC3339),
-	})
+// healthz is the response data of the health check endpoint.
+type healthz struct {
+	Time     string           `json:"time"`
+	Database *database.Health `json:"database,omitempty"`
+}
+
+// getHealthzHandler returns the health check handler, reporting the health of db when it isn't nil.
+func getHealthzHandler(db *database.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			code = http.StatusOK
+			data = healthz{Time: time.Now().Format(time.RFC3339)}
+		)
+
+		if db != nil {
+			health := db.Health(r.Context())
+			data.Database = &health
+
+			if health.Status != database.HealthStatusUp {
+				code = http.StatusServiceUnavailable
+			}
+		}
+
+		json.Write(w, json.Response{
+			StatusCode: code,
+			Data:       data,
+		})
+	}
+}
+
+// getRoutesHandler returns the handler listing the routes served by root.
+func getRoutesHandler(root *router.Router) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		routes := []router.RouteInfo{}
+
+		root.Walk(func(info router.RouteInfo) error {
+			routes = append(routes, info)
+			return nil
+		})
+
+		json.Write(w, json.Response{
+			StatusCode: http.StatusOK,
+			Data:       routes,
+		})
+	}
 }
@@ -0,0 +1,246 @@
+package auth
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// TokenType identifies one of the token kinds configured in TokenConfigs.
+type TokenType string
+
+// recognized TokenType
+const (
+	TokenTypeId      = TokenType("id")
+	TokenTypeAccess  = TokenType("access")
+	TokenTypeRefresh = TokenType("refresh")
+)
+
+// Claims is implemented by token claims. Custom claims get it by embedding RegisteredClaims:
+//
+//	type SessionClaims struct {
+//		auth.RegisteredClaims
+//		TenantId string `json:"tid"`
+//	}
+type Claims interface {
+	jwt.Claims
+
+	// Registered returns the registered claims embedded in the claims.
+	Registered() *jwt.RegisteredClaims
+}
+
+// RegisteredClaims are the registered claims defined by RFC 7519. Embed it in custom claims structs.
+type RegisteredClaims struct {
+	jwt.RegisteredClaims
+}
+
+// Registered makes RegisteredClaims meet the Claims interface
+func (rc *RegisteredClaims) Registered() *jwt.RegisteredClaims {
+	return &rc.RegisteredClaims
+}
+
+// SessionClaims are the claims of access and refresh tokens.
+type SessionClaims struct {
+	RegisteredClaims
+
+	// TenantId is the id of the tenant the session is scoped to. it is empty for unscoped sessions.
+	TenantId string `json:"tid,omitempty"`
+}
+
+// tokenOptions holds the options used when creating or verifying a token.
+type tokenOptions struct {
+	audience       []string
+	notBefore      time.Time
+	leeway         *time.Duration
+	requiredClaims []string
+}
+
+// TokenOption customizes how a token is created or verified.
+type TokenOption func(*tokenOptions)
+
+// WithAudience sets the "aud" claim of created tokens. When verifying, it requires
+// the token's "aud" claim to contain at least one of aud.
+func WithAudience(aud ...string) TokenOption {
+	return func(o *tokenOptions) {
+		o.audience = aud
+	}
+}
+
+// WithNotBefore sets the "nbf" claim of created tokens. it is ignored when verifying.
+func WithNotBefore(t time.Time) TokenOption {
+	return func(o *tokenOptions) {
+		o.notBefore = t
+	}
+}
+
+// WithLeeway sets the clock skew allowed when validating the "exp", "nbf" and "iat" claims,
+// overriding the default configured in TokenConfigs. it is ignored when creating tokens.
+func WithLeeway(d time.Duration) TokenOption {
+	return func(o *tokenOptions) {
+		o.leeway = &d
+	}
+}
+
+// WithRequiredClaims requires the named claims, registered or custom, to be present
+// and non-empty when verifying a token. it is ignored when creating tokens.
+func WithRequiredClaims(names ...string) TokenOption {
+	return func(o *tokenOptions) {
+		o.requiredClaims = append(o.requiredClaims, names...)
+	}
+}
+
+func newTokenOptions(opts []TokenOption) *tokenOptions {
+	o := &tokenOptions{}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// CreateToken generates a token of type t from the caller-defined claims. The issuer, issued at and
+// expiration claims are set from the TokenConfigs unless claims already has an expiration.
+func CreateToken[C Claims](tc *TokenConfigs, t TokenType, claims C, opts ...TokenOption) (Jwt, JwtExp, error) {
+	cfg, err := tc.configsFor(t)
+
+	if err != nil {
+		return "", 0, err
+	}
+
+	var (
+		o   = newTokenOptions(opts)
+		now = time.Now()
+		rc  = claims.Registered()
+	)
+
+	rc.Issuer = cfg.issuer
+	rc.IssuedAt = jwt.NewNumericDate(now)
+
+	if rc.ExpiresAt == nil {
+		rc.ExpiresAt = jwt.NewNumericDate(now.Add(cfg.expiresAt))
+	}
+
+	if len(o.audience) > 0 {
+		rc.Audience = o.audience
+	}
+
+	if !o.notBefore.IsZero() {
+		rc.NotBefore = jwt.NewNumericDate(o.notBefore)
+	}
+
+	token, err := cfg.codec.Encode(claims)
+
+	if err != nil {
+		return "", 0, err
+	}
+
+	return Jwt(token), JwtExp(rc.ExpiresAt.Unix()), nil
+}
+
+// VerifyToken parses and validates a token of type t into the caller-defined claims type C.
+//
+// return ErrInvalidToken when token is invalid
+func VerifyToken[C any, PC interface {
+	*C
+	Claims
+}](tc *TokenConfigs, t TokenType, token string, opts ...TokenOption) (*C, error) {
+	cfg, err := tc.configsFor(t)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		o      = newTokenOptions(opts)
+		claims = PC(new(C))
+		leeway = tc.leeway
+	)
+
+	if o.leeway != nil {
+		leeway = *o.leeway
+	}
+
+	parserOpts := []jwt.ParserOption{
+		jwt.WithLeeway(leeway),
+		jwt.WithExpirationRequired(),
+	}
+
+	if cfg.issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(cfg.issuer))
+	}
+
+	if err := cfg.codec.Decode(token, claims, parserOpts...); err != nil {
+		return nil, err
+	}
+
+	if err := checkAudience(claims, o.audience); err != nil {
+		return nil, err
+	}
+
+	if err := checkRequiredClaims(claims, o.requiredClaims); err != nil {
+		return nil, err
+	}
+
+	return (*C)(claims), nil
+}
+
+// checkAudience returns ErrInvalidToken if the "aud" claim doesn't contain any of the expected audiences.
+func checkAudience(claims jwt.Claims, expected []string) error {
+	if len(expected) == 0 {
+		return nil
+	}
+
+	aud, err := claims.GetAudience()
+
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	for _, a := range aud {
+		if slices.Contains(expected, a) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %w", ErrInvalidToken, jwt.ErrTokenInvalidAudience)
+}
+
+// checkRequiredClaims returns ErrInvalidToken if any of the named claims is missing or empty.
+func checkRequiredClaims(claims jwt.Claims, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+
+	values, err := claimsToMap(claims)
+
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if v, ok := values[name]; !ok || isEmptyClaim(v) {
+			return fmt.Errorf("%w: missing required claim \"%s\"", ErrInvalidToken, name)
+		}
+	}
+
+	return nil
+}
+
+// isEmptyClaim reports whether a decoded JSON claim value holds no data.
+func isEmptyClaim(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []any:
+		return len(v) == 0
+	case map[string]any:
+		return len(v) == 0
+	}
+
+	return false
+}
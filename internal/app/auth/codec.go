@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// TokenFormat is the wire format a TokenCodec produces.
+type TokenFormat string
+
+// recognized TokenFormat
+const (
+	TokenFormatJwt            = TokenFormat("jwt")
+	TokenFormatPasetoV4Local  = TokenFormat("paseto.v4.local")
+	TokenFormatPasetoV4Public = TokenFormat("paseto.v4.public")
+)
+
+// TokenCodec encodes claims into a signed or encrypted token string and decodes them back.
+type TokenCodec interface {
+	// Format returns the wire format of the tokens produced by the codec.
+	Format() TokenFormat
+
+	// Encode serializes claims into a token string.
+	Encode(claims jwt.Claims) (string, error)
+
+	// Decode verifies token and deserializes its payload into claims, validating
+	// the registered claims using opts.
+	//
+	// return ErrInvalidToken when token is invalid
+	Decode(token string, claims jwt.Claims, opts ...jwt.ParserOption) error
+}
+
+// newTokenCodec creates the TokenCodec for the given format using the hex or raw key material in key.
+func newTokenCodec(f TokenFormat, key string) (TokenCodec, error) {
+	switch f {
+	case "", TokenFormatJwt:
+		return newJwtCodec([]byte(key)), nil
+
+	case TokenFormatPasetoV4Local:
+		return newPasetoV4LocalCodec(key)
+
+	case TokenFormatPasetoV4Public:
+		return newPasetoV4PublicCodec(key)
+	}
+
+	return nil, fmt.Errorf("unsupported token format \"%s\"", f)
+}
@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// jweContentEncryption is the content encryption algorithm used for every JWE.
+const jweContentEncryption = jose.A256GCM
+
+// TokenEncryption is the JWE key management algorithm used to encrypt tokens.
+type TokenEncryption string
+
+// recognized TokenEncryption
+const (
+	TokenEncryptionNone      = TokenEncryption("")
+	TokenEncryptionDirect    = TokenEncryption(jose.DIRECT)
+	TokenEncryptionA256KW    = TokenEncryption(jose.A256KW)
+	TokenEncryptionA256GCMKW = TokenEncryption(jose.A256GCMKW)
+)
+
+// jweCodec is a TokenCodec that encrypts the tokens of another codec as compact JWEs,
+// so their claims can't be read by the clients holding them.
+type jweCodec struct {
+	inner TokenCodec
+	alg   jose.KeyAlgorithm
+	key   []byte
+}
+
+// newJweCodec wraps inner in a jweCodec using the key management algorithm e and a hex encoded 32 byte key.
+func newJweCodec(inner TokenCodec, e TokenEncryption, hexKey string) (*jweCodec, error) {
+	switch e {
+	case TokenEncryptionDirect, TokenEncryptionA256KW, TokenEncryptionA256GCMKW:
+	default:
+		return nil, fmt.Errorf("unsupported token encryption \"%s\"", e)
+	}
+
+	key, err := hex.DecodeString(hexKey)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid jwe key: %w", err)
+	}
+
+	if len(key) != 32 {
+		return nil, fmt.Errorf("invalid jwe key: expected 32 bytes, got %d", len(key))
+	}
+
+	return &jweCodec{
+		inner: inner,
+		alg:   jose.KeyAlgorithm(e),
+		key:   key,
+	}, nil
+}
+
+func (c *jweCodec) Format() TokenFormat {
+	return c.inner.Format()
+}
+
+func (c *jweCodec) Encode(claims jwt.Claims) (string, error) {
+	token, err := c.inner.Encode(claims)
+
+	if err != nil {
+		return "", err
+	}
+
+	opts := &jose.EncrypterOptions{}
+
+	// nested JWTs are flagged as such (RFC 7519 section 5.2)
+	if c.inner.Format() == TokenFormatJwt {
+		opts = opts.WithContentType("JWT")
+	}
+
+	encrypter, err := jose.NewEncrypter(
+		jweContentEncryption,
+		jose.Recipient{
+			Algorithm: c.alg,
+			Key:       c.key,
+		},
+		opts,
+	)
+
+	if err != nil {
+		return "", err
+	}
+
+	obj, err := encrypter.Encrypt([]byte(token))
+
+	if err != nil {
+		return "", err
+	}
+
+	return obj.CompactSerialize()
+}
+
+func (c *jweCodec) Decode(t string, claims jwt.Claims, opts ...jwt.ParserOption) error {
+	// pin the algorithms so a token can't pick how it gets decrypted
+	obj, err := jose.ParseEncryptedCompact(
+		t,
+		[]jose.KeyAlgorithm{c.alg},
+		[]jose.ContentEncryption{jweContentEncryption},
+	)
+
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	token, err := obj.Decrypt(c.key)
+
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	return c.inner.Decode(string(token), claims, opts...)
+}
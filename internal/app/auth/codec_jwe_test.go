@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/huboh/go-rest-api/internal/pkg/utils"
+
+	"github.com/go-jose/go-jose/v4"
+)
+
+const (
+	testJweKey      = "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+	testOtherJweKey = "1f1e1d1c1b1a191817161514131211100f0e0d0c0b0a09080706050403020100"
+)
+
+func TestJweCodec(t *testing.T) {
+	inner := newJwtCodec([]byte("secret"))
+
+	for _, e := range []TokenEncryption{TokenEncryptionDirect, TokenEncryptionA256KW, TokenEncryptionA256GCMKW} {
+		t.Run(string(e), func(t *testing.T) {
+			var (
+				codec = utils.Must(newJweCodec(inner, e, testJweKey))
+				other = utils.Must(newJweCodec(inner, e, testOtherJweKey))
+			)
+
+			testTokenCodec(t, codec, other)
+		})
+	}
+}
+
+func TestJweCodecPinsAlgorithms(t *testing.T) {
+	var (
+		key   = utils.Must(hex.DecodeString(testJweKey))
+		inner = newJwtCodec([]byte("secret"))
+		codec = utils.Must(newJweCodec(inner, TokenEncryptionA256KW, testJweKey))
+	)
+
+	token, err := inner.Encode(&RegisteredClaims{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		alg  jose.KeyAlgorithm
+		enc  jose.ContentEncryption
+		err  error
+	}{
+		{"pinned algorithms", jose.A256KW, jweContentEncryption, nil},
+		{"other key algorithm", jose.A256GCMKW, jweContentEncryption, ErrInvalidToken},
+		{"other content encryption", jose.A256KW, jose.A128GCM, ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encrypter, err := jose.NewEncrypter(tt.enc, jose.Recipient{Algorithm: tt.alg, Key: key}, nil)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			obj, err := encrypter.Encrypt([]byte(token))
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// the key is right, only the algorithms differ
+			if err := codec.Decode(utils.Must(obj.CompactSerialize()), &RegisteredClaims{}); !errors.Is(err, tt.err) {
+				t.Errorf("Decode() = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestJweCodecRejectsTamperedCiphertext(t *testing.T) {
+	codec := utils.Must(newJweCodec(newJwtCodec([]byte("secret")), TokenEncryptionDirect, testJweKey))
+	token, err := codec.Encode(&RegisteredClaims{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// header.encrypted key.iv.ciphertext.tag
+	parts := strings.Split(token, ".")
+	parts[3] = tamper(parts[3])
+
+	if err := codec.Decode(strings.Join(parts, "."), &RegisteredClaims{}); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Decode() = %v, want ErrInvalidToken", err)
+	}
+}
@@ -0,0 +1,168 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// pasetoTimeClaims are the registered claims that PASETO encodes as RFC 3339
+// strings rather than the numeric dates used by JWT.
+var pasetoTimeClaims = []string{"exp", "nbf", "iat"}
+
+// pasetoV4LocalCodec is a TokenCodec that produces symmetrically encrypted v4.local PASETOs.
+type pasetoV4LocalCodec struct {
+	key paseto.V4SymmetricKey
+}
+
+// newPasetoV4LocalCodec creates a v4.local codec from a hex encoded 32 byte key.
+func newPasetoV4LocalCodec(hexKey string) (*pasetoV4LocalCodec, error) {
+	key, err := paseto.V4SymmetricKeyFromHex(hexKey)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid paseto v4.local key: %w", err)
+	}
+
+	return &pasetoV4LocalCodec{key: key}, nil
+}
+
+func (c *pasetoV4LocalCodec) Format() TokenFormat {
+	return TokenFormatPasetoV4Local
+}
+
+func (c *pasetoV4LocalCodec) Encode(claims jwt.Claims) (string, error) {
+	token, err := newPasetoToken(claims)
+
+	if err != nil {
+		return "", err
+	}
+
+	return token.V4Encrypt(c.key, nil), nil
+}
+
+func (c *pasetoV4LocalCodec) Decode(t string, claims jwt.Claims, opts ...jwt.ParserOption) error {
+	token, err := paseto.MakeParser(nil).ParseV4Local(c.key, t, nil)
+
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	return decodePasetoClaims(token, claims, opts...)
+}
+
+// pasetoV4PublicCodec is a TokenCodec that produces Ed25519 signed v4.public PASETOs.
+type pasetoV4PublicCodec struct {
+	secretKey paseto.V4AsymmetricSecretKey
+	publicKey paseto.V4AsymmetricPublicKey
+}
+
+// newPasetoV4PublicCodec creates a v4.public codec from a hex encoded Ed25519 private key.
+func newPasetoV4PublicCodec(hexKey string) (*pasetoV4PublicCodec, error) {
+	key, err := paseto.NewV4AsymmetricSecretKeyFromHex(hexKey)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid paseto v4.public key: %w", err)
+	}
+
+	return &pasetoV4PublicCodec{
+		secretKey: key,
+		publicKey: key.Public(),
+	}, nil
+}
+
+func (c *pasetoV4PublicCodec) Format() TokenFormat {
+	return TokenFormatPasetoV4Public
+}
+
+func (c *pasetoV4PublicCodec) Encode(claims jwt.Claims) (string, error) {
+	token, err := newPasetoToken(claims)
+
+	if err != nil {
+		return "", err
+	}
+
+	return token.V4Sign(c.secretKey, nil), nil
+}
+
+func (c *pasetoV4PublicCodec) Decode(t string, claims jwt.Claims, opts ...jwt.ParserOption) error {
+	token, err := paseto.MakeParser(nil).ParseV4Public(c.publicKey, t, nil)
+
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	return decodePasetoClaims(token, claims, opts...)
+}
+
+// newPasetoToken builds a PASETO token from claims, converting JWT numeric dates to RFC 3339.
+func newPasetoToken(claims jwt.Claims) (*paseto.Token, error) {
+	values, err := claimsToMap(claims)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range pasetoTimeClaims {
+		if v, ok := values[key].(float64); ok {
+			values[key] = time.Unix(int64(v), 0).UTC().Format(time.RFC3339)
+		}
+	}
+
+	return paseto.MakeToken(values, nil)
+}
+
+// decodePasetoClaims converts the RFC 3339 dates of token back to JWT numeric dates,
+// unmarshals the payload into claims and validates them using opts.
+func decodePasetoClaims(token *paseto.Token, claims jwt.Claims, opts ...jwt.ParserOption) error {
+	values := token.Claims()
+
+	for _, key := range pasetoTimeClaims {
+		v, ok := values[key].(string)
+		if !ok {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return fmt.Errorf("%w: malformed \"%s\" claim", ErrInvalidToken, key)
+		}
+
+		values[key] = t.Unix()
+	}
+
+	data, err := json.Marshal(values)
+
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, claims); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	if err := jwt.NewValidator(opts...).Validate(claims); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	return nil
+}
+
+// claimsToMap round-trips claims through JSON into a generic map.
+func claimsToMap(claims jwt.Claims) (map[string]any, error) {
+	data, err := json.Marshal(claims)
+
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]any{}
+
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
@@ -0,0 +1,27 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/huboh/go-rest-api/internal/pkg/utils"
+
+	"aidanwoods.dev/go-paseto"
+)
+
+func TestPasetoV4LocalCodec(t *testing.T) {
+	var (
+		codec = utils.Must(newPasetoV4LocalCodec(paseto.NewV4SymmetricKey().ExportHex()))
+		other = utils.Must(newPasetoV4LocalCodec(paseto.NewV4SymmetricKey().ExportHex()))
+	)
+
+	testTokenCodec(t, codec, other)
+}
+
+func TestPasetoV4PublicCodec(t *testing.T) {
+	var (
+		codec = utils.Must(newPasetoV4PublicCodec(paseto.NewV4AsymmetricSecretKey().ExportHex()))
+		other = utils.Must(newPasetoV4PublicCodec(paseto.NewV4AsymmetricSecretKey().ExportHex()))
+	)
+
+	testTokenCodec(t, codec, other)
+}
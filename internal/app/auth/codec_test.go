@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testTokenCodec checks that codec decodes the tokens it encodes and rejects tampered and expired
+// tokens, and tokens encoded by other, a codec of the same format using another key.
+func testTokenCodec(t *testing.T, codec TokenCodec, other TokenCodec) {
+	t.Helper()
+
+	var (
+		now    = time.Now().Truncate(time.Second)
+		claims = &SessionClaims{TenantId: "acme"}
+	)
+
+	claims.Subject = "jane"
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(time.Hour))
+
+	token, err := codec.Encode(claims)
+
+	if err != nil {
+		t.Fatalf("Encode() = %s", err)
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		got := &SessionClaims{}
+
+		if err := codec.Decode(token, got, jwt.WithExpirationRequired()); err != nil {
+			t.Fatalf("Decode() = %s", err)
+		}
+
+		if (got.Subject != "jane") || (got.TenantId != "acme") || !got.ExpiresAt.Equal(now.Add(time.Hour)) {
+			t.Errorf("Decode() = %+v, want the encoded claims", got)
+		}
+	})
+
+	t.Run("tampered token", func(t *testing.T) {
+		if err := codec.Decode(tamper(token), &SessionClaims{}); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Decode() = %v, want ErrInvalidToken", err)
+		}
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		if err := other.Decode(token, &SessionClaims{}); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Decode() = %v, want ErrInvalidToken", err)
+		}
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		expired := &SessionClaims{}
+		expired.Subject = "jane"
+		expired.ExpiresAt = jwt.NewNumericDate(now.Add(-time.Minute))
+
+		token, err := codec.Encode(expired)
+
+		if err != nil {
+			t.Fatalf("Encode() = %s", err)
+		}
+
+		if err := codec.Decode(token, &SessionClaims{}, jwt.WithExpirationRequired()); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Decode() = %v, want ErrInvalidToken", err)
+		}
+	})
+}
+
+// tamper returns token with a character of its middle changed.
+func tamper(token string) string {
+	i := len(token) / 2
+	c := "A"
+
+	if strings.HasPrefix(token[i:], c) {
+		c = "B"
+	}
+
+	return token[:i] + c + token[i+1:]
+}
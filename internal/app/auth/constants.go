@@ -1,3 +1,5 @@
 package auth
 
 const RouterPath = "/auth"
+
+const OAuthRouterPath = "/oauth"
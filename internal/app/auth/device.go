@@ -0,0 +1,348 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/huboh/go-rest-api/internal/pkg/env"
+	"github.com/huboh/go-rest-api/internal/pkg/utils"
+)
+
+const (
+	// deviceCodeGrantType is the grant type used to poll the token endpoint (RFC 8628 section 3.4).
+	deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+	// userCodeCharset is the set of characters user codes are built from. it has
+	// no vowels so codes never spell words, as recommended by RFC 8628 section 6.1.
+	userCodeCharset = "BCDFGHJKLMNPQRSTVWXZ"
+
+	// userCodeLength is the number of characters in a user code, excluding the separator.
+	userCodeLength = 8
+
+	// defDeviceCodeExpiration is the default lifetime of a device authorization request.
+	defDeviceCodeExpiration = time.Minute * 10
+
+	// defDevicePollInterval is the default minimum time clients must wait between polls.
+	defDevicePollInterval = time.Second * 5
+
+	// slowDownIncrement is added to a grant's polling interval each time a client polls too fast.
+	slowDownIncrement = time.Second * 5
+)
+
+// device authorization errors, their messages are the error codes defined by RFC 6749 section 5.2
+// and RFC 8628 section 3.5.
+var (
+	ErrInvalidRequest       = errors.New("invalid_request")
+	ErrAuthorizationPending = errors.New("authorization_pending")
+	ErrSlowDown             = errors.New("slow_down")
+	ErrAccessDenied         = errors.New("access_denied")
+	ErrExpiredToken         = errors.New("expired_token")
+	ErrInvalidGrant         = errors.New("invalid_grant")
+	ErrUnsupportedGrantType = errors.New("unsupported_grant_type")
+
+	// ErrGrantDecided is returned when approving or denying a grant that was already approved or denied.
+	ErrGrantDecided = fmt.Errorf("%w: the device authorization was already approved or denied", ErrInvalidGrant)
+)
+
+// deviceConfigs holds the configuration for device authorization requests.
+type deviceConfigs struct {
+	// verificationUri is the end-user page where user codes are entered.
+	verificationUri string
+
+	// expiration is the lifetime of a device authorization request.
+	expiration time.Duration
+
+	// interval is the minimum time clients must wait between token polls.
+	interval time.Duration
+}
+
+// newDeviceConfigs initializes a new deviceConfigs instance by reading environment variables,
+// falling back to defaults when they are unset.
+func newDeviceConfigs() *deviceConfigs {
+	dc := &deviceConfigs{
+		verificationUri: env.Get("OAUTH_DEVICE_VERIFICATION_URI"),
+		expiration:      defDeviceCodeExpiration,
+		interval:        defDevicePollInterval,
+	}
+
+	if dc.verificationUri == "" {
+		dc.verificationUri = utils.Must(url.JoinPath(env.Get("JWT_ISSUER"), "/device"))
+	}
+
+	if exp := env.Get("OAUTH_DEVICE_CODE_EXPIRATION"); exp != "" {
+		dc.expiration = utils.Must(time.ParseDuration(exp))
+	}
+
+	if interval := env.Get("OAUTH_DEVICE_POLL_INTERVAL"); interval != "" {
+		dc.interval = utils.Must(time.ParseDuration(interval))
+	}
+
+	return dc
+}
+
+// DeviceGrantStatus is the approval state of a device authorization request.
+type DeviceGrantStatus string
+
+// recognized DeviceGrantStatus
+const (
+	DeviceGrantPending  = DeviceGrantStatus("pending")
+	DeviceGrantApproved = DeviceGrantStatus("approved")
+	DeviceGrantDenied   = DeviceGrantStatus("denied")
+)
+
+// DeviceGrant represents a pending device authorization request.
+type DeviceGrant struct {
+	ClientId   string
+	Scope      string
+	DeviceCode string
+	UserCode   string
+	Status     DeviceGrantStatus
+
+	// Subject is the id of the user who approved the request.
+	Subject string
+
+	ExpiresAt  time.Time
+	Interval   time.Duration
+	LastPollAt time.Time
+}
+
+// expired reports whether the grant's lifetime has elapsed at t.
+func (g *DeviceGrant) expired(t time.Time) bool {
+	return !t.Before(g.ExpiresAt)
+}
+
+// DeviceGrantStore persists device authorization requests.
+type DeviceGrantStore interface {
+	// Create stores a new grant.
+	Create(ctx context.Context, g DeviceGrant) error
+
+	// GetByDeviceCode returns the grant with device code c, or ErrInvalidGrant.
+	GetByDeviceCode(ctx context.Context, c string) (DeviceGrant, error)
+
+	// GetByUserCode returns the grant with user code c, or ErrInvalidGrant.
+	GetByUserCode(ctx context.Context, c string) (DeviceGrant, error)
+
+	// RecordPoll sets the polling interval of the grant with device code c and the time it was last
+	// polled at, leaving its status as is, or returns ErrInvalidGrant.
+	RecordPoll(ctx context.Context, c string, interval time.Duration, at time.Time) error
+
+	// Decide sets the status of the pending grant with device code c to status, approved or denied by
+	// the user with id subject. it returns ErrGrantDecided when the grant isn't pending, or no longer
+	// exists. it must only succeed for one concurrent caller.
+	Decide(ctx context.Context, c string, status DeviceGrantStatus, subject string) error
+
+	// Delete removes the grant with device code c, or returns ErrInvalidGrant when there is none.
+	// Since grants are redeemed by deleting them, it must only succeed for one concurrent caller.
+	Delete(ctx context.Context, c string) error
+
+	// ListBySubject returns the unexpired grants approved or denied by the user with id subject.
+	ListBySubject(ctx context.Context, subject string) ([]DeviceGrant, error)
+
+	// DeleteBySubject removes every grant approved or denied by the user with id subject.
+	DeleteBySubject(ctx context.Context, subject string) error
+}
+
+// MemoryDeviceGrantStore is an in-memory DeviceGrantStore.
+type MemoryDeviceGrantStore struct {
+	mu          sync.Mutex
+	grants      map[string]DeviceGrant
+	userCodeIdx map[string]string
+}
+
+// NewMemoryDeviceGrantStore creates an empty MemoryDeviceGrantStore.
+func NewMemoryDeviceGrantStore() *MemoryDeviceGrantStore {
+	return &MemoryDeviceGrantStore{
+		grants:      map[string]DeviceGrant{},
+		userCodeIdx: map[string]string{},
+	}
+}
+
+func (s *MemoryDeviceGrantStore) Create(ctx context.Context, g DeviceGrant) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.evictExpired(time.Now())
+
+	if _, ok := s.userCodeIdx[g.UserCode]; ok {
+		return errors.New("user code already in use")
+	}
+
+	s.grants[g.DeviceCode] = g
+	s.userCodeIdx[g.UserCode] = g.DeviceCode
+
+	return nil
+}
+
+func (s *MemoryDeviceGrantStore) GetByDeviceCode(ctx context.Context, c string) (DeviceGrant, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g, ok := s.grants[c]
+	if !ok {
+		return DeviceGrant{}, ErrInvalidGrant
+	}
+
+	return g, nil
+}
+
+func (s *MemoryDeviceGrantStore) GetByUserCode(ctx context.Context, c string) (DeviceGrant, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g, ok := s.grants[s.userCodeIdx[c]]
+	if !ok {
+		return DeviceGrant{}, ErrInvalidGrant
+	}
+
+	return g, nil
+}
+
+func (s *MemoryDeviceGrantStore) RecordPoll(ctx context.Context, c string, interval time.Duration, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g, ok := s.grants[c]
+	if !ok {
+		return ErrInvalidGrant
+	}
+
+	g.Interval = interval
+	g.LastPollAt = at
+	s.grants[c] = g
+
+	return nil
+}
+
+func (s *MemoryDeviceGrantStore) Decide(ctx context.Context, c string, status DeviceGrantStatus, subject string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g, ok := s.grants[c]
+	if !ok || (g.Status != DeviceGrantPending) {
+		return ErrGrantDecided
+	}
+
+	g.Status = status
+	g.Subject = subject
+	s.grants[c] = g
+
+	return nil
+}
+
+func (s *MemoryDeviceGrantStore) Delete(ctx context.Context, c string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g, ok := s.grants[c]
+	if !ok {
+		return ErrInvalidGrant
+	}
+
+	delete(s.userCodeIdx, g.UserCode)
+	delete(s.grants, c)
+
+	return nil
+}
+
+func (s *MemoryDeviceGrantStore) ListBySubject(ctx context.Context, subject string) ([]DeviceGrant, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.evictExpired(time.Now())
+
+	grants := []DeviceGrant{}
+
+	for _, g := range s.grants {
+		if g.Subject == subject {
+			grants = append(grants, g)
+		}
+	}
+
+	return grants, nil
+}
+
+func (s *MemoryDeviceGrantStore) DeleteBySubject(ctx context.Context, subject string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for c, g := range s.grants {
+		if g.Subject == subject {
+			delete(s.userCodeIdx, g.UserCode)
+			delete(s.grants, c)
+		}
+	}
+
+	return nil
+}
+
+// evictExpired removes grants that expired before t. callers must hold s.mu.
+func (s *MemoryDeviceGrantStore) evictExpired(t time.Time) {
+	for c, g := range s.grants {
+		if g.expired(t) {
+			delete(s.userCodeIdx, g.UserCode)
+			delete(s.grants, c)
+		}
+	}
+}
+
+// newDeviceCode returns a high-entropy, url-safe device code.
+func newDeviceCode() (string, error) {
+	b := make([]byte, 32)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// newUserCode returns a short, human-typeable user code in the form "XXXX-XXXX".
+func newUserCode() (string, error) {
+	var (
+		sb  strings.Builder
+		max = big.NewInt(int64(len(userCodeCharset)))
+	)
+
+	for i := 0; i < userCodeLength; i++ {
+		if i == userCodeLength/2 {
+			sb.WriteByte('-')
+		}
+
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+
+		sb.WriteByte(userCodeCharset[n.Int64()])
+	}
+
+	return sb.String(), nil
+}
+
+// normalizeUserCode uppercases c and strips separators and whitespace so users
+// can type codes however they like, then re-inserts the canonical separator.
+func normalizeUserCode(c string) string {
+	c = strings.Map(
+		func(r rune) rune {
+			if r == '-' || r == ' ' {
+				return -1
+			}
+			return r
+		},
+		strings.ToUpper(c),
+	)
+
+	if len(c) != userCodeLength {
+		return c
+	}
+
+	return c[:userCodeLength/2] + "-" + c[userCodeLength/2:]
+}
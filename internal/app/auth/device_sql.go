@@ -0,0 +1,180 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/huboh/go-rest-api/internal/pkg/database"
+)
+
+// deviceGrantColumns are the columns selected by every grant query, in scanDeviceGrant order.
+const deviceGrantColumns = "device_code, user_code, client_id, scope, status, subject, expires_at, interval_ms, last_poll_at"
+
+// SQLDeviceGrantStore is a DeviceGrantStore backed by a SQL database.
+type SQLDeviceGrantStore struct {
+	db *database.DB
+}
+
+// NewSQLDeviceGrantStore creates a SQLDeviceGrantStore using db.
+func NewSQLDeviceGrantStore(db *database.DB) *SQLDeviceGrantStore {
+	return &SQLDeviceGrantStore{db: db}
+}
+
+func (s *SQLDeviceGrantStore) Create(ctx context.Context, g DeviceGrant) error {
+	// expired grants are cleaned up lazily, as new ones are created
+	_, err := s.db.Executor(ctx).ExecContext(ctx, s.db.Rebind("DELETE FROM device_grants WHERE expires_at <= ?"), time.Now().UTC())
+
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Executor(ctx).ExecContext(
+		ctx,
+		s.db.Rebind("INSERT INTO device_grants ("+deviceGrantColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)"),
+		g.DeviceCode, g.UserCode, g.ClientId, g.Scope, g.Status, g.Subject,
+		g.ExpiresAt.UTC(), g.Interval.Milliseconds(), nullTime(g.LastPollAt),
+	)
+
+	if database.IsUniqueViolation(err) {
+		return errors.New("user code already in use")
+	}
+
+	return err
+}
+
+func (s *SQLDeviceGrantStore) GetByDeviceCode(ctx context.Context, c string) (DeviceGrant, error) {
+	return s.get(ctx, "device_code", c)
+}
+
+func (s *SQLDeviceGrantStore) GetByUserCode(ctx context.Context, c string) (DeviceGrant, error) {
+	return s.get(ctx, "user_code", c)
+}
+
+func (s *SQLDeviceGrantStore) RecordPoll(ctx context.Context, c string, interval time.Duration, at time.Time) error {
+	res, err := s.db.Executor(ctx).ExecContext(
+		ctx,
+		s.db.Rebind("UPDATE device_grants SET interval_ms = ?, last_poll_at = ? WHERE device_code = ?"),
+		interval.Milliseconds(), nullTime(at), c,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); (err == nil) && (n == 0) {
+		return ErrInvalidGrant
+	}
+
+	return err
+}
+
+func (s *SQLDeviceGrantStore) Decide(ctx context.Context, c string, status DeviceGrantStatus, subject string) error {
+	// the status condition lets a single concurrent caller decide
+	res, err := s.db.Executor(ctx).ExecContext(
+		ctx,
+		s.db.Rebind("UPDATE device_grants SET status = ?, subject = ? WHERE device_code = ? AND status = ?"),
+		status, subject, c, DeviceGrantPending,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); (err == nil) && (n == 0) {
+		return ErrGrantDecided
+	}
+
+	return err
+}
+
+func (s *SQLDeviceGrantStore) Delete(ctx context.Context, c string) error {
+	res, err := s.db.Executor(ctx).ExecContext(ctx, s.db.Rebind("DELETE FROM device_grants WHERE device_code = ?"), c)
+
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); (err == nil) && (n == 0) {
+		return ErrInvalidGrant
+	}
+
+	return err
+}
+
+func (s *SQLDeviceGrantStore) ListBySubject(ctx context.Context, subject string) ([]DeviceGrant, error) {
+	rows, err := s.db.Executor(ctx).QueryContext(
+		ctx,
+		s.db.Rebind("SELECT "+deviceGrantColumns+" FROM device_grants WHERE subject = ? AND expires_at > ?"),
+		subject, time.Now().UTC(),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	grants := []DeviceGrant{}
+
+	for rows.Next() {
+		g, err := scanDeviceGrant(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		grants = append(grants, g)
+	}
+
+	return grants, rows.Err()
+}
+
+func (s *SQLDeviceGrantStore) DeleteBySubject(ctx context.Context, subject string) error {
+	_, err := s.db.Executor(ctx).ExecContext(ctx, s.db.Rebind("DELETE FROM device_grants WHERE subject = ?"), subject)
+	return err
+}
+
+// get returns the grant whose column equals val. column must be a trusted identifier.
+func (s *SQLDeviceGrantStore) get(ctx context.Context, column string, val string) (DeviceGrant, error) {
+	g, err := scanDeviceGrant(s.db.Executor(ctx).QueryRowContext(
+		ctx,
+		s.db.Rebind("SELECT "+deviceGrantColumns+" FROM device_grants WHERE "+column+" = ?"),
+		val,
+	))
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return DeviceGrant{}, ErrInvalidGrant
+	}
+
+	return g, err
+}
+
+// scanDeviceGrant scans a row of deviceGrantColumns.
+func scanDeviceGrant(row interface{ Scan(...any) error }) (DeviceGrant, error) {
+	var (
+		g          DeviceGrant
+		intervalMs int64
+		lastPollAt sql.NullTime
+	)
+
+	err := row.Scan(
+		&g.DeviceCode, &g.UserCode, &g.ClientId, &g.Scope, &g.Status, &g.Subject,
+		&g.ExpiresAt, &intervalMs, &lastPollAt,
+	)
+
+	if err != nil {
+		return DeviceGrant{}, err
+	}
+
+	g.Interval = time.Duration(intervalMs) * time.Millisecond
+	g.LastPollAt = lastPollAt.Time
+
+	return g, nil
+}
+
+// nullTime converts the zero time to a SQL NULL.
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t.UTC(), Valid: !t.IsZero()}
+}
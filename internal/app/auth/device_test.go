@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/huboh/go-rest-api/internal/pkg/database"
+	"github.com/huboh/go-rest-api/internal/pkg/database/dbtest"
+)
+
+func TestMemoryDeviceGrantStore(t *testing.T) {
+	testDeviceGrantStore(t, NewMemoryDeviceGrantStore())
+}
+
+func TestSQLDeviceGrantStore(t *testing.T) {
+	dbtest.Run(t, func(t *testing.T, db *database.DB) {
+		testDeviceGrantStore(t, NewSQLDeviceGrantStore(db))
+	})
+}
+
+// testDeviceGrantStore checks that store meets the DeviceGrantStore contract.
+func testDeviceGrantStore(t *testing.T, store DeviceGrantStore) {
+	var (
+		ctx   = context.Background()
+		now   = time.Now().UTC().Truncate(time.Second)
+		grant = DeviceGrant{
+			ClientId:   "cli",
+			DeviceCode: "device",
+			UserCode:   "BCDF-GHJK",
+			Status:     DeviceGrantPending,
+			ExpiresAt:  now.Add(time.Minute),
+			Interval:   time.Second,
+		}
+	)
+
+	if err := store.Create(ctx, grant); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Decide(ctx, grant.DeviceCode, DeviceGrantApproved, "jane"); err != nil {
+		t.Fatalf("Decide() = %s", err)
+	}
+
+	// a poll that read the grant while it was pending doesn't revert the approval
+	if err := store.RecordPoll(ctx, grant.DeviceCode, 2*time.Second, now); err != nil {
+		t.Fatalf("RecordPoll() = %s", err)
+	}
+
+	got, err := store.GetByDeviceCode(ctx, grant.DeviceCode)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if (got.Status != DeviceGrantApproved) || (got.Subject != "jane") || (got.Interval != 2*time.Second) || !got.LastPollAt.Equal(now) {
+		t.Errorf("grant = %+v, want it approved by jane and polled at %s every 2s", got, now)
+	}
+
+	if err := store.Decide(ctx, grant.DeviceCode, DeviceGrantDenied, ""); !errors.Is(err, ErrGrantDecided) {
+		t.Errorf("Decide() of a decided grant = %v, want ErrGrantDecided", err)
+	}
+
+	if err := store.RecordPoll(ctx, "unknown", time.Second, now); !errors.Is(err, ErrInvalidGrant) {
+		t.Errorf("RecordPoll() of an unknown grant = %v, want ErrInvalidGrant", err)
+	}
+
+	// concurrent decisions on a pending grant
+	grant.DeviceCode, grant.UserCode = "concurrent", "LMNP-QRST"
+
+	if err := store.Create(ctx, grant); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		decided = 0
+	)
+
+	for i := 0; i < 8; i++ {
+		status := DeviceGrantApproved
+
+		if i%2 == 1 {
+			status = DeviceGrantDenied
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			err := store.Decide(ctx, grant.DeviceCode, status, "jane")
+
+			if (err != nil) && !errors.Is(err, ErrGrantDecided) {
+				t.Errorf("Decide() = %s", err)
+			}
+
+			if err == nil {
+				mu.Lock()
+				decided++
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if decided != 1 {
+		t.Errorf("%d concurrent decisions succeeded, want 1", decided)
+	}
+}
+
+func TestVerifyDeviceDecidesOnce(t *testing.T) {
+	var (
+		ctx = context.Background()
+		s   = newTestService(t)
+	)
+
+	auth, err := s.authorizeDevice(ctx, deviceAuthorizationRequest{ClientId: "cli"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.verifyDevice(ctx, "jane", deviceVerificationRequest{UserCode: auth.UserCode, Approve: true}); err != nil {
+		t.Fatalf("approving = %s", err)
+	}
+
+	if _, err := s.verifyDevice(ctx, "john", deviceVerificationRequest{UserCode: auth.UserCode}); !errors.Is(err, ErrGrantDecided) {
+		t.Errorf("denying an approved grant = %v, want ErrGrantDecided", err)
+	}
+
+	if name := oauthErrorName(ErrGrantDecided); name != "invalid_grant" {
+		t.Errorf("error name = %q, want invalid_grant", name)
+	}
+}
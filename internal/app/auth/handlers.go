@@ -1,70 +1,169 @@
 package auth
 
 import (
+	"context"
+	jsonEncoder "encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
 	"net/http"
+	"net/url"
+	"strings"
 
-	"github.com/huboh/go-rest-api/internal/pkg/json"
+	"github.com/huboh/go-rest-api/internal/app/user"
+	"github.com/huboh/go-rest-api/internal/pkg/router"
 )
 
-func handleLogin(w http.ResponseWriter, r *http.Request) {
-	var (
-		err   error
-		creds loginCredentials
-	)
+// formContentType is the content type of the bodies of OAuth requests (RFC 6749 appendix B).
+const formContentType = "application/x-www-form-urlencoded"
 
-	err = json.UnmarshalBody(r, &creds)
+// oauthErrors are the errors answered to OAuth clients with their code, other errors being server errors.
+var oauthErrors = []error{
+	ErrInvalidRequest,
+	ErrInvalidGrant,
+	ErrUnsupportedGrantType,
+	ErrAuthorizationPending,
+	ErrSlowDown,
+	ErrAccessDenied,
+	ErrExpiredToken,
+}
+
+// oauthOptions are the options of the typed handlers of the oauth routes answering users, whose errors
+// are bad requests named after their OAuth error code.
+var oauthOptions = []router.HandleOption{
+	router.WithErrorStatus(func(error) int { return http.StatusBadRequest }),
+	router.WithErrorName(oauthErrorName),
+}
+
+// oauthErrorName returns the OAuth error code of err, e.g. "invalid_request" or "authorization_pending".
+func oauthErrorName(err error) string {
+	if errors.Is(err, router.ErrInvalidRequest) {
+		return ErrInvalidRequest.Error()
+	}
+
+	for _, oauthErr := range oauthErrors {
+		if errors.Is(err, oauthErr) {
+			return oauthErr.Error()
+		}
+	}
+
+	return err.Error()
+}
+
+// handleDeviceAuthorization starts a device authorization, see RFC 8628 section 3.1. the client id and
+// scope are read from the form encoded body.
+func (s *Service) handleDeviceAuthorization(w http.ResponseWriter, r *http.Request) {
+	form, err := parseOAuthForm(r)
 
 	if err != nil {
-		json.Write(w, json.Response{
-			StatusCode: http.StatusBadRequest,
-			Error:      json.ErrorFromErr(err, "", ""),
-		})
+		writeOAuthError(w, err)
 		return
 	}
 
-	result, err := login(r.Context(), creds)
+	req := deviceAuthorizationRequest{
+		ClientId: form.Get("client_id"),
+		Scope:    form.Get("scope"),
+	}
+
+	if err := req.Validate(); err != nil {
+		writeOAuthError(w, fmt.Errorf("%w: %w", ErrInvalidRequest, err))
+		return
+	}
+
+	res, err := s.authorizeDevice(r.Context(), req)
 
 	if err != nil {
-		json.Write(w, json.Response{
-			StatusCode: http.StatusBadRequest,
-			Error:      json.ErrorFromErr(err, "", ""),
-		})
+		writeOAuthError(w, err)
 		return
 	}
 
-	json.Write(w, json.Response{
-		Data: result,
-	})
+	writeOAuth(w, http.StatusOK, res)
 }
 
-func handleSignup(w http.ResponseWriter, r *http.Request) {
-	result, err := signUp(r.Context())
+// handleDeviceToken answers the polls of devices for their tokens, see RFC 8628 section 3.4. the grant
+// type, device code and client id are read from the form encoded body.
+func (s *Service) handleDeviceToken(w http.ResponseWriter, r *http.Request) {
+	form, err := parseOAuthForm(r)
 
 	if err != nil {
-		json.Write(w, json.Response{
-			StatusCode: http.StatusBadRequest,
-			Error:      json.ErrorFromErr(err, "", ""),
-		})
+		writeOAuthError(w, err)
 		return
 	}
 
-	json.Write(w, json.Response{
-		Data: result,
-	})
-}
+	req := deviceTokenRequest{
+		GrantType:  form.Get("grant_type"),
+		DeviceCode: form.Get("device_code"),
+		ClientId:   form.Get("client_id"),
+	}
+
+	for _, name := range []string{"grant_type", "device_code"} {
+		if form.Get(name) == "" {
+			writeOAuthError(w, fmt.Errorf("%w: %s is required", ErrInvalidRequest, name))
+			return
+		}
+	}
 
-func handleRefresh(w http.ResponseWriter, r *http.Request) {
-	result, err := refresh(r.Context())
+	res, err := s.pollDeviceToken(r.Context(), req)
 
 	if err != nil {
-		json.Write(w, json.Response{
-			StatusCode: http.StatusBadRequest,
-			Error:      json.ErrorFromErr(err, "", ""),
-		})
+		writeOAuthError(w, err)
 		return
 	}
 
-	json.Write(w, json.Response{
-		Data: result,
-	})
+	writeOAuth(w, http.StatusOK, res)
+}
+
+// handleDeviceVerification approves or denies a device authorization as the authenticated user.
+func (s *Service) handleDeviceVerification(ctx context.Context, req deviceVerificationRequest) (deviceVerificationResponse, error) {
+	subject, _ := user.UserFromContext(ctx)
+	return s.verifyDevice(ctx, subject, req)
+}
+
+// parseOAuthForm returns the parameters of the form encoded body of r, or ErrInvalidRequest when r has
+// another content type, can't be parsed or repeats a parameter (RFC 6749 section 3.2).
+func parseOAuthForm(r *http.Request) (url.Values, error) {
+	if t, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); t != formContentType {
+		return nil, fmt.Errorf("%w: the content type must be %s", ErrInvalidRequest, formContentType)
+	}
+
+	if err := r.ParseForm(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRequest, err)
+	}
+
+	for name, vals := range r.PostForm {
+		if len(vals) > 1 {
+			return nil, fmt.Errorf("%w: %s is repeated", ErrInvalidRequest, name)
+		}
+	}
+
+	return r.PostForm, nil
+}
+
+// writeOAuthError responds with the error response of err, see RFC 6749 section 5.2. errors that aren't
+// OAuth errors are logged and answered as server errors.
+func writeOAuthError(w http.ResponseWriter, err error) {
+	for _, oauthErr := range oauthErrors {
+		if errors.Is(err, oauthErr) {
+			code := oauthErr.Error()
+			desc := strings.TrimPrefix(strings.TrimPrefix(err.Error(), code), ": ")
+
+			writeOAuth(w, http.StatusBadRequest, oauthErrorResponse{Error: code, ErrorDescription: desc})
+			return
+		}
+	}
+
+	log.Printf("oauth request failed: %s\n", err)
+	writeOAuth(w, http.StatusInternalServerError, oauthErrorResponse{Error: "server_error"})
+}
+
+// writeOAuth responds with the status code and body v, which OAuth clients read unwrapped from the json.Response envelope.
+func writeOAuth(w http.ResponseWriter, code int, v any) {
+	h := w.Header()
+	h.Set("Content-Type", "application/json; charset=utf-8")
+	h.Set("Cache-Control", "no-store")
+	h.Set("Pragma", "no-cache")
+
+	w.WriteHeader(code)
+	jsonEncoder.NewEncoder(w).Encode(v)
 }
@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"context"
+	jsonEncoder "encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/huboh/go-rest-api/internal/app/user"
+	"github.com/huboh/go-rest-api/internal/pkg/database"
+)
+
+// newTestService creates a Service storing its users and grants in memory, with jwt tokens.
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+
+	for key, val := range map[string]string{
+		"JWT_ISSUER":                   "https://www.example.com",
+		"JWT_ID_TOKEN_SECRET":          "id secret",
+		"JWT_ACCESS_TOKEN_SECRET":      "access secret",
+		"JWT_REFRESH_TOKEN_SECRET":     "refresh secret",
+		"JWT_ID_TOKEN_EXPIRATION":      "5m",
+		"JWT_ACCESS_TOKEN_EXPIRATION":  "5m",
+		"JWT_REFRESH_TOKEN_EXPIRATION": "24h",
+	} {
+		t.Setenv(key, val)
+	}
+
+	return NewService(
+		user.NewMemoryRepository(),
+		NewMemoryDeviceGrantStore(),
+		NewMemoryRevocationStore(),
+		database.NoopTxManager{},
+		NewTokenConfigs(),
+	)
+}
+
+// postForm serves a form encoded POST request of form to h, decoding the json body of the response into v.
+func postForm(t *testing.T, h http.HandlerFunc, form url.Values, v any) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var (
+		w = httptest.NewRecorder()
+		r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+	)
+
+	r.Header.Set("Content-Type", formContentType)
+	h(w, r)
+
+	if err := jsonEncoder.NewDecoder(w.Body).Decode(v); err != nil {
+		t.Fatalf("decoding the response: %s", err)
+	}
+
+	return w
+}
+
+func TestDeviceFlow(t *testing.T) {
+	s := newTestService(t)
+
+	var auth deviceAuthorizationResponse
+	w := postForm(t, s.handleDeviceAuthorization, url.Values{"client_id": {"cli"}, "scope": {"read"}}, &auth)
+
+	if (w.Code != http.StatusOK) || (auth.DeviceCode == "") || (auth.UserCode == "") || (auth.ExpiresIn <= 0) {
+		t.Fatalf("device authorization = %d %+v", w.Code, auth)
+	}
+
+	poll := url.Values{"grant_type": {deviceCodeGrantType}, "device_code": {auth.DeviceCode}, "client_id": {"cli"}}
+
+	var pending oauthErrorResponse
+	w = postForm(t, s.handleDeviceToken, poll, &pending)
+
+	if (w.Code != http.StatusBadRequest) || (pending.Error != "authorization_pending") {
+		t.Fatalf("pending poll = %d %+v, want 400 authorization_pending", w.Code, pending)
+	}
+
+	if got := w.Header().Get("Cache-Control"); got != "no-store" {
+		t.Errorf("Cache-Control = %q, want no-store", got)
+	}
+
+	u := &user.User{Email: "jane@example.com", Username: "jane"}
+
+	if err := s.users.Create(context.Background(), u); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.verifyDevice(context.Background(), u.Id, deviceVerificationRequest{UserCode: auth.UserCode, Approve: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	var tokens deviceTokenResponse
+	w = postForm(t, s.handleDeviceToken, poll, &tokens)
+
+	if (w.Code != http.StatusOK) || (tokens.AccessToken == "") || (tokens.TokenType != "Bearer") || (tokens.ExpiresIn <= 0) {
+		t.Fatalf("approved poll = %d %+v", w.Code, tokens)
+	}
+
+	var redeemed oauthErrorResponse
+	w = postForm(t, s.handleDeviceToken, poll, &redeemed)
+
+	if (w.Code != http.StatusBadRequest) || (redeemed.Error != "invalid_grant") {
+		t.Fatalf("redeemed poll = %d %+v, want 400 invalid_grant", w.Code, redeemed)
+	}
+}
+
+func TestDeviceTokenErrors(t *testing.T) {
+	s := newTestService(t)
+
+	tests := []struct {
+		name string
+		form url.Values
+		want string
+	}{
+		{"missing grant type", url.Values{"device_code": {"code"}}, "invalid_request"},
+		{"missing device code", url.Values{"grant_type": {deviceCodeGrantType}}, "invalid_request"},
+		{"repeated parameter", url.Values{"grant_type": {deviceCodeGrantType, deviceCodeGrantType}, "device_code": {"code"}}, "invalid_request"},
+		{"other grant type", url.Values{"grant_type": {"password"}, "device_code": {"code"}}, "unsupported_grant_type"},
+		{"unknown device code", url.Values{"grant_type": {deviceCodeGrantType}, "device_code": {"code"}}, "invalid_grant"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var res oauthErrorResponse
+
+			if w := postForm(t, s.handleDeviceToken, tt.form, &res); (w.Code != http.StatusBadRequest) || (res.Error != tt.want) {
+				t.Errorf("got %d %+v, want 400 %s", w.Code, res, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeviceAuthorizationRejectsJSON(t *testing.T) {
+	var (
+		s = newTestService(t)
+		w = httptest.NewRecorder()
+		r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"client_id":"cli"}`))
+	)
+
+	r.Header.Set("Content-Type", "application/json")
+	s.handleDeviceAuthorization(w, r)
+
+	var res oauthErrorResponse
+
+	if err := jsonEncoder.NewDecoder(w.Body).Decode(&res); (err != nil) || (w.Code != http.StatusBadRequest) || (res.Error != "invalid_request") {
+		t.Errorf("got %d %+v %v, want 400 invalid_request", w.Code, res, err)
+	}
+}
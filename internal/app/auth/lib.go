@@ -2,14 +2,49 @@ package auth
 
 import (
 	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/huboh/go-rest-api/internal/app/user"
+	"github.com/huboh/go-rest-api/internal/pkg/utils"
+
+	"golang.org/x/crypto/bcrypt"
 )
 
 var (
-	tokens = NewTokenConfigs()
+	// ErrInvalidCredentials is returned when logging in with an unknown email or a wrong password
+	ErrInvalidCredentials = errors.New("invalid email or password")
+
+	// ErrIncompleteSignup is returned when signing up without an email, username or password
+	ErrIncompleteSignup = errors.New("email, username and password are required")
+
+	// dummyPasswordHash is compared to the passwords of unknown emails, so logging in with them takes
+	// as long as with a wrong password and response times don't tell which emails are registered
+	dummyPasswordHash = sync.OnceValue(func() []byte {
+		return utils.Must(bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost))
+	})
 )
 
-func login(ctx context.Context, creds loginCredentials) (loginResponse, error) {
-	authTokens, err := tokens.CreateAuthToken("userId")
+func (s *Service) login(ctx context.Context, creds loginCredentials) (loginResponse, error) {
+	u, err := s.users.GetByEmail(ctx, user.NormalizeEmail(creds.Email))
+
+	if errors.Is(err, user.ErrUserNotFound) {
+		bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(creds.Password))
+		return loginResponse{}, ErrInvalidCredentials
+	}
+
+	if err != nil {
+		return loginResponse{}, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(creds.Password)); err != nil {
+		return loginResponse{}, ErrInvalidCredentials
+	}
+
+	authTokens, err := s.tokens.CreateAuthToken(u.Id)
 
 	if err != nil {
 		return loginResponse{}, err
@@ -20,20 +55,80 @@ func login(ctx context.Context, creds loginCredentials) (loginResponse, error) {
 	}, nil
 }
 
-func signUp(ctx context.Context) (signupResponse, error) {
-	authTokens, err := tokens.CreateAuthToken("userId")
+func (s *Service) signUp(ctx context.Context, req signupRequest) (signupResponse, error) {
+	var (
+		u          *user.User
+		authTokens *AuthToken
+	)
+
+	// the user is only kept if everything created along with it succeeds
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+
+		if u, err = s.CreateAccount(ctx, req.Name, req.Email, req.Username, req.Password); err != nil {
+			return err
+		}
+
+		authTokens, err = s.tokens.CreateAuthToken(u.Id)
+
+		return err
+	})
 
 	if err != nil {
 		return signupResponse{}, err
 	}
 
 	return signupResponse{
+		User:   *u,
 		Tokens: *authTokens,
 	}, nil
 }
 
-func refresh(ctx context.Context) (refreshResponse, error) {
-	authTokens, err := tokens.CreateAuthToken("userId")
+// CreateAccount creates a user with the given password. it returns ErrIncompleteSignup when the email,
+// username or password is empty. it makes Service meet the org.Authenticator interface
+func (s *Service) CreateAccount(ctx context.Context, name string, email string, username string, password string) (*user.User, error) {
+	if (strings.TrimSpace(email) == "") || (strings.TrimSpace(username) == "") || (password == "") {
+		return nil, ErrIncompleteSignup
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+
+	if err != nil {
+		return nil, err
+	}
+
+	u := &user.User{
+		Name:         strings.TrimSpace(name),
+		Email:        user.NormalizeEmail(email),
+		Username:     strings.TrimSpace(username),
+		PasswordHash: string(hash),
+	}
+
+	if err := s.users.Create(ctx, u); err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
+
+func (s *Service) refresh(ctx context.Context, req refreshRequest) (refreshResponse, error) {
+	claims, err := s.tokens.verifySessionToken(req.RefreshToken, TokenTypeRefresh)
+
+	if err != nil {
+		return refreshResponse{}, err
+	}
+
+	if err := s.checkRevoked(ctx, claims.Registered()); err != nil {
+		return refreshResponse{}, err
+	}
+
+	// users deleted since the refresh token was issued can't refresh it
+	if _, err := s.users.GetById(ctx, claims.Subject); err != nil {
+		return refreshResponse{}, err
+	}
+
+	// refreshed tokens stay scoped to the same tenant, membership is checked as they are used
+	authTokens, err := s.tokens.CreateTenantAuthToken(claims.Subject, claims.TenantId)
 
 	if err != nil {
 		return refreshResponse{}, err
@@ -43,3 +138,144 @@ func refresh(ctx context.Context) (refreshResponse, error) {
 		Tokens: *authTokens,
 	}, nil
 }
+
+// IssueTenantTokens issues tokens of the user with the given id scoped to the tenant with id tenantId.
+// callers must have verified the user belongs to the tenant. it makes Service meet the org.Authenticator interface
+func (s *Service) IssueTenantTokens(ctx context.Context, userId string, tenantId string) (any, error) {
+	return s.tokens.CreateTenantAuthToken(userId, tenantId)
+}
+
+func (s *Service) authorizeDevice(ctx context.Context, req deviceAuthorizationRequest) (deviceAuthorizationResponse, error) {
+	deviceCode, err := newDeviceCode()
+	if err != nil {
+		return deviceAuthorizationResponse{}, err
+	}
+
+	userCode, err := newUserCode()
+	if err != nil {
+		return deviceAuthorizationResponse{}, err
+	}
+
+	grant := DeviceGrant{
+		ClientId:   req.ClientId,
+		Scope:      req.Scope,
+		DeviceCode: deviceCode,
+		UserCode:   userCode,
+		Status:     DeviceGrantPending,
+		ExpiresAt:  time.Now().Add(s.devicesConfigs.expiration),
+		Interval:   s.devicesConfigs.interval,
+	}
+
+	if err := s.devices.Create(ctx, grant); err != nil {
+		return deviceAuthorizationResponse{}, err
+	}
+
+	verificationUriComplete := s.devicesConfigs.verificationUri
+
+	if u, err := url.Parse(s.devicesConfigs.verificationUri); err == nil {
+		q := u.Query()
+		q.Set("user_code", userCode)
+		u.RawQuery = q.Encode()
+		verificationUriComplete = u.String()
+	}
+
+	return deviceAuthorizationResponse{
+		DeviceCode:              deviceCode,
+		UserCode:                userCode,
+		VerificationUri:         s.devicesConfigs.verificationUri,
+		VerificationUriComplete: verificationUriComplete,
+		ExpiresIn:               int64(s.devicesConfigs.expiration.Seconds()),
+		Interval:                int64(s.devicesConfigs.interval.Seconds()),
+	}, nil
+}
+
+func (s *Service) verifyDevice(ctx context.Context, subject string, req deviceVerificationRequest) (deviceVerificationResponse, error) {
+	grant, err := s.devices.GetByUserCode(ctx, normalizeUserCode(req.UserCode))
+	if err != nil {
+		return deviceVerificationResponse{}, err
+	}
+
+	if grant.expired(time.Now()) {
+		return deviceVerificationResponse{}, ErrExpiredToken
+	}
+
+	status, approver := DeviceGrantDenied, ""
+
+	if req.Approve {
+		status, approver = DeviceGrantApproved, subject
+	}
+
+	// Decide only succeeds while the grant is pending, so it can't be both approved and denied
+	if err := s.devices.Decide(ctx, grant.DeviceCode, status, approver); err != nil {
+		return deviceVerificationResponse{}, err
+	}
+
+	return deviceVerificationResponse{
+		ClientId: grant.ClientId,
+		Scope:    grant.Scope,
+		Approved: req.Approve,
+	}, nil
+}
+
+func (s *Service) pollDeviceToken(ctx context.Context, req deviceTokenRequest) (deviceTokenResponse, error) {
+	if req.GrantType != deviceCodeGrantType {
+		return deviceTokenResponse{}, ErrUnsupportedGrantType
+	}
+
+	grant, err := s.devices.GetByDeviceCode(ctx, req.DeviceCode)
+	if err != nil {
+		return deviceTokenResponse{}, err
+	}
+
+	if grant.ClientId != req.ClientId {
+		return deviceTokenResponse{}, ErrInvalidGrant
+	}
+
+	now := time.Now()
+
+	if grant.expired(now) {
+		s.devices.Delete(ctx, grant.DeviceCode)
+		return deviceTokenResponse{}, ErrExpiredToken
+	}
+
+	switch grant.Status {
+	case DeviceGrantDenied:
+		s.devices.Delete(ctx, grant.DeviceCode)
+		return deviceTokenResponse{}, ErrAccessDenied
+
+	case DeviceGrantPending:
+		polledTooFast := !grant.LastPollAt.IsZero() && now.Sub(grant.LastPollAt) < grant.Interval
+
+		if polledTooFast {
+			grant.Interval += slowDownIncrement
+		}
+
+		// only the polling state is written, so a concurrent approval isn't overwritten
+		if err := s.devices.RecordPoll(ctx, grant.DeviceCode, grant.Interval, now); err != nil {
+			return deviceTokenResponse{}, err
+		}
+
+		if polledTooFast {
+			return deviceTokenResponse{}, ErrSlowDown
+		}
+
+		return deviceTokenResponse{}, ErrAuthorizationPending
+	}
+
+	// device codes are single use, only one concurrent poll can delete the grant
+	if err := s.devices.Delete(ctx, grant.DeviceCode); err != nil {
+		return deviceTokenResponse{}, err
+	}
+
+	authTokens, err := s.tokens.CreateAuthToken(grant.Subject)
+	if err != nil {
+		return deviceTokenResponse{}, err
+	}
+
+	return deviceTokenResponse{
+		AccessToken:  authTokens.AccessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    max(int64(authTokens.AccessTokenExpAt)-time.Now().Unix(), 0),
+		RefreshToken: authTokens.RefreshToken,
+	}, nil
+}
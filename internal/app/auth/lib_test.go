@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestLogin(t *testing.T) {
+	var (
+		s   = newTestService(t)
+		ctx = context.Background()
+	)
+
+	if _, err := s.CreateAccount(ctx, "Jane", "jane@example.com", "jane", "password1"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		creds loginCredentials
+		err   error
+	}{
+		{"valid credentials", loginCredentials{Email: "Jane@Example.com", Password: "password1"}, nil},
+		{"wrong password", loginCredentials{Email: "jane@example.com", Password: "password2"}, ErrInvalidCredentials},
+		{"unknown email", loginCredentials{Email: "john@example.com", Password: "password1"}, ErrInvalidCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := s.login(ctx, tt.creds)
+
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("login() error = %v, want %v", err, tt.err)
+			}
+
+			if (tt.err == nil) && (res.Tokens.AccessToken == "") {
+				t.Error("login() returned no access token")
+			}
+		})
+	}
+}
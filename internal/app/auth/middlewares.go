@@ -5,9 +5,13 @@ import (
 
 	"github.com/huboh/go-rest-api/internal/app/user"
 	"github.com/huboh/go-rest-api/internal/pkg/json"
+	"github.com/huboh/go-rest-api/internal/pkg/tenant"
 )
 
-func AuthGuardMiddleware(next http.Handler) http.Handler {
+// AuthGuardMiddleware rejects requests without a valid, unrevoked bearer access token and
+// stores the token's subject in the request context for user.UserFromContext, and its tenant
+// claim for tenant.ClaimFromContext.
+func (s *Service) AuthGuardMiddleware(next http.Handler) http.Handler {
 	writeErr := func(w http.ResponseWriter, err error) {
 		var msg string
 
@@ -33,13 +37,24 @@ func AuthGuardMiddleware(next http.Handler) http.Handler {
 				return
 			}
 
-			payload, err := tokens.VerifyAccessToken(token)
+			claims, err := s.tokens.verifySessionToken(token, TokenTypeAccess)
 			if err != nil {
 				writeErr(w, err)
 				return
 			}
 
-			next.ServeHTTP(w, r.WithContext(user.ContextWithUser(r.Context(), payload)))
+			if err := s.checkRevoked(r.Context(), claims.Registered()); err != nil {
+				writeErr(w, err)
+				return
+			}
+
+			ctx := user.ContextWithUser(r.Context(), claims.Subject)
+
+			if claims.TenantId != "" {
+				ctx = tenant.ContextWithClaim(ctx, claims.TenantId)
+			}
+
+			next.ServeHTTP(w, r.WithContext(ctx))
 		},
 	)
 }
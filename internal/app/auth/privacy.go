@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/huboh/go-rest-api/internal/pkg/privacy"
+)
+
+// privacyProvider exports and erases the authentication data of users.
+type privacyProvider struct {
+	s *Service
+}
+
+// authData is the authentication data of a user included in export archives.
+type authData struct {
+	TokensRevokedAt *time.Time        `json:"tokensRevokedAt"`
+	DeviceGrants    []deviceGrantData `json:"deviceGrants"`
+}
+
+// deviceGrantData is a device authorization request included in export archives. codes are left out.
+type deviceGrantData struct {
+	ClientId  string            `json:"clientId"`
+	Scope     string            `json:"scope"`
+	Status    DeviceGrantStatus `json:"status"`
+	ExpiresAt time.Time         `json:"expiresAt"`
+}
+
+// PrivacyProvider returns the privacy.Provider of the authentication data held by s.
+func (s *Service) PrivacyProvider() privacy.Provider {
+	return privacyProvider{s: s}
+}
+
+func (p privacyProvider) Name() string {
+	return "auth"
+}
+
+func (p privacyProvider) Export(ctx context.Context, userId string) (any, error) {
+	data := authData{DeviceGrants: []deviceGrantData{}}
+	revokedAt, err := p.s.revocations.RevokedAt(ctx, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !revokedAt.IsZero() {
+		data.TokensRevokedAt = &revokedAt
+	}
+
+	grants, err := p.s.devices.ListBySubject(ctx, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, g := range grants {
+		data.DeviceGrants = append(data.DeviceGrants, deviceGrantData{
+			ClientId:  g.ClientId,
+			Scope:     g.Scope,
+			Status:    g.Status,
+			ExpiresAt: g.ExpiresAt,
+		})
+	}
+
+	return data, nil
+}
+
+// Erase revokes the tokens of the user so its sessions end, and removes its device authorization requests.
+// the revocation itself is kept, it must outlive the tokens it revokes.
+func (p privacyProvider) Erase(ctx context.Context, userId string) error {
+	if err := p.s.RevokeTokens(ctx, userId); err != nil {
+		return err
+	}
+
+	return p.s.devices.DeleteBySubject(ctx, userId)
+}
@@ -1,6 +1,46 @@
 package auth
 
+import "errors"
+
 type loginCredentials struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+type signupRequest struct {
+	Name     string `json:"name"`
+	Email    string `json:"email" validate:"required,email"`
+	Username string `json:"username" validate:"required,username"`
+	Password string `json:"password" validate:"required,min=8"`
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
+
+// deviceAuthorizationRequest is the form encoded device authorization request of RFC 8628 section 3.1.
+type deviceAuthorizationRequest struct {
+	ClientId string
+	Scope    string
+}
+
+// Validate checks the request has a client id.
+func (r deviceAuthorizationRequest) Validate() error {
+	if r.ClientId == "" {
+		return errors.New("client_id is required")
+	}
+
+	return nil
+}
+
+type deviceVerificationRequest struct {
+	UserCode string `json:"userCode"`
+	Approve  bool   `json:"approve"`
+}
+
+// deviceTokenRequest is the form encoded device access token request of RFC 8628 section 3.4.
+type deviceTokenRequest struct {
+	GrantType  string
+	DeviceCode string
+	ClientId   string
 }
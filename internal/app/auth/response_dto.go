@@ -1,13 +1,46 @@
 package auth
 
+import "github.com/huboh/go-rest-api/internal/app/user"
+
 type loginResponse struct {
 	Tokens AuthToken `json:"tokens"`
 }
 
 type signupResponse struct {
+	User   user.User `json:"user"`
 	Tokens AuthToken `json:"tokens"`
 }
 
 type refreshResponse struct {
 	Tokens AuthToken `json:"tokens"`
 }
+
+// deviceAuthorizationResponse is the device authorization response of RFC 8628 section 3.2.
+type deviceAuthorizationResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationUri         string `json:"verification_uri"`
+	VerificationUriComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int64  `json:"expires_in"`
+	Interval                int64  `json:"interval"`
+}
+
+type deviceVerificationResponse struct {
+	ClientId string `json:"clientId"`
+	Scope    string `json:"scope"`
+	Approved bool   `json:"approved"`
+}
+
+// deviceTokenResponse is the access token response of RFC 6749 section 5.1.
+type deviceTokenResponse struct {
+	AccessToken  Jwt    `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken Jwt    `json:"refresh_token"`
+}
+
+// oauthErrorResponse is the error response of RFC 6749 section 5.2.
+type oauthErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
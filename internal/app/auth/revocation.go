@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// RevocationStore records when the tokens of subjects were revoked.
+type RevocationStore interface {
+	// Revoke revokes every token of subject issued up to at.
+	Revoke(ctx context.Context, subject string, at time.Time) error
+
+	// RevokedAt returns the time the tokens of subject were last revoked, or the zero time if they never were.
+	RevokedAt(ctx context.Context, subject string) (time.Time, error)
+}
+
+// MemoryRevocationStore is an in-memory RevocationStore, mainly useful for tests and local development.
+type MemoryRevocationStore struct {
+	mu      sync.RWMutex
+	revoked map[string]time.Time
+}
+
+// NewMemoryRevocationStore creates an empty MemoryRevocationStore.
+func NewMemoryRevocationStore() *MemoryRevocationStore {
+	return &MemoryRevocationStore{
+		revoked: map[string]time.Time{},
+	}
+}
+
+func (m *MemoryRevocationStore) Revoke(ctx context.Context, subject string, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.revoked[subject] = at.UTC()
+
+	return nil
+}
+
+func (m *MemoryRevocationStore) RevokedAt(ctx context.Context, subject string) (time.Time, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.revoked[subject], nil
+}
+
+// RevokeTokens revokes every token issued to subject so far. it makes Service meet the user.TokenRevoker interface
+func (s *Service) RevokeTokens(ctx context.Context, subject string) error {
+	return s.revocations.Revoke(ctx, subject, time.Now())
+}
+
+// checkRevoked returns ErrInvalidToken if the token with the given claims was issued before its subject's tokens were revoked.
+func (s *Service) checkRevoked(ctx context.Context, claims *jwt.RegisteredClaims) error {
+	revokedAt, err := s.revocations.RevokedAt(ctx, claims.Subject)
+
+	if err != nil {
+		return err
+	}
+
+	// "iat" only has a second precision, tokens issued within the second of the revocation are revoked too
+	if !revokedAt.IsZero() && ((claims.IssuedAt == nil) || !claims.IssuedAt.After(revokedAt.Truncate(time.Second))) {
+		return fmt.Errorf("%w: token was revoked", ErrInvalidToken)
+	}
+
+	return nil
+}
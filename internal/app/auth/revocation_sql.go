@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/huboh/go-rest-api/internal/pkg/database"
+)
+
+// SQLRevocationStore is a RevocationStore backed by a SQL database.
+type SQLRevocationStore struct {
+	db *database.DB
+}
+
+// NewSQLRevocationStore creates a SQLRevocationStore using db.
+func NewSQLRevocationStore(db *database.DB) *SQLRevocationStore {
+	return &SQLRevocationStore{db: db}
+}
+
+func (s *SQLRevocationStore) Revoke(ctx context.Context, subject string, at time.Time) error {
+	_, err := s.db.Executor(ctx).ExecContext(
+		ctx,
+		s.db.Rebind("INSERT INTO token_revocations (subject, revoked_at) VALUES (?, ?) ON CONFLICT (subject) DO UPDATE SET revoked_at = excluded.revoked_at"),
+		subject, at.UTC(),
+	)
+
+	return err
+}
+
+func (s *SQLRevocationStore) RevokedAt(ctx context.Context, subject string) (time.Time, error) {
+	var at time.Time
+
+	err := s.db.Executor(ctx).QueryRowContext(ctx, s.db.Rebind("SELECT revoked_at FROM token_revocations WHERE subject = ?"), subject).Scan(&at)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+
+	return at, err
+}
@@ -3,12 +3,14 @@ package auth
 import (
 	"net/http"
 
+	"github.com/huboh/go-rest-api/internal/pkg/database"
 	"github.com/huboh/go-rest-api/internal/pkg/middleware"
 	"github.com/huboh/go-rest-api/internal/pkg/router"
 )
 
-var (
-	Router = router.New(
+// NewRouter creates the router serving the auth routes of s.
+func NewRouter(s *Service) *router.Router {
+	return router.New(
 		// mount path
 		RouterPath,
 
@@ -20,18 +22,79 @@ var (
 			{
 				Path:    "/login",
 				Method:  http.MethodPost,
-				Handler: http.HandlerFunc(handleLogin),
+				Handler: router.Handle(s.login, router.WithErrorStatus(errStatusCode)),
+				Summary: "Log in with an email and password",
+				Public:  true,
 			},
 			{
-				Path:    "/signup",
-				Method:  http.MethodPost,
-				Handler: http.HandlerFunc(handleSignup),
+				Path:        "/signup",
+				Method:      http.MethodPost,
+				Handler:     router.Handle(s.signUp, router.WithErrorStatus(errStatusCode)),
+				Middlewares: []middleware.Middleware{database.Transactional(s.tx)},
+				Summary:     "Create an account",
+				Public:      true,
 			},
 			{
 				Path:    "/refresh",
 				Method:  http.MethodPost,
-				Handler: http.HandlerFunc(handleRefresh),
+				Handler: router.Handle(s.refresh, router.WithErrorStatus(errStatusCode)),
+				Summary: "Exchange a refresh token for new tokens",
+				Public:  true,
 			},
 		},
 	)
-)
+}
+
+// NewOAuthRouter creates the router serving the oauth routes of s.
+func NewOAuthRouter(s *Service) *router.Router {
+	return router.New(
+		// mount path
+		OAuthRouterPath,
+
+		// middlewares
+		[]middleware.Middleware{},
+
+		// routes
+		[]router.Route{
+			{
+				Path:    "/device_authorization",
+				Method:  http.MethodPost,
+				Handler: http.HandlerFunc(s.handleDeviceAuthorization),
+				Summary: "Start an OAuth device authorization",
+				Public:  true,
+			},
+			{
+				Path:        "/device",
+				Method:      http.MethodPost,
+				Handler:     router.Handle(s.handleDeviceVerification, oauthOptions...),
+				Middlewares: []middleware.Middleware{s.AuthGuardMiddleware},
+				Auth:        []string{"authenticated"},
+				Summary:     "Approve or deny a device authorization as the authenticated user",
+				Security:    []string{"bearer"},
+			},
+			{
+				Path:    "/token",
+				Method:  http.MethodPost,
+				Handler: http.HandlerFunc(s.handleDeviceToken),
+				Summary: "Poll for the tokens of a device authorization",
+				Public:  true,
+			},
+		},
+	)
+}
+
+// Module adds the auth and oauth routes to the router of the app, see router.Module.
+type Module struct {
+	service *Service
+}
+
+// NewModule creates the module mounting the routers of s.
+func NewModule(s *Service) *Module {
+	return &Module{service: s}
+}
+
+// RegisterRoutes makes Module implement router.Module.
+func (m *Module) RegisterRoutes(r *router.Router) {
+	r.Mount(RouterPath, NewRouter(m.service), router.Tagged("auth"))
+	r.Mount(OAuthRouterPath, NewOAuthRouter(m.service), router.Tagged("oauth"))
+}
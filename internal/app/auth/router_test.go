@@ -0,0 +1,37 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/huboh/go-rest-api/internal/pkg/router"
+)
+
+func TestRouterDescribesLogin(t *testing.T) {
+	found := false
+
+	err := NewRouter(newTestService(t)).Walk(func(info router.RouteInfo) error {
+		if info.Path != RouterPath+"/login" {
+			return nil
+		}
+
+		found = true
+
+		if _, ok := info.Request.(loginCredentials); !ok {
+			t.Errorf("Request = %#v, want loginCredentials", info.Request)
+		}
+
+		if _, ok := info.Response.(loginResponse); !ok {
+			t.Errorf("Response = %#v, want loginResponse", info.Response)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !found {
+		t.Errorf("no %s/login route", RouterPath)
+	}
+}
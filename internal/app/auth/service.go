@@ -0,0 +1,30 @@
+package auth
+
+import (
+	"github.com/huboh/go-rest-api/internal/app/user"
+	"github.com/huboh/go-rest-api/internal/pkg/database"
+)
+
+// Service holds the dependencies used by the auth handlers and middlewares.
+type Service struct {
+	users          user.UserRepository
+	tokens         *TokenConfigs
+	devices        DeviceGrantStore
+	devicesConfigs *deviceConfigs
+	revocations    RevocationStore
+	tx             database.TxManager
+}
+
+// NewService creates a new auth Service that authenticates users stored in users, keeps device
+// authorization requests in devices, records revoked tokens in revocations, runs multi-record
+// changes within tx and issues tokens configured by tokens.
+func NewService(users user.UserRepository, devices DeviceGrantStore, revocations RevocationStore, tx database.TxManager, tokens *TokenConfigs) *Service {
+	return &Service{
+		tx:             tx,
+		revocations:    revocations,
+		users:          users,
+		tokens:         tokens,
+		devices:        devices,
+		devicesConfigs: newDeviceConfigs(),
+	}
+}
@@ -17,7 +17,8 @@ var (
 	ErrInvalidToken = errors.New("invalid token")
 )
 
-// Jwt represents a JWT token as a string.
+// Jwt represents a token as a string. it holds a JWT by default, or a PASETO
+// when the token type is configured to use one.
 type Jwt string
 
 // JwtExp represents the token expiration time in Unix format.
@@ -48,93 +49,137 @@ type AuthToken struct {
 }
 
 // TokenConfigs holds the configuration for generating various types of tokens
-// including their secrets, issuers, and expiration durations.
+// including their codecs, issuers, and expiration durations.
 type TokenConfigs struct {
 	idTokenIssuer    string
-	idTokenSecret    []byte
+	idTokenCodec     TokenCodec
 	idTokenExpiresAt time.Duration
 
 	accessTokenIssuer    string
-	accessTokenSecret    []byte
+	accessTokenCodec     TokenCodec
 	accessTokenExpiresAt time.Duration
 
 	refreshTokenIssuer    string
-	refreshTokenSecret    []byte
+	refreshTokenCodec     TokenCodec
 	refreshTokenExpiresAt time.Duration
+
+	// leeway is the default clock skew allowed when validating time based claims
+	leeway time.Duration
 }
 
 // NewTokenConfigs initializes a new TokenConfigs instance by reading environment variables
-// for issuer, secrets, and expiration durations.
+// for issuer, formats, keys, encryption, and expiration durations.
 func NewTokenConfigs() *TokenConfigs {
 	tc := new(TokenConfigs)
 	issuer := env.Get("JWT_ISSUER")
 
 	//* set id token configs
 	tc.idTokenIssuer = issuer
-	tc.idTokenSecret = []byte(env.MustGet("JWT_ID_TOKEN_SECRET"))
+	tc.idTokenCodec = mustGetTokenCodec("ID")
 	tc.idTokenExpiresAt = utils.Must(time.ParseDuration(env.MustGet("JWT_ID_TOKEN_EXPIRATION")))
 
 	//* set access token configs
 	tc.accessTokenIssuer = issuer
-	tc.accessTokenSecret = []byte(env.MustGet("JWT_ACCESS_TOKEN_SECRET"))
+	tc.accessTokenCodec = mustGetTokenCodec("ACCESS")
 	tc.accessTokenExpiresAt = utils.Must(time.ParseDuration(env.MustGet("JWT_ACCESS_TOKEN_EXPIRATION")))
 
 	//* set refresh tokens configs
 	tc.refreshTokenIssuer = issuer
-	tc.refreshTokenSecret = []byte(env.MustGet("JWT_REFRESH_TOKEN_SECRET"))
+	tc.refreshTokenCodec = mustGetTokenCodec("REFRESH")
 	tc.refreshTokenExpiresAt = utils.Must(time.ParseDuration(env.MustGet("JWT_REFRESH_TOKEN_EXPIRATION")))
 
+	//* set validation configs
+	if leeway := env.Get("TOKEN_LEEWAY"); leeway != "" {
+		tc.leeway = utils.Must(time.ParseDuration(leeway))
+	}
+
 	return tc
 }
 
-// createToken generates a Jwt with the provided payload, secret, and expiration duration.
-func (tc *TokenConfigs) createToken(p string, s []byte, d time.Duration) (Jwt, JwtExp, error) {
-	now := time.Now()
-	expAt := now.Add(d)
-	token, err := jwt.NewWithClaims(
-		jwt.SigningMethodHS256,
-		jwt.RegisteredClaims{
-			Issuer:    tc.idTokenIssuer,
-			Subject:   p,
-			IssuedAt:  jwt.NewNumericDate(now),
-			ExpiresAt: jwt.NewNumericDate(expAt),
-		},
-	).SignedString(s)
+// mustGetTokenCodec creates the TokenCodec for a token type (e.g "ID") from the "<TYPE>_TOKEN_FORMAT" env
+// variable, reading its key from "JWT_<TYPE>_TOKEN_SECRET" for JWTs or "PASETO_<TYPE>_TOKEN_KEY" for PASETOs.
+//
+// When "<TYPE>_TOKEN_ENCRYPTION" is set the tokens are further encrypted as JWEs using "JWE_<TYPE>_TOKEN_KEY".
+// It panics when the configuration is invalid.
+func mustGetTokenCodec(t string) TokenCodec {
+	var (
+		codec  TokenCodec
+		format = TokenFormat(env.Get(t + "_TOKEN_FORMAT"))
+	)
+
+	if (format == "") || (format == TokenFormatJwt) {
+		codec = utils.Must(newTokenCodec(format, env.MustGet("JWT_"+t+"_TOKEN_SECRET")))
+	} else {
+		codec = utils.Must(newTokenCodec(format, env.MustGet("PASETO_"+t+"_TOKEN_KEY")))
+	}
 
-	if err != nil {
-		return "", 0, err
+	if enc := TokenEncryption(env.Get(t + "_TOKEN_ENCRYPTION")); enc != TokenEncryptionNone {
+		codec = utils.Must(newJweCodec(codec, enc, env.MustGet("JWE_"+t+"_TOKEN_KEY")))
 	}
 
-	return Jwt(token), JwtExp(expAt.Unix()), nil
+	return codec
 }
 
-// verifyToken parses and validates a given token string using the provided secret.
-//
-// return ErrInvalidToken when t is invalid
-func (tc *TokenConfigs) verifyToken(t string, s []byte) (*jwt.RegisteredClaims, error) {
-	claims := &jwt.RegisteredClaims{}
-	getSecret := func(token *jwt.Token) (any, error) {
-		return s, nil
+// tokenConfigs holds the configuration of a single token type.
+type tokenConfigs struct {
+	issuer    string
+	codec     TokenCodec
+	expiresAt time.Duration
+}
+
+// configsFor returns the configuration of token type t.
+func (tc *TokenConfigs) configsFor(t TokenType) (tokenConfigs, error) {
+	switch t {
+	case TokenTypeId:
+		return tokenConfigs{tc.idTokenIssuer, tc.idTokenCodec, tc.idTokenExpiresAt}, nil
+	case TokenTypeAccess:
+		return tokenConfigs{tc.accessTokenIssuer, tc.accessTokenCodec, tc.accessTokenExpiresAt}, nil
+	case TokenTypeRefresh:
+		return tokenConfigs{tc.refreshTokenIssuer, tc.refreshTokenCodec, tc.refreshTokenExpiresAt}, nil
 	}
 
-	token, err := jwt.ParseWithClaims(t, claims, getSecret)
+	return tokenConfigs{}, fmt.Errorf("unknown token type \"%s\"", t)
+}
 
-	if err != nil {
-		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
-	}
+// createToken generates a token of type t with the provided payload as its subject.
+func (tc *TokenConfigs) createToken(p string, t TokenType) (Jwt, JwtExp, error) {
+	claims := &RegisteredClaims{}
+	claims.Subject = p
 
-	claims, ok := token.Claims.(*jwt.RegisteredClaims)
+	return CreateToken(tc, t, claims)
+}
+
+// createSessionToken generates an access or refresh token of subject, scoped to the tenant with id tenantId if not empty.
+func (tc *TokenConfigs) createSessionToken(subject string, tenantId string, t TokenType) (Jwt, JwtExp, error) {
+	claims := &SessionClaims{TenantId: tenantId}
+	claims.Subject = subject
+
+	return CreateToken(tc, t, claims)
+}
+
+// verifySessionToken parses and validates a given access or refresh token string of type t.
+//
+// return ErrInvalidToken when token is invalid
+func (tc *TokenConfigs) verifySessionToken(token string, t TokenType) (*SessionClaims, error) {
+	return VerifyToken[SessionClaims](tc, t, token)
+}
 
-	if !ok && !token.Valid {
-		return nil, ErrInvalidToken
+// verifyToken parses and validates a given token string of type t.
+//
+// return ErrInvalidToken when token is invalid
+func (tc *TokenConfigs) verifyToken(token string, t TokenType) (*jwt.RegisteredClaims, error) {
+	claims, err := VerifyToken[RegisteredClaims](tc, t, token)
+
+	if err != nil {
+		return nil, err
 	}
 
-	return claims, nil
+	return claims.Registered(), nil
 }
 
 // CreateIdToken generates an ID token using the provided payload and the configurations set in TokenConfigs.
 func (tc *TokenConfigs) CreateIdToken(payload string) (*IdToken, error) {
-	token, expAt, err := tc.createToken(payload, tc.idTokenSecret, tc.idTokenExpiresAt)
+	token, expAt, err := tc.createToken(payload, TokenTypeId)
 
 	if err != nil {
 		return nil, err
@@ -150,7 +195,7 @@ func (tc *TokenConfigs) CreateIdToken(payload string) (*IdToken, error) {
 //
 // return ErrInvalidToken when t is invalid
 func (tc *TokenConfigs) VerifyIdToken(token string) (*IdToken, error) {
-	claims, err := tc.verifyToken(token, tc.idTokenSecret)
+	claims, err := tc.verifyToken(token, TokenTypeId)
 	if err != nil {
 		return nil, err
 	}
@@ -164,6 +209,11 @@ func (tc *TokenConfigs) VerifyIdToken(token string) (*IdToken, error) {
 // CreateAuthToken generates an access token and a refresh token concurrently using the provided payload
 // and the configurations set in TokenConfigs.
 func (tc *TokenConfigs) CreateAuthToken(payload string) (*AuthToken, error) {
+	return tc.CreateTenantAuthToken(payload, "")
+}
+
+// CreateTenantAuthToken is like CreateAuthToken, but the tokens are scoped to the tenant with id tenantId.
+func (tc *TokenConfigs) CreateTenantAuthToken(payload string, tenantId string) (*AuthToken, error) {
 	wg := sync.WaitGroup{}
 	errChan := make(chan error, 2)
 	authToken := AuthToken{}
@@ -174,7 +224,7 @@ func (tc *TokenConfigs) CreateAuthToken(payload string) (*AuthToken, error) {
 	go func() {
 		defer wg.Done()
 
-		token, expAt, err := tc.createToken(payload, tc.accessTokenSecret, tc.accessTokenExpiresAt)
+		token, expAt, err := tc.createSessionToken(payload, tenantId, TokenTypeAccess)
 		if err != nil {
 			errChan <- err
 			return
@@ -188,7 +238,7 @@ func (tc *TokenConfigs) CreateAuthToken(payload string) (*AuthToken, error) {
 	go func() {
 		defer wg.Done()
 
-		token, expAt, err := tc.createToken(payload, tc.refreshTokenSecret, tc.refreshTokenExpiresAt)
+		token, expAt, err := tc.createSessionToken(payload, tenantId, TokenTypeRefresh)
 		if err != nil {
 			errChan <- err
 			return
@@ -227,7 +277,7 @@ func (tc *TokenConfigs) VerifyAuthToken(aToken string, rToken string) (*AuthToke
 	go func() {
 		defer wg.Done()
 
-		claims, err := tc.verifyToken(aToken, tc.accessTokenSecret)
+		claims, err := tc.verifyToken(aToken, TokenTypeAccess)
 
 		if err != nil {
 			errChan <- err
@@ -243,7 +293,7 @@ func (tc *TokenConfigs) VerifyAuthToken(aToken string, rToken string) (*AuthToke
 	go func() {
 		defer wg.Done()
 
-		claims, err := tc.verifyToken(rToken, tc.refreshTokenSecret)
+		claims, err := tc.verifyToken(rToken, TokenTypeRefresh)
 
 		if err != nil {
 			errChan <- err
@@ -271,7 +321,7 @@ func (tc *TokenConfigs) VerifyAuthToken(aToken string, rToken string) (*AuthToke
 }
 
 func (tc *TokenConfigs) VerifyAccessToken(token string) (string, error) {
-	claims, err := tc.verifyToken(token, tc.accessTokenSecret)
+	claims, err := tc.verifyToken(token, TokenTypeAccess)
 
 	if err != nil {
 		return "", err
@@ -281,7 +331,7 @@ func (tc *TokenConfigs) VerifyAccessToken(token string) (string, error) {
 }
 
 func (tc *TokenConfigs) VerifyRefreshToken(token string) (string, error) {
-	claims, err := tc.verifyToken(token, tc.refreshTokenSecret)
+	claims, err := tc.verifyToken(token, TokenTypeRefresh)
 
 	if err != nil {
 		return "", err
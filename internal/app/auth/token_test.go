@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// orderClaims are custom claims, as defined by callers of CreateToken and VerifyToken.
+type orderClaims struct {
+	RegisteredClaims
+
+	OrderId string   `json:"oid,omitempty"`
+	Scopes  []string `json:"scopes,omitempty"`
+}
+
+// numericTenantClaims decode the "tid" claim of SessionClaims as a number.
+type numericTenantClaims struct {
+	RegisteredClaims
+
+	TenantId int `json:"tid"`
+}
+
+// newTestTokenConfigs creates TokenConfigs issuing jwt access tokens valid for an hour.
+func newTestTokenConfigs() *TokenConfigs {
+	return &TokenConfigs{
+		accessTokenIssuer:    "https://www.example.com",
+		accessTokenCodec:     newJwtCodec([]byte("access secret")),
+		accessTokenExpiresAt: time.Hour,
+	}
+}
+
+func TestCustomClaimsRoundTrip(t *testing.T) {
+	var (
+		tc     = newTestTokenConfigs()
+		claims = &orderClaims{OrderId: "42", Scopes: []string{"read", "write"}}
+	)
+
+	claims.Subject = "jane"
+
+	token, exp, err := CreateToken(tc, TokenTypeAccess, claims)
+
+	if err != nil {
+		t.Fatalf("CreateToken() = %s", err)
+	}
+
+	got, err := VerifyToken[orderClaims](tc, TokenTypeAccess, string(token))
+
+	if err != nil {
+		t.Fatalf("VerifyToken() = %s", err)
+	}
+
+	if (got.Subject != "jane") || (got.OrderId != "42") || !slices.Equal(got.Scopes, claims.Scopes) {
+		t.Errorf("VerifyToken() = %+v, want the created claims", got)
+	}
+
+	if (got.Issuer != tc.accessTokenIssuer) || (got.ExpiresAt.Unix() != int64(exp)) {
+		t.Errorf("VerifyToken() issuer %q expiring at %v, want %q at %d", got.Issuer, got.ExpiresAt, tc.accessTokenIssuer, exp)
+	}
+}
+
+func TestVerifyTokenAsWrongClaimsType(t *testing.T) {
+	tc := newTestTokenConfigs()
+	token, _, err := tc.createSessionToken("jane", "acme", TokenTypeAccess)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := VerifyToken[numericTenantClaims](tc, TokenTypeAccess, string(token)); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("VerifyToken() of a string tenant as a number = %v, want ErrInvalidToken", err)
+	}
+
+	if _, err := VerifyToken[SessionClaims](tc, TokenTypeAccess, string(token)); err != nil {
+		t.Errorf("VerifyToken() as its own claims type = %v, want nil", err)
+	}
+}
+
+func TestVerifyTokenRequiredClaims(t *testing.T) {
+	tc := newTestTokenConfigs()
+
+	tests := []struct {
+		name     string
+		claims   *orderClaims
+		required []string
+		err      error
+	}{
+		{"present claims", &orderClaims{OrderId: "42", Scopes: []string{"read"}}, []string{"oid", "scopes", "sub"}, nil},
+		{"missing custom claim", &orderClaims{Scopes: []string{"read"}}, []string{"oid"}, ErrInvalidToken},
+		{"empty custom claim", &orderClaims{OrderId: "42", Scopes: []string{}}, []string{"scopes"}, ErrInvalidToken},
+		{"missing registered claim", &orderClaims{OrderId: "42"}, []string{"aud"}, ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.claims.Subject = "jane"
+
+			token, _, err := CreateToken(tc, TokenTypeAccess, tt.claims)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := VerifyToken[orderClaims](tc, TokenTypeAccess, string(token), WithRequiredClaims(tt.required...)); !errors.Is(err, tt.err) {
+				t.Errorf("VerifyToken() = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
@@ -1,9 +1,12 @@
 package auth
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"regexp"
+
+	"github.com/huboh/go-rest-api/internal/app/user"
 )
 
 var tknRegexp = regexp.MustCompile("^Bearer\x20(.+)$")
@@ -17,3 +20,15 @@ func getAuthHeaderToken(r http.Request) (string, error) {
 
 	return matches[1], nil
 }
+
+// errStatusCode returns the http status code that best describes err.
+func errStatusCode(err error) int {
+	switch {
+	case errors.Is(err, ErrInvalidCredentials), errors.Is(err, ErrInvalidToken), errors.Is(err, user.ErrUserNotFound):
+		return http.StatusUnauthorized
+	case errors.Is(err, user.ErrUserExists):
+		return http.StatusConflict
+	}
+
+	return http.StatusBadRequest
+}
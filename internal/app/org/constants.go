@@ -0,0 +1,12 @@
+package org
+
+const (
+	// RouterPath is the mount path of the routes managing the orgs of the authenticated user.
+	RouterPath = "/orgs"
+
+	// TenantRouterPath is the mount path of the routes acting on the active tenant.
+	TenantRouterPath = "/org"
+
+	// InvitationRouterPath is the mount path of the public routes of invitations.
+	InvitationRouterPath = "/invitations"
+)
@@ -0,0 +1,352 @@
+package org
+
+import (
+	"net/http"
+
+	"github.com/huboh/go-rest-api/internal/app/user"
+	"github.com/huboh/go-rest-api/internal/pkg/json"
+)
+
+func (s *Service) handleCreateOrg(w http.ResponseWriter, r *http.Request) {
+	var (
+		req       createOrgRequest
+		userId, _ = user.UserFromContext(r.Context())
+	)
+
+	if err := json.UnmarshalBody(r, &req); err != nil {
+		json.Write(w, json.Response{
+			StatusCode: http.StatusBadRequest,
+			Error:      json.ErrorFromErr(err, "", ""),
+		})
+		return
+	}
+
+	o, err := s.createOrg(r.Context(), userId, req)
+
+	if err != nil {
+		json.Write(w, json.Response{
+			StatusCode: errStatusCode(err),
+			Error:      json.ErrorFromErr(err, "", ""),
+		})
+		return
+	}
+
+	json.Write(w, json.Response{
+		StatusCode: http.StatusCreated,
+		Data:       orgResponse{Org: *o, Role: RoleOwner},
+	})
+}
+
+func (s *Service) handleListOrgs(w http.ResponseWriter, r *http.Request) {
+	userId, _ := user.UserFromContext(r.Context())
+	orgs, err := s.listOrgs(r.Context(), userId)
+
+	if err != nil {
+		json.Write(w, json.Response{
+			StatusCode: errStatusCode(err),
+			Error:      json.ErrorFromErr(err, "", ""),
+		})
+		return
+	}
+
+	json.Write(w, json.Response{
+		Data: orgs,
+	})
+}
+
+func (s *Service) handleIssueTokens(w http.ResponseWriter, r *http.Request) {
+	userId, _ := user.UserFromContext(r.Context())
+	tokens, err := s.issueTokens(r.Context(), userId, r.PathValue("id"))
+
+	if err != nil {
+		json.Write(w, json.Response{
+			StatusCode: errStatusCode(err),
+			Error:      json.ErrorFromErr(err, "", ""),
+		})
+		return
+	}
+
+	json.Write(w, json.Response{
+		Data: tokensResponse{Tokens: tokens},
+	})
+}
+
+func (s *Service) handleGetOrg(w http.ResponseWriter, r *http.Request) {
+	o, err := s.getOrg(r.Context())
+
+	if err != nil {
+		json.Write(w, json.Response{
+			StatusCode: errStatusCode(err),
+			Error:      json.ErrorFromErr(err, "", ""),
+		})
+		return
+	}
+
+	m, _ := MemberFromContext(r.Context())
+
+	json.Write(w, json.Response{
+		Data: orgResponse{Org: *o, Role: m.Role},
+	})
+}
+
+func (s *Service) handleUpdateOrg(w http.ResponseWriter, r *http.Request) {
+	var req updateOrgRequest
+
+	if err := json.UnmarshalBody(r, &req); err != nil {
+		json.Write(w, json.Response{
+			StatusCode: http.StatusBadRequest,
+			Error:      json.ErrorFromErr(err, "", ""),
+		})
+		return
+	}
+
+	o, err := s.updateOrg(r.Context(), req)
+
+	if err != nil {
+		json.Write(w, json.Response{
+			StatusCode: errStatusCode(err),
+			Error:      json.ErrorFromErr(err, "", ""),
+		})
+		return
+	}
+
+	json.Write(w, json.Response{
+		Data: o,
+	})
+}
+
+func (s *Service) handleDeleteOrg(w http.ResponseWriter, r *http.Request) {
+	if err := s.deleteOrg(r.Context()); err != nil {
+		json.Write(w, json.Response{
+			StatusCode: errStatusCode(err),
+			Error:      json.ErrorFromErr(err, "", ""),
+		})
+		return
+	}
+
+	json.Write(w, json.Response{
+		Message: "organization deleted",
+	})
+}
+
+func (s *Service) handleListMembers(w http.ResponseWriter, r *http.Request) {
+	members, err := s.members.ListMembers(r.Context())
+
+	if err != nil {
+		json.Write(w, json.Response{
+			StatusCode: errStatusCode(err),
+			Error:      json.ErrorFromErr(err, "", ""),
+		})
+		return
+	}
+
+	json.Write(w, json.Response{
+		Data: members,
+	})
+}
+
+func (s *Service) handleAddMember(w http.ResponseWriter, r *http.Request) {
+	var req addMemberRequest
+
+	if err := json.UnmarshalBody(r, &req); err != nil {
+		json.Write(w, json.Response{
+			StatusCode: http.StatusBadRequest,
+			Error:      json.ErrorFromErr(err, "", ""),
+		})
+		return
+	}
+
+	m, err := s.addMember(r.Context(), req)
+
+	if err != nil {
+		json.Write(w, json.Response{
+			StatusCode: errStatusCode(err),
+			Error:      json.ErrorFromErr(err, "", ""),
+		})
+		return
+	}
+
+	json.Write(w, json.Response{
+		StatusCode: http.StatusCreated,
+		Data:       m,
+	})
+}
+
+func (s *Service) handleUpdateMember(w http.ResponseWriter, r *http.Request) {
+	var req updateMemberRequest
+
+	if err := json.UnmarshalBody(r, &req); err != nil {
+		json.Write(w, json.Response{
+			StatusCode: http.StatusBadRequest,
+			Error:      json.ErrorFromErr(err, "", ""),
+		})
+		return
+	}
+
+	m, err := s.updateMember(r.Context(), r.PathValue("userId"), req)
+
+	if err != nil {
+		json.Write(w, json.Response{
+			StatusCode: errStatusCode(err),
+			Error:      json.ErrorFromErr(err, "", ""),
+		})
+		return
+	}
+
+	json.Write(w, json.Response{
+		Data: m,
+	})
+}
+
+func (s *Service) handleRemoveMember(w http.ResponseWriter, r *http.Request) {
+	if err := s.removeMember(r.Context(), r.PathValue("userId")); err != nil {
+		json.Write(w, json.Response{
+			StatusCode: errStatusCode(err),
+			Error:      json.ErrorFromErr(err, "", ""),
+		})
+		return
+	}
+
+	json.Write(w, json.Response{
+		Message: "member removed",
+	})
+}
+
+func (s *Service) handleListInvitations(w http.ResponseWriter, r *http.Request) {
+	invitations, err := s.invitations.ListInvitations(r.Context())
+
+	if err != nil {
+		json.Write(w, json.Response{
+			StatusCode: errStatusCode(err),
+			Error:      json.ErrorFromErr(err, "", ""),
+		})
+		return
+	}
+
+	json.Write(w, json.Response{
+		Data: invitations,
+	})
+}
+
+func (s *Service) handleInviteMember(w http.ResponseWriter, r *http.Request) {
+	var req inviteMemberRequest
+
+	if err := json.UnmarshalBody(r, &req); err != nil {
+		json.Write(w, json.Response{
+			StatusCode: http.StatusBadRequest,
+			Error:      json.ErrorFromErr(err, "", ""),
+		})
+		return
+	}
+
+	inv, err := s.inviteMember(r.Context(), req)
+
+	if err != nil {
+		json.Write(w, json.Response{
+			StatusCode: errStatusCode(err),
+			Error:      json.ErrorFromErr(err, "", ""),
+		})
+		return
+	}
+
+	json.Write(w, json.Response{
+		StatusCode: http.StatusCreated,
+		Data:       inv,
+	})
+}
+
+func (s *Service) handleRevokeInvitation(w http.ResponseWriter, r *http.Request) {
+	if err := s.revokeInvitation(r.Context(), r.PathValue("id")); err != nil {
+		json.Write(w, json.Response{
+			StatusCode: errStatusCode(err),
+			Error:      json.ErrorFromErr(err, "", ""),
+		})
+		return
+	}
+
+	json.Write(w, json.Response{
+		Message: "invitation revoked",
+	})
+}
+
+func (s *Service) handlePreviewInvitation(w http.ResponseWriter, r *http.Request) {
+	var req previewInvitationRequest
+
+	if err := json.UnmarshalBody(r, &req); err != nil {
+		json.Write(w, json.Response{
+			StatusCode: http.StatusBadRequest,
+			Error:      json.ErrorFromErr(err, "", ""),
+		})
+		return
+	}
+
+	preview, err := s.previewInvitation(r.Context(), req.Token)
+
+	if err != nil {
+		json.Write(w, json.Response{
+			StatusCode: errStatusCode(err),
+			Error:      json.ErrorFromErr(err, "", ""),
+		})
+		return
+	}
+
+	json.Write(w, json.Response{
+		Data: preview,
+	})
+}
+
+func (s *Service) handleAcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	var (
+		req       acceptInvitationRequest
+		userId, _ = user.UserFromContext(r.Context())
+	)
+
+	if err := json.UnmarshalBody(r, &req); err != nil {
+		json.Write(w, json.Response{
+			StatusCode: http.StatusBadRequest,
+			Error:      json.ErrorFromErr(err, "", ""),
+		})
+		return
+	}
+
+	res, err := s.acceptInvitation(r.Context(), userId, req)
+
+	if err != nil {
+		json.Write(w, json.Response{
+			StatusCode: errStatusCode(err),
+			Error:      json.ErrorFromErr(err, "", ""),
+		})
+		return
+	}
+
+	json.Write(w, json.Response{
+		Data: res,
+	})
+}
+
+func (s *Service) handleInvitationSignup(w http.ResponseWriter, r *http.Request) {
+	var req invitationSignupRequest
+
+	if err := json.UnmarshalBody(r, &req); err != nil {
+		json.Write(w, json.Response{
+			StatusCode: http.StatusBadRequest,
+			Error:      json.ErrorFromErr(err, "", ""),
+		})
+		return
+	}
+
+	res, err := s.signUpInvitation(r.Context(), req)
+
+	if err != nil {
+		json.Write(w, json.Response{
+			StatusCode: errStatusCode(err),
+			Error:      json.ErrorFromErr(err, "", ""),
+		})
+		return
+	}
+
+	json.Write(w, json.Response{
+		StatusCode: http.StatusCreated,
+		Data:       res,
+	})
+}
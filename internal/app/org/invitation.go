@@ -0,0 +1,147 @@
+package org
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	jsonEncoder "encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/huboh/go-rest-api/internal/pkg/env"
+	"github.com/huboh/go-rest-api/internal/pkg/utils"
+)
+
+var (
+	// ErrInvitationNotFound is returned when no pending invitation matches a lookup
+	ErrInvitationNotFound = errors.New("invitation not found")
+
+	// ErrInvitationExists is returned when inviting an email with a pending invitation to the org
+	ErrInvitationExists = errors.New("email already has a pending invitation")
+
+	// ErrInvalidInvitation is returned when an invitation token is malformed, forged or expired
+	ErrInvalidInvitation = errors.New("invalid or expired invitation")
+
+	// ErrInvitationEmail is returned when accepting an invitation addressed to another email
+	ErrInvitationEmail = errors.New("invitation was sent to another email")
+
+	// ErrIncompleteSignup is returned when signing up through an invitation without a username or password
+	ErrIncompleteSignup = errors.New("username and password are required")
+)
+
+// defInvitationExpiration is the default lifetime of invitations.
+const defInvitationExpiration = time.Hour * 24 * 7
+
+// Invitation is a pending invitation of an email to join an Org. it is deleted once accepted.
+type Invitation struct {
+	Id    string `json:"id"`
+	OrgId string `json:"orgId"`
+	Email string `json:"email"`
+	Role  Role   `json:"role"`
+
+	// InvitedBy is the id of the user who sent the invitation, empty once the data of the user is erased.
+	InvitedBy string `json:"invitedBy"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// invitationConfigs holds the configuration of invitations.
+type invitationConfigs struct {
+	// secret signs invitation tokens.
+	secret []byte
+
+	// expiration is the lifetime of invitations.
+	expiration time.Duration
+
+	// acceptUrl is the page invitation links point to, with the token in the "token" query parameter.
+	acceptUrl string
+}
+
+// newInvitationConfigs initializes a new invitationConfigs instance by reading environment variables,
+// falling back to defaults when they are unset.
+func newInvitationConfigs() *invitationConfigs {
+	ic := &invitationConfigs{
+		secret:     []byte(env.MustGet("INVITATION_TOKEN_SECRET")),
+		expiration: defInvitationExpiration,
+		acceptUrl:  env.Get("INVITATION_URL"),
+	}
+
+	if ic.acceptUrl == "" {
+		ic.acceptUrl = utils.Must(url.JoinPath(env.Get("JWT_ISSUER"), "/invitations"))
+	}
+
+	if exp := env.Get("INVITATION_EXPIRATION"); exp != "" {
+		ic.expiration = utils.Must(time.ParseDuration(exp))
+	}
+
+	return ic
+}
+
+// invitationPayload is the signed content of invitation tokens.
+type invitationPayload struct {
+	Id        string `json:"i"`
+	OrgId     string `json:"o"`
+	ExpiresAt int64  `json:"e"`
+}
+
+// encodeToken returns the token of inv as "<payload>.<signature>".
+func (ic *invitationConfigs) encodeToken(inv *Invitation) string {
+	data, _ := jsonEncoder.Marshal(invitationPayload{Id: inv.Id, OrgId: inv.OrgId, ExpiresAt: inv.ExpiresAt.Unix()})
+	enc := base64.RawURLEncoding.EncodeToString(data)
+
+	return enc + "." + base64.RawURLEncoding.EncodeToString(ic.sign(enc))
+}
+
+// decodeToken returns the payload of an unexpired token, or ErrInvalidInvitation.
+func (ic *invitationConfigs) decodeToken(token string) (invitationPayload, error) {
+	payload := invitationPayload{}
+	enc, sig, ok := strings.Cut(token, ".")
+
+	if !ok {
+		return payload, ErrInvalidInvitation
+	}
+
+	rawSig, err := base64.RawURLEncoding.DecodeString(sig)
+
+	if (err != nil) || !hmac.Equal(rawSig, ic.sign(enc)) {
+		return payload, ErrInvalidInvitation
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(enc)
+
+	if (err != nil) || (jsonEncoder.Unmarshal(data, &payload) != nil) {
+		return payload, ErrInvalidInvitation
+	}
+
+	if !time.Now().Before(time.Unix(payload.ExpiresAt, 0)) {
+		return payload, ErrInvalidInvitation
+	}
+
+	return payload, nil
+}
+
+// link returns the link of the page accepting the invitation with the given token.
+func (ic *invitationConfigs) link(token string) string {
+	u, err := url.Parse(ic.acceptUrl)
+
+	if err != nil {
+		return ic.acceptUrl + "?token=" + url.QueryEscape(token)
+	}
+
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+
+	return u.String()
+}
+
+// sign returns the signature of s.
+func (ic *invitationConfigs) sign(s string) []byte {
+	mac := hmac.New(sha256.New, ic.secret)
+	mac.Write([]byte(s))
+
+	return mac.Sum(nil)
+}
@@ -0,0 +1,555 @@
+package org
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/huboh/go-rest-api/internal/app/user"
+	"github.com/huboh/go-rest-api/internal/pkg/tenant"
+
+	mailer "github.com/huboh/go-rest-api/internal/pkg/mail"
+)
+
+var (
+	// ErrForbidden is returned when a member isn't allowed to perform an action
+	ErrForbidden = errors.New("you are not allowed to perform this action")
+
+	// ErrNotMember is returned when acting on an org the user isn't a member of
+	ErrNotMember = errors.New("you are not a member of this organization")
+
+	// ErrInvalidOrg is returned when a change would leave an org or a membership with invalid fields
+	ErrInvalidOrg = errors.New("invalid organization")
+
+	// ErrLastOwner is returned when a change would leave an org without owners
+	ErrLastOwner = errors.New("organizations must keep at least one owner")
+)
+
+// slugRegexp matches the slugs orgs may pick. slugs are valid subdomain labels, shorter than ids.
+var slugRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,30}[a-z0-9]$`)
+
+// createOrg creates the org described by req, owned by the user with the given id.
+func (s *Service) createOrg(ctx context.Context, userId string, req createOrgRequest) (*Org, error) {
+	o := &Org{Name: strings.TrimSpace(req.Name), Slug: strings.ToLower(strings.TrimSpace(req.Slug))}
+
+	if err := validateOrg(o); err != nil {
+		return nil, err
+	}
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.orgs.Create(ctx, o); err != nil {
+			return err
+		}
+
+		return s.members.AddMember(tenant.ContextWithTenant(ctx, o.Id), &Membership{UserId: userId, Role: RoleOwner})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+// listOrgs returns the orgs the user with the given id is a member of, with its role in each.
+func (s *Service) listOrgs(ctx context.Context, userId string) ([]orgResponse, error) {
+	orgs, err := s.orgs.ListByUser(ctx, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	memberships, err := s.members.ListMemberships(ctx, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	roles := map[string]Role{}
+
+	for _, m := range memberships {
+		roles[m.OrgId] = m.Role
+	}
+
+	res := []orgResponse{}
+
+	for _, o := range orgs {
+		res = append(res, orgResponse{Org: o, Role: roles[o.Id]})
+	}
+
+	return res, nil
+}
+
+// issueTokens issues tokens of the user with the given id scoped to the org with id or slug ref.
+func (s *Service) issueTokens(ctx context.Context, userId string, ref string) (any, error) {
+	o, _, err := s.membership(ctx, userId, ref)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.auth.IssueTenantTokens(ctx, userId, o.Id)
+}
+
+// membership returns the org with id or slug ref and the membership of the user with the given id in it.
+// it returns ErrNotMember when either doesn't exist, so orgs can't be discovered by non members.
+func (s *Service) membership(ctx context.Context, userId string, ref string) (*Org, *Membership, error) {
+	o, err := s.orgs.GetById(ctx, ref)
+
+	if errors.Is(err, ErrOrgNotFound) {
+		o, err = s.orgs.GetBySlug(ctx, strings.ToLower(ref))
+	}
+
+	if errors.Is(err, ErrOrgNotFound) {
+		return nil, nil, ErrNotMember
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	m, err := s.members.GetMember(tenant.ContextWithTenant(ctx, o.Id), userId)
+
+	if errors.Is(err, ErrMemberNotFound) {
+		return nil, nil, ErrNotMember
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return o, m, nil
+}
+
+// getOrg returns the active tenant.
+func (s *Service) getOrg(ctx context.Context) (*Org, error) {
+	id, err := tenant.Require(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.orgs.GetById(ctx, id)
+}
+
+// updateOrg applies the fields set in req to the active tenant.
+func (s *Service) updateOrg(ctx context.Context, req updateOrgRequest) (*Org, error) {
+	o, err := s.getOrg(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		o.Name = strings.TrimSpace(*req.Name)
+	}
+
+	if req.Slug != nil {
+		o.Slug = strings.ToLower(strings.TrimSpace(*req.Slug))
+	}
+
+	if err := validateOrg(o); err != nil {
+		return nil, err
+	}
+
+	if err := s.orgs.Update(ctx, o); err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+// deleteOrg deletes the active tenant along with its memberships.
+func (s *Service) deleteOrg(ctx context.Context) error {
+	id, err := tenant.Require(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	return s.orgs.Delete(ctx, id)
+}
+
+// addMember adds the user described by req to the active tenant. members can't grant roles above their own.
+func (s *Service) addMember(ctx context.Context, req addMemberRequest) (*Membership, error) {
+	if req.Role == "" {
+		req.Role = RoleMember
+	}
+
+	if err := s.checkGrant(ctx, req.Role); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.users.GetById(ctx, req.UserId); err != nil {
+		return nil, err
+	}
+
+	m := &Membership{UserId: req.UserId, Role: req.Role}
+
+	if err := s.members.AddMember(ctx, m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// updateMember changes the role of the member with the given user id. members can't change the role of
+// members above them, nor grant roles above their own.
+func (s *Service) updateMember(ctx context.Context, userId string, req updateMemberRequest) (*Membership, error) {
+	if err := s.checkGrant(ctx, req.Role); err != nil {
+		return nil, err
+	}
+
+	var m *Membership
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+
+		if m, err = s.members.GetMember(ctx, userId); err != nil {
+			return err
+		}
+
+		if err := s.checkManage(ctx, m); err != nil {
+			return err
+		}
+
+		if (m.Role == RoleOwner) && (req.Role != RoleOwner) {
+			if err := s.checkOtherOwners(ctx); err != nil {
+				return err
+			}
+		}
+
+		m.Role = req.Role
+
+		return s.members.UpdateMember(ctx, m)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// removeMember removes the member with the given user id from the active tenant. members may leave,
+// or remove the members they are allowed to manage.
+func (s *Service) removeMember(ctx context.Context, userId string) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		m, err := s.members.GetMember(ctx, userId)
+
+		if err != nil {
+			return err
+		}
+
+		if actor, _ := MemberFromContext(ctx); actor.UserId != userId {
+			if err := s.checkManage(ctx, m); err != nil {
+				return err
+			}
+		}
+
+		if m.Role == RoleOwner {
+			if err := s.checkOtherOwners(ctx); err != nil {
+				return err
+			}
+		}
+
+		return s.members.RemoveMember(ctx, userId)
+	})
+}
+
+// inviteMember invites the email in req to join the active tenant and emails it the invitation link.
+// members can't invite with roles above their own.
+func (s *Service) inviteMember(ctx context.Context, req inviteMemberRequest) (*Invitation, error) {
+	if req.Role == "" {
+		req.Role = RoleMember
+	}
+
+	if err := s.checkGrant(ctx, req.Role); err != nil {
+		return nil, err
+	}
+
+	email := user.NormalizeEmail(req.Email)
+
+	if addr, err := mail.ParseAddress(email); (err != nil) || (addr.Address != email) {
+		return nil, fmt.Errorf("%w: email is not a valid address", ErrInvalidOrg)
+	}
+
+	if err := s.checkNotMember(ctx, email); err != nil {
+		return nil, err
+	}
+
+	o, err := s.getOrg(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		actor, _ = MemberFromContext(ctx)
+		inv      = &Invitation{
+			Email:     email,
+			Role:      req.Role,
+			InvitedBy: actor.UserId,
+			ExpiresAt: time.Now().Add(s.inviteCfgs.expiration).UTC(),
+		}
+	)
+
+	if err := s.invitations.CreateInvitation(ctx, inv); err != nil {
+		return nil, err
+	}
+
+	// invitations that can't be delivered are withdrawn, so they can be sent again
+	if err := s.sendInvitation(ctx, o, inv); err != nil {
+		s.invitations.DeleteInvitation(ctx, inv.Id)
+		return nil, err
+	}
+
+	return inv, nil
+}
+
+// checkNotMember returns ErrMemberExists when the user with the given email is a member of the active tenant.
+func (s *Service) checkNotMember(ctx context.Context, email string) error {
+	u, err := s.users.GetByEmail(ctx, email)
+
+	if errors.Is(err, user.ErrUserNotFound) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if _, err = s.members.GetMember(ctx, u.Id); err == nil {
+		return ErrMemberExists
+	}
+
+	if errors.Is(err, ErrMemberNotFound) {
+		return nil
+	}
+
+	return err
+}
+
+// sendInvitation emails the link of inv to its email.
+func (s *Service) sendInvitation(ctx context.Context, o *Org, inv *Invitation) error {
+	link := s.inviteCfgs.link(s.inviteCfgs.encodeToken(inv))
+
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      inv.Email,
+		Subject: fmt.Sprintf("You are invited to join %s", o.Name),
+		Text: fmt.Sprintf(
+			"You have been invited to join %s as %s.\r\n\r\nAccept the invitation by opening the link below before %s:\r\n\r\n%s\r\n",
+			o.Name, inv.Role, inv.ExpiresAt.Format(time.RFC1123), link,
+		),
+	})
+}
+
+// revokeInvitation withdraws the invitation with the given id to the active tenant.
+func (s *Service) revokeInvitation(ctx context.Context, id string) error {
+	return s.invitations.DeleteInvitation(ctx, id)
+}
+
+// previewInvitation describes the invitation of token, so invitees can tell whether to log in or sign up to accept it.
+func (s *Service) previewInvitation(ctx context.Context, token string) (invitationPreview, error) {
+	ctx, o, inv, err := s.invitation(ctx, token)
+
+	if err != nil {
+		return invitationPreview{}, err
+	}
+
+	_, err = s.users.GetByEmail(ctx, inv.Email)
+
+	if (err != nil) && !errors.Is(err, user.ErrUserNotFound) {
+		return invitationPreview{}, err
+	}
+
+	return invitationPreview{
+		Org:           *o,
+		Email:         inv.Email,
+		Role:          inv.Role,
+		ExpiresAt:     inv.ExpiresAt,
+		AccountExists: err == nil,
+	}, nil
+}
+
+// acceptInvitation adds the user with the given id to the org it was invited to by the invitation of req.
+// the invitation must have been sent to the email of the user.
+func (s *Service) acceptInvitation(ctx context.Context, userId string, req acceptInvitationRequest) (acceptInvitationResponse, error) {
+	ctx, _, inv, err := s.invitation(ctx, req.Token)
+
+	if err != nil {
+		return acceptInvitationResponse{}, err
+	}
+
+	u, err := s.users.GetById(ctx, userId)
+
+	if err != nil {
+		return acceptInvitationResponse{}, err
+	}
+
+	if u.Email != inv.Email {
+		return acceptInvitationResponse{}, ErrInvitationEmail
+	}
+
+	return s.redeemInvitation(ctx, inv, func(ctx context.Context) (*user.User, error) { return u, nil })
+}
+
+// signUpInvitation creates an account for the email the invitation of req was sent to, and adds it to the
+// org it was invited to.
+func (s *Service) signUpInvitation(ctx context.Context, req invitationSignupRequest) (acceptInvitationResponse, error) {
+	if (strings.TrimSpace(req.Username) == "") || (req.Password == "") {
+		return acceptInvitationResponse{}, ErrIncompleteSignup
+	}
+
+	ctx, _, inv, err := s.invitation(ctx, req.Token)
+
+	if err != nil {
+		return acceptInvitationResponse{}, err
+	}
+
+	var u *user.User
+
+	res, err := s.redeemInvitation(ctx, inv, func(ctx context.Context) (*user.User, error) {
+		u, err = s.auth.CreateAccount(ctx, req.Name, inv.Email, req.Username, req.Password)
+		return u, err
+	})
+
+	if err != nil {
+		return acceptInvitationResponse{}, err
+	}
+
+	res.User = u
+
+	return res, nil
+}
+
+// redeemInvitation deletes inv and adds the user returned by account to its org, within a single transaction.
+// ctx must have the org of inv as active tenant.
+func (s *Service) redeemInvitation(ctx context.Context, inv *Invitation, account func(ctx context.Context) (*user.User, error)) (acceptInvitationResponse, error) {
+	var (
+		res = acceptInvitationResponse{}
+		m   = &Membership{Role: inv.Role}
+	)
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		// deleting first makes sure concurrent redemptions of the same invitation fail
+		if err := s.invitations.DeleteInvitation(ctx, inv.Id); err != nil {
+			return err
+		}
+
+		u, err := account(ctx)
+
+		if err != nil {
+			return err
+		}
+
+		m.UserId = u.Id
+
+		return s.members.AddMember(ctx, m)
+	})
+
+	if errors.Is(err, ErrInvitationNotFound) {
+		return res, ErrInvalidInvitation
+	}
+
+	if err != nil {
+		return res, err
+	}
+
+	if res.Tokens, err = s.auth.IssueTenantTokens(ctx, m.UserId, m.OrgId); err != nil {
+		return res, err
+	}
+
+	res.Membership = *m
+
+	return res, nil
+}
+
+// invitation returns the pending invitation of token along with its org, and a copy of ctx in which
+// the org is the active tenant.
+func (s *Service) invitation(ctx context.Context, token string) (context.Context, *Org, *Invitation, error) {
+	payload, err := s.inviteCfgs.decodeToken(token)
+
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	ctx = tenant.ContextWithTenant(ctx, payload.OrgId)
+	inv, err := s.invitations.GetInvitation(ctx, payload.Id)
+
+	if errors.Is(err, ErrInvitationNotFound) {
+		return nil, nil, nil, ErrInvalidInvitation
+	}
+
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	o, err := s.orgs.GetById(ctx, inv.OrgId)
+
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return ctx, o, inv, nil
+}
+
+// checkGrant returns ErrInvalidOrg when r is unknown, and ErrForbidden when it is above the role of the acting member.
+func (s *Service) checkGrant(ctx context.Context, r Role) error {
+	if !r.Valid() {
+		return fmt.Errorf("%w: unknown role \"%s\"", ErrInvalidOrg, r)
+	}
+
+	if actor, _ := MemberFromContext(ctx); !actor.Role.AtLeast(r) {
+		return ErrForbidden
+	}
+
+	return nil
+}
+
+// checkManage returns ErrForbidden when m is above the role of the acting member.
+func (s *Service) checkManage(ctx context.Context, m *Membership) error {
+	if actor, _ := MemberFromContext(ctx); !actor.Role.AtLeast(m.Role) {
+		return ErrForbidden
+	}
+
+	return nil
+}
+
+// checkOtherOwners returns ErrLastOwner unless the active tenant has more than one owner.
+func (s *Service) checkOtherOwners(ctx context.Context) error {
+	n, err := s.members.CountRole(ctx, RoleOwner)
+
+	if err != nil {
+		return err
+	}
+
+	if n < 2 {
+		return ErrLastOwner
+	}
+
+	return nil
+}
+
+// validateOrg checks the fields of o.
+func validateOrg(o *Org) error {
+	if o.Name == "" {
+		return fmt.Errorf("%w: name must not be empty", ErrInvalidOrg)
+	}
+
+	// names end up in the subject of invitation emails
+	if strings.ContainsFunc(o.Name, unicode.IsControl) {
+		return fmt.Errorf("%w: name must not contain control characters", ErrInvalidOrg)
+	}
+
+	if !slugRegexp.MatchString(o.Slug) {
+		return fmt.Errorf("%w: slug must be 3 to 32 lowercase letters, digits or \"-\", starting and ending with a letter or digit", ErrInvalidOrg)
+	}
+
+	return nil
+}
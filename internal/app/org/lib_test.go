@@ -0,0 +1,24 @@
+package org
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateOrg(t *testing.T) {
+	tests := []struct {
+		org  Org
+		want error
+	}{
+		{Org{Name: "Acme", Slug: "acme"}, nil},
+		{Org{Name: "", Slug: "acme"}, ErrInvalidOrg},
+		{Org{Name: "Acme\r\nBcc: eve@example.com", Slug: "acme"}, ErrInvalidOrg},
+		{Org{Name: "Acme", Slug: "-acme"}, ErrInvalidOrg},
+	}
+
+	for _, tt := range tests {
+		if err := validateOrg(&tt.org); !errors.Is(err, tt.want) {
+			t.Errorf("validateOrg(%q) = %v, want %v", tt.org.Name, err, tt.want)
+		}
+	}
+}
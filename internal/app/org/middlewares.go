@@ -0,0 +1,90 @@
+package org
+
+import (
+	"net/http"
+
+	"github.com/huboh/go-rest-api/internal/app/user"
+	"github.com/huboh/go-rest-api/internal/pkg/json"
+	"github.com/huboh/go-rest-api/internal/pkg/middleware"
+	"github.com/huboh/go-rest-api/internal/pkg/tenant"
+)
+
+// TenantMiddleware resolves the active tenant of requests with a tenant.Resolver, rejects requests of users
+// who aren't members of it, and stores it in the request context for tenant.FromContext along with the
+// membership of the user for MemberFromContext.
+// it must be wrapped by a middleware storing the authenticated user with user.ContextWithUser.
+func (s *Service) TenantMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			userId, ok := user.UserFromContext(r.Context())
+
+			if !ok {
+				json.Write(w, json.Response{
+					StatusCode: http.StatusUnauthorized,
+					Error:      &json.Error{Name: "Unauthorized"},
+				})
+				return
+			}
+
+			o, m, err := s.resolveTenant(r, userId)
+
+			if err != nil {
+				json.Write(w, json.Response{
+					StatusCode: errStatusCode(err),
+					Error:      json.ErrorFromErr(err, "", ""),
+				})
+				return
+			}
+
+			ctx := contextWithMember(tenant.ContextWithTenant(r.Context(), o.Id), *m)
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		},
+	)
+}
+
+// RoleGuard returns a middleware rejecting requests of members below role r in the active tenant.
+// it must be wrapped by TenantMiddleware.
+func (s *Service) RoleGuard(r Role) middleware.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, req *http.Request) {
+				if m, ok := MemberFromContext(req.Context()); !ok || !m.Role.AtLeast(r) {
+					json.Write(w, json.Response{
+						StatusCode: http.StatusForbidden,
+						Error:      json.ErrorFromErr(ErrForbidden, "Forbidden", ""),
+					})
+					return
+				}
+
+				next.ServeHTTP(w, req)
+			},
+		)
+	}
+}
+
+// resolveTenant returns the tenant named by r and the membership of the user with the given id in it.
+// the tenant claimed by the access token wins, r must not name another one.
+func (s *Service) resolveTenant(r *http.Request, userId string) (*Org, *Membership, error) {
+	req := s.resolver.Resolve(r)
+
+	if req.Claim == "" {
+		if req.Ref == "" {
+			return nil, nil, tenant.ErrNoTenant
+		}
+
+		return s.membership(r.Context(), userId, req.Ref)
+	}
+
+	o, m, err := s.membership(r.Context(), userId, req.Claim)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if (req.Ref != "") && (req.Ref != o.Id) && (req.Ref != o.Slug) {
+		return nil, nil, tenant.ErrTenantMismatch
+	}
+
+	return o, m, nil
+}
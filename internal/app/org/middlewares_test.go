@@ -0,0 +1,72 @@
+package org
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/huboh/go-rest-api/internal/app/user"
+	"github.com/huboh/go-rest-api/internal/pkg/tenant"
+)
+
+func TestTenantGuards(t *testing.T) {
+	s, repo, _ := newTestService(t)
+
+	acme := createOrg(t, repo, "acme", Membership{UserId: "jane", Role: RoleOwner}, Membership{UserId: "john", Role: RoleMember})
+	other := createOrg(t, repo, "other", Membership{UserId: "bob", Role: RoleOwner})
+
+	var active string
+
+	h := s.TenantMiddleware(s.RoleGuard(RoleAdmin)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		active, _ = tenant.FromContext(r.Context())
+	})))
+
+	tests := []struct {
+		name   string
+		userId string
+		claim  string
+		ref    string
+		want   int
+	}{
+		{"by id", "jane", "", acme.Id, http.StatusOK},
+		{"by slug", "jane", "", "ACME", http.StatusOK},
+		{"claimed", "jane", acme.Id, "", http.StatusOK},
+		{"claimed and named", "jane", acme.Id, "acme", http.StatusOK},
+		{"unauthenticated", "", "", acme.Id, http.StatusUnauthorized},
+		{"no tenant", "jane", "", "", http.StatusBadRequest},
+		{"not a member", "jane", "", other.Id, http.StatusForbidden},
+		{"unknown org", "jane", "", "unknown", http.StatusForbidden},
+		{"mismatched claim", "jane", acme.Id, other.Id, http.StatusForbidden},
+		{"below the role", "john", "", acme.Id, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			active = ""
+			ctx := context.Background()
+
+			if tt.userId != "" {
+				ctx = user.ContextWithUser(ctx, tt.userId)
+			}
+
+			if tt.claim != "" {
+				ctx = tenant.ContextWithClaim(ctx, tt.claim)
+			}
+
+			r := httptest.NewRequest(http.MethodGet, "/org", nil).WithContext(ctx)
+			r.Header.Set(s.resolver.Header, tt.ref)
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+
+			if (tt.want == http.StatusOK) && (active != acme.Id) {
+				t.Errorf("active tenant = %q, want %q", active, acme.Id)
+			}
+		})
+	}
+}
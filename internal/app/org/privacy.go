@@ -0,0 +1,153 @@
+package org
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/huboh/go-rest-api/internal/app/user"
+	"github.com/huboh/go-rest-api/internal/pkg/privacy"
+	"github.com/huboh/go-rest-api/internal/pkg/tenant"
+)
+
+// privacyProvider exports and erases the memberships of users, and the invitations sent to or by them.
+type privacyProvider struct {
+	s *Service
+}
+
+// membershipData is a membership included in export archives.
+type membershipData struct {
+	Membership
+	OrgName string `json:"orgName"`
+}
+
+// orgsData is the data of a user included in export archives.
+type orgsData struct {
+	Memberships []membershipData `json:"memberships"`
+	Invitations []Invitation     `json:"invitations"`
+}
+
+// PrivacyProvider returns the privacy.Provider of the memberships and invitations held by s.
+func (s *Service) PrivacyProvider() privacy.Provider {
+	return privacyProvider{s: s}
+}
+
+func (p privacyProvider) Name() string {
+	return "orgs"
+}
+
+func (p privacyProvider) Export(ctx context.Context, userId string) (any, error) {
+	memberships, err := p.s.members.ListMemberships(ctx, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	email, err := p.email(ctx, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	invitations, err := p.s.invitations.ListUserInvitations(ctx, email, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	data := orgsData{Memberships: []membershipData{}, Invitations: invitations}
+
+	for _, m := range memberships {
+		o, err := p.s.orgs.GetById(ctx, m.OrgId)
+
+		if err != nil {
+			return nil, err
+		}
+
+		data.Memberships = append(data.Memberships, membershipData{Membership: m, OrgName: o.Name})
+	}
+
+	return data, nil
+}
+
+// Erase removes the user from its orgs, whether its data is erased on request or its deleted account
+// is purged. orgs left without members are deleted, and orgs left without owners are handed over to
+// their longest standing admin, or to their longest standing member when they have no admin.
+//
+// the invitations sent to the user are deleted, and those it sent are kept for their invitees, without
+// their sender.
+func (p privacyProvider) Erase(ctx context.Context, userId string) error {
+	memberships, err := p.s.members.ListMemberships(ctx, userId)
+
+	if err != nil {
+		return err
+	}
+
+	email, err := p.email(ctx, userId)
+
+	if err != nil {
+		return err
+	}
+
+	return p.s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := p.s.invitations.EraseUserInvitations(ctx, email, userId); err != nil {
+			return err
+		}
+
+		for _, m := range memberships {
+			if err := p.leave(tenant.ContextWithTenant(ctx, m.OrgId), m); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// leave removes m from the active tenant of ctx, keeping the tenant owned.
+func (p privacyProvider) leave(ctx context.Context, m Membership) error {
+	if err := p.s.members.RemoveMember(ctx, m.UserId); (err != nil) && !errors.Is(err, ErrMemberNotFound) {
+		return err
+	}
+
+	members, err := p.s.members.ListMembers(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	if len(members) == 0 {
+		return p.s.orgs.Delete(ctx, m.OrgId)
+	}
+
+	if slices.ContainsFunc(members, func(o Membership) bool { return o.Role == RoleOwner }) {
+		return nil
+	}
+
+	// members are listed the earliest first
+	heir := members[0]
+
+	if i := slices.IndexFunc(members, func(o Membership) bool { return o.Role == RoleAdmin }); i >= 0 {
+		heir = members[i]
+	}
+
+	heir.Role = RoleOwner
+
+	return p.s.members.UpdateMember(ctx, &heir)
+}
+
+// email returns the email of the user with the given id, deleted or not, or an empty string when it
+// was already purged.
+func (p privacyProvider) email(ctx context.Context, userId string) (string, error) {
+	u, err := p.s.users.GetById(user.WithDeleted(ctx), userId)
+
+	if errors.Is(err, user.ErrUserNotFound) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return u.Email, nil
+}
@@ -0,0 +1,150 @@
+package org
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/huboh/go-rest-api/internal/app/user"
+	"github.com/huboh/go-rest-api/internal/pkg/database"
+	"github.com/huboh/go-rest-api/internal/pkg/tenant"
+)
+
+// newTestService creates a Service storing its orgs and users in memory.
+func newTestService(t *testing.T) (*Service, *MemoryRepository, *user.MemoryRepository) {
+	t.Helper()
+	t.Setenv("INVITATION_TOKEN_SECRET", "secret")
+
+	var (
+		repo  = NewMemoryRepository()
+		users = user.NewMemoryRepository()
+	)
+
+	return NewService(repo, repo, repo, users, nil, nil, database.NoopTxManager{}), repo, users
+}
+
+// createOrg creates an org named name with the given members, by user id.
+func createOrg(t *testing.T, repo *MemoryRepository, name string, members ...Membership) *Org {
+	t.Helper()
+
+	o := &Org{Name: name, Slug: name}
+
+	if err := repo.Create(context.Background(), o); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := tenant.ContextWithTenant(context.Background(), o.Id)
+
+	for _, m := range members {
+		if err := repo.AddMember(ctx, &m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return o
+}
+
+func TestPrivacyProviderErase(t *testing.T) {
+	var (
+		ctx        = context.Background()
+		s, repo, _ = newTestService(t)
+	)
+
+	handedOver := createOrg(t, repo, "handed-over",
+		Membership{UserId: "jane", Role: RoleOwner},
+		Membership{UserId: "john", Role: RoleMember},
+		Membership{UserId: "ann", Role: RoleAdmin},
+	)
+	alone := createOrg(t, repo, "alone", Membership{UserId: "jane", Role: RoleOwner})
+	owned := createOrg(t, repo, "owned", Membership{UserId: "bob", Role: RoleOwner}, Membership{UserId: "jane", Role: RoleMember})
+
+	if err := s.PrivacyProvider().Erase(ctx, "jane"); err != nil {
+		t.Fatalf("Erase() = %s", err)
+	}
+
+	if memberships, _ := repo.ListMemberships(ctx, "jane"); len(memberships) != 0 {
+		t.Errorf("memberships after Erase() = %+v, want none", memberships)
+	}
+
+	// the admin is preferred to the longer standing member
+	roles := map[string]Role{}
+	members, _ := repo.ListMembers(tenant.ContextWithTenant(ctx, handedOver.Id))
+
+	for _, m := range members {
+		roles[m.UserId] = m.Role
+	}
+
+	if (roles["ann"] != RoleOwner) || (roles["john"] != RoleMember) {
+		t.Errorf("roles of the org left without owner = %v, want ann as owner", roles)
+	}
+
+	if _, err := repo.GetById(ctx, alone.Id); !errors.Is(err, ErrOrgNotFound) {
+		t.Errorf("org left without members = %v, want ErrOrgNotFound", err)
+	}
+
+	if members, _ := repo.ListMembers(tenant.ContextWithTenant(ctx, owned.Id)); (len(members) != 1) || (members[0].UserId != "bob") {
+		t.Errorf("members of the owned org = %+v, want bob only", members)
+	}
+
+	// erasures are retried, erasing a user without data succeeds
+	if err := s.PrivacyProvider().Erase(ctx, "jane"); err != nil {
+		t.Errorf("Erase() of an erased user = %s", err)
+	}
+}
+
+func TestPrivacyProviderInvitations(t *testing.T) {
+	var (
+		ctx            = context.Background()
+		s, repo, users = newTestService(t)
+		jane           = &user.User{Name: "Jane", Email: "jane@example.com", Username: "jane"}
+	)
+
+	if err := users.Create(ctx, jane); err != nil {
+		t.Fatal(err)
+	}
+
+	o := createOrg(t, repo, "acme", Membership{UserId: "bob", Role: RoleOwner}, Membership{UserId: jane.Id, Role: RoleAdmin})
+	orgCtx := tenant.ContextWithTenant(ctx, o.Id)
+	expires := time.Now().Add(time.Hour)
+
+	invitations := []*Invitation{
+		{Email: jane.Email, Role: RoleMember, InvitedBy: "bob", ExpiresAt: expires},
+		{Email: "john@example.com", Role: RoleMember, InvitedBy: jane.Id, ExpiresAt: expires},
+		{Email: "ann@example.com", Role: RoleMember, InvitedBy: "bob", ExpiresAt: expires},
+	}
+
+	for _, inv := range invitations {
+		if err := repo.CreateInvitation(orgCtx, inv); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := s.PrivacyProvider().Export(ctx, jane.Id)
+
+	if err != nil {
+		t.Fatalf("Export() = %s", err)
+	}
+
+	if exported := data.(orgsData).Invitations; len(exported) != 2 {
+		t.Errorf("exported invitations = %+v, want those sent to and by jane", exported)
+	}
+
+	if err := s.PrivacyProvider().Erase(ctx, jane.Id); err != nil {
+		t.Fatalf("Erase() = %s", err)
+	}
+
+	left, _ := repo.ListInvitations(orgCtx)
+	senders := map[string]string{}
+
+	for _, inv := range left {
+		senders[inv.Email] = inv.InvitedBy
+	}
+
+	want := map[string]string{"john@example.com": "", "ann@example.com": "bob"}
+
+	if !maps.Equal(senders, want) {
+		t.Errorf("senders of the invitations left = %v, want %v", senders, want)
+	}
+}
@@ -0,0 +1,104 @@
+package org
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrOrgNotFound is returned when no org matches a lookup
+	ErrOrgNotFound = errors.New("organization not found")
+
+	// ErrOrgExists is returned when an org with the same slug already exists
+	ErrOrgExists = errors.New("organization with the same slug already exists")
+
+	// ErrMemberNotFound is returned when the user isn't a member of the org
+	ErrMemberNotFound = errors.New("member not found")
+
+	// ErrMemberExists is returned when adding a user who already is a member of the org
+	ErrMemberExists = errors.New("user already is a member of the organization")
+)
+
+// OrgRepository persists orgs. orgs are the tenants themselves, so it isn't tenant scoped.
+type OrgRepository interface {
+	// Create stores o, assigning its id and timestamps.
+	//
+	// return ErrOrgExists when the slug is taken
+	Create(ctx context.Context, o *Org) error
+
+	// GetById returns the org with the given id, or ErrOrgNotFound.
+	GetById(ctx context.Context, id string) (*Org, error)
+
+	// GetBySlug returns the org with the given slug, or ErrOrgNotFound.
+	GetBySlug(ctx context.Context, slug string) (*Org, error)
+
+	// Update replaces the stored org with the same id, refreshing its UpdatedAt.
+	//
+	// return ErrOrgNotFound when no such org exists and ErrOrgExists when the slug is taken
+	Update(ctx context.Context, o *Org) error
+
+	// Delete removes the org with the given id along with its memberships, or returns ErrOrgNotFound.
+	Delete(ctx context.Context, id string) error
+
+	// ListByUser returns the orgs the user with the given id is a member of, by name.
+	ListByUser(ctx context.Context, userId string) ([]Org, error)
+}
+
+// MemberRepository persists memberships.
+//
+// It is tenant scoped: its methods only see the members of the active tenant of ctx, set by
+// tenant.ContextWithTenant, and return tenant.ErrNoTenant when there is none. ListMemberships is
+// the exception, it lists the memberships of a user across tenants.
+type MemberRepository interface {
+	// AddMember stores m as a member of the active tenant, assigning its org id and timestamps.
+	//
+	// return ErrMemberExists when the user already is a member
+	AddMember(ctx context.Context, m *Membership) error
+
+	// GetMember returns the membership of the user with the given id, or ErrMemberNotFound.
+	GetMember(ctx context.Context, userId string) (*Membership, error)
+
+	// UpdateMember replaces the role of the stored membership of m.UserId, refreshing its UpdatedAt.
+	//
+	// return ErrMemberNotFound when the user isn't a member
+	UpdateMember(ctx context.Context, m *Membership) error
+
+	// RemoveMember removes the membership of the user with the given id, or returns ErrMemberNotFound.
+	RemoveMember(ctx context.Context, userId string) error
+
+	// ListMembers returns the members of the active tenant, the earliest first.
+	ListMembers(ctx context.Context) ([]Membership, error)
+
+	// CountRole returns the number of members of the active tenant with role r.
+	CountRole(ctx context.Context, r Role) (int, error)
+
+	// ListMemberships returns the memberships of the user with the given id in every tenant.
+	ListMemberships(ctx context.Context, userId string) ([]Membership, error)
+}
+
+// InvitationRepository persists invitations. like MemberRepository, it is tenant scoped.
+// expired invitations are never returned.
+type InvitationRepository interface {
+	// CreateInvitation stores inv as an invitation to the active tenant, assigning its id, org id and CreatedAt.
+	//
+	// return ErrInvitationExists when the email already has a pending invitation
+	CreateInvitation(ctx context.Context, inv *Invitation) error
+
+	// GetInvitation returns the invitation with the given id, or ErrInvitationNotFound.
+	GetInvitation(ctx context.Context, id string) (*Invitation, error)
+
+	// ListInvitations returns the pending invitations of the active tenant, the latest first.
+	ListInvitations(ctx context.Context) ([]Invitation, error)
+
+	// DeleteInvitation removes the invitation with the given id, or returns ErrInvitationNotFound.
+	// since invitations are accepted by deleting them, it must only succeed for one concurrent caller.
+	DeleteInvitation(ctx context.Context, id string) error
+
+	// ListUserInvitations returns the invitations of every tenant sent to email or by the user with the
+	// given id, expired ones included, the latest first.
+	ListUserInvitations(ctx context.Context, email string, userId string) ([]Invitation, error)
+
+	// EraseUserInvitations deletes the invitations of every tenant sent to email, expired ones included,
+	// and clears the InvitedBy of those sent by the user with the given id.
+	EraseUserInvitations(ctx context.Context, email string, userId string) error
+}
@@ -0,0 +1,414 @@
+package org
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/huboh/go-rest-api/internal/pkg/tenant"
+	"github.com/huboh/go-rest-api/internal/pkg/utils"
+)
+
+// MemoryRepository is an in-memory OrgRepository, MemberRepository and InvitationRepository, mainly useful
+// for tests and local development.
+type MemoryRepository struct {
+	mu          sync.RWMutex
+	orgs        []Org
+	members     []Membership
+	invitations []Invitation
+}
+
+// NewMemoryRepository creates an empty MemoryRepository.
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{}
+}
+
+func (m *MemoryRepository) Create(ctx context.Context, o *Org) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.slugTaken(o) {
+		return ErrOrgExists
+	}
+
+	now := time.Now().UTC()
+
+	o.Id = utils.NewUUID()
+	o.CreatedAt = now
+	o.UpdatedAt = now
+
+	m.orgs = append(m.orgs, *o)
+
+	return nil
+}
+
+func (m *MemoryRepository) GetById(ctx context.Context, id string) (*Org, error) {
+	return m.findOrg(func(o Org) bool { return o.Id == id })
+}
+
+func (m *MemoryRepository) GetBySlug(ctx context.Context, slug string) (*Org, error) {
+	return m.findOrg(func(o Org) bool { return o.Slug == slug })
+}
+
+func (m *MemoryRepository) Update(ctx context.Context, o *Org) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := slices.IndexFunc(m.orgs, func(other Org) bool { return other.Id == o.Id })
+
+	if i < 0 {
+		return ErrOrgNotFound
+	}
+
+	if m.slugTaken(o) {
+		return ErrOrgExists
+	}
+
+	o.CreatedAt = m.orgs[i].CreatedAt
+	o.UpdatedAt = time.Now().UTC()
+	m.orgs[i] = *o
+
+	return nil
+}
+
+func (m *MemoryRepository) Delete(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := slices.IndexFunc(m.orgs, func(o Org) bool { return o.Id == id })
+
+	if i < 0 {
+		return ErrOrgNotFound
+	}
+
+	m.orgs = slices.Delete(m.orgs, i, i+1)
+	m.members = slices.DeleteFunc(m.members, func(mb Membership) bool { return mb.OrgId == id })
+	m.invitations = slices.DeleteFunc(m.invitations, func(inv Invitation) bool { return inv.OrgId == id })
+
+	return nil
+}
+
+func (m *MemoryRepository) ListByUser(ctx context.Context, userId string) ([]Org, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	orgs := []Org{}
+
+	for _, o := range m.orgs {
+		if slices.ContainsFunc(m.members, func(mb Membership) bool { return (mb.OrgId == o.Id) && (mb.UserId == userId) }) {
+			orgs = append(orgs, o)
+		}
+	}
+
+	slices.SortFunc(orgs, func(a, b Org) int { return strings.Compare(a.Name, b.Name) })
+
+	return orgs, nil
+}
+
+func (m *MemoryRepository) AddMember(ctx context.Context, mb *Membership) error {
+	orgId, err := tenant.Require(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.memberIndex(orgId, mb.UserId) >= 0 {
+		return ErrMemberExists
+	}
+
+	now := time.Now().UTC()
+
+	mb.OrgId = orgId
+	mb.CreatedAt = now
+	mb.UpdatedAt = now
+
+	m.members = append(m.members, *mb)
+
+	return nil
+}
+
+func (m *MemoryRepository) GetMember(ctx context.Context, userId string) (*Membership, error) {
+	orgId, err := tenant.Require(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	i := m.memberIndex(orgId, userId)
+
+	if i < 0 {
+		return nil, ErrMemberNotFound
+	}
+
+	mb := m.members[i]
+
+	return &mb, nil
+}
+
+func (m *MemoryRepository) UpdateMember(ctx context.Context, mb *Membership) error {
+	orgId, err := tenant.Require(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.memberIndex(orgId, mb.UserId)
+
+	if i < 0 {
+		return ErrMemberNotFound
+	}
+
+	m.members[i].Role = mb.Role
+	m.members[i].UpdatedAt = time.Now().UTC()
+	*mb = m.members[i]
+
+	return nil
+}
+
+func (m *MemoryRepository) RemoveMember(ctx context.Context, userId string) error {
+	orgId, err := tenant.Require(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.memberIndex(orgId, userId)
+
+	if i < 0 {
+		return ErrMemberNotFound
+	}
+
+	m.members = slices.Delete(m.members, i, i+1)
+
+	return nil
+}
+
+func (m *MemoryRepository) ListMembers(ctx context.Context) ([]Membership, error) {
+	orgId, err := tenant.Require(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.filterMembers(func(mb Membership) bool { return mb.OrgId == orgId }), nil
+}
+
+func (m *MemoryRepository) CountRole(ctx context.Context, r Role) (int, error) {
+	members, err := m.ListMembers(ctx)
+
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+
+	for _, mb := range members {
+		if mb.Role == r {
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+func (m *MemoryRepository) ListMemberships(ctx context.Context, userId string) ([]Membership, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.filterMembers(func(mb Membership) bool { return mb.UserId == userId }), nil
+}
+
+func (m *MemoryRepository) CreateInvitation(ctx context.Context, inv *Invitation) error {
+	orgId, err := tenant.Require(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now().UTC()
+
+	// expired invitations are cleaned up lazily, as new ones are created
+	m.invitations = slices.DeleteFunc(m.invitations, func(other Invitation) bool { return !other.ExpiresAt.After(now) })
+
+	if slices.ContainsFunc(m.invitations, func(other Invitation) bool { return (other.OrgId == orgId) && (other.Email == inv.Email) }) {
+		return ErrInvitationExists
+	}
+
+	inv.Id = utils.NewUUID()
+	inv.OrgId = orgId
+	inv.CreatedAt = now
+
+	m.invitations = append(m.invitations, *inv)
+
+	return nil
+}
+
+func (m *MemoryRepository) GetInvitation(ctx context.Context, id string) (*Invitation, error) {
+	orgId, err := tenant.Require(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	i := m.invitationIndex(orgId, id)
+
+	if i < 0 {
+		return nil, ErrInvitationNotFound
+	}
+
+	inv := m.invitations[i]
+
+	return &inv, nil
+}
+
+func (m *MemoryRepository) ListInvitations(ctx context.Context) ([]Invitation, error) {
+	orgId, err := tenant.Require(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var (
+		now         = time.Now()
+		invitations = []Invitation{}
+	)
+
+	for _, inv := range m.invitations {
+		if (inv.OrgId == orgId) && inv.ExpiresAt.After(now) {
+			invitations = append(invitations, inv)
+		}
+	}
+
+	slices.SortStableFunc(invitations, func(a, b Invitation) int { return b.CreatedAt.Compare(a.CreatedAt) })
+
+	return invitations, nil
+}
+
+func (m *MemoryRepository) DeleteInvitation(ctx context.Context, id string) error {
+	orgId, err := tenant.Require(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.invitationIndex(orgId, id)
+
+	if i < 0 {
+		return ErrInvitationNotFound
+	}
+
+	m.invitations = slices.Delete(m.invitations, i, i+1)
+
+	return nil
+}
+
+func (m *MemoryRepository) ListUserInvitations(ctx context.Context, email string, userId string) ([]Invitation, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	invitations := []Invitation{}
+
+	for _, inv := range m.invitations {
+		if (inv.Email == email) || (inv.InvitedBy == userId) {
+			invitations = append(invitations, inv)
+		}
+	}
+
+	slices.SortStableFunc(invitations, func(a, b Invitation) int { return b.CreatedAt.Compare(a.CreatedAt) })
+
+	return invitations, nil
+}
+
+func (m *MemoryRepository) EraseUserInvitations(ctx context.Context, email string, userId string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.invitations = slices.DeleteFunc(m.invitations, func(inv Invitation) bool { return inv.Email == email })
+
+	for i := range m.invitations {
+		if m.invitations[i].InvitedBy == userId {
+			m.invitations[i].InvitedBy = ""
+		}
+	}
+
+	return nil
+}
+
+// findOrg returns a copy of the first org matching fn.
+func (m *MemoryRepository) findOrg(fn func(Org) bool) (*Org, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	i := slices.IndexFunc(m.orgs, fn)
+
+	if i < 0 {
+		return nil, ErrOrgNotFound
+	}
+
+	o := m.orgs[i]
+
+	return &o, nil
+}
+
+// slugTaken reports whether another org already has o's slug. callers must hold m.mu.
+func (m *MemoryRepository) slugTaken(o *Org) bool {
+	return slices.ContainsFunc(m.orgs, func(other Org) bool { return (other.Id != o.Id) && (other.Slug == o.Slug) })
+}
+
+// memberIndex returns the position of the membership of userId in orgId, or -1. callers must hold m.mu.
+func (m *MemoryRepository) memberIndex(orgId string, userId string) int {
+	return slices.IndexFunc(m.members, func(mb Membership) bool { return (mb.OrgId == orgId) && (mb.UserId == userId) })
+}
+
+// invitationIndex returns the position of the unexpired invitation with the given id to orgId, or -1. callers must hold m.mu.
+func (m *MemoryRepository) invitationIndex(orgId string, id string) int {
+	now := time.Now()
+
+	return slices.IndexFunc(m.invitations, func(inv Invitation) bool {
+		return (inv.OrgId == orgId) && (inv.Id == id) && inv.ExpiresAt.After(now)
+	})
+}
+
+// filterMembers returns the memberships matching fn, the earliest first. callers must hold m.mu.
+func (m *MemoryRepository) filterMembers(fn func(Membership) bool) []Membership {
+	members := []Membership{}
+
+	for _, mb := range m.members {
+		if fn(mb) {
+			members = append(members, mb)
+		}
+	}
+
+	slices.SortStableFunc(members, func(a, b Membership) int { return a.CreatedAt.Compare(b.CreatedAt) })
+
+	return members
+}
@@ -0,0 +1,495 @@
+package org
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/huboh/go-rest-api/internal/pkg/database"
+	"github.com/huboh/go-rest-api/internal/pkg/tenant"
+	"github.com/huboh/go-rest-api/internal/pkg/utils"
+)
+
+const (
+	// orgColumns are the columns selected by every org query, in scanOrg order.
+	orgColumns = "id, name, slug, created_at, updated_at"
+
+	// memberColumns are the columns selected by every membership query, in scanMember order.
+	memberColumns = "org_id, user_id, role, created_at, updated_at"
+
+	// invitationColumns are the columns selected by every invitation query, in scanInvitation order.
+	invitationColumns = "id, org_id, email, role, invited_by, created_at, expires_at"
+)
+
+// SQLRepository is an OrgRepository, MemberRepository and InvitationRepository backed by a SQL database.
+type SQLRepository struct {
+	db *database.DB
+}
+
+// NewSQLRepository creates a SQLRepository using db.
+func NewSQLRepository(db *database.DB) *SQLRepository {
+	return &SQLRepository{db: db}
+}
+
+func (s *SQLRepository) Create(ctx context.Context, o *Org) error {
+	now := time.Now().UTC()
+	id := utils.NewUUID()
+
+	_, err := s.db.Executor(ctx).ExecContext(
+		ctx,
+		s.db.Rebind("INSERT INTO orgs (id, name, slug, created_at, updated_at) VALUES (?, ?, ?, ?, ?)"),
+		id, o.Name, o.Slug, now, now,
+	)
+
+	if database.IsUniqueViolation(err) {
+		return ErrOrgExists
+	}
+
+	if err != nil {
+		return err
+	}
+
+	o.Id = id
+	o.CreatedAt = now
+	o.UpdatedAt = now
+
+	return nil
+}
+
+func (s *SQLRepository) GetById(ctx context.Context, id string) (*Org, error) {
+	return s.getOrg(ctx, "id", id)
+}
+
+func (s *SQLRepository) GetBySlug(ctx context.Context, slug string) (*Org, error) {
+	return s.getOrg(ctx, "slug", slug)
+}
+
+func (s *SQLRepository) Update(ctx context.Context, o *Org) error {
+	now := time.Now().UTC()
+
+	res, err := s.db.Executor(ctx).ExecContext(
+		ctx,
+		s.db.Rebind("UPDATE orgs SET name = ?, slug = ?, updated_at = ? WHERE id = ?"),
+		o.Name, o.Slug, now, o.Id,
+	)
+
+	if database.IsUniqueViolation(err) {
+		return ErrOrgExists
+	}
+
+	if err := checkAffected(res, err, ErrOrgNotFound); err != nil {
+		return err
+	}
+
+	o.UpdatedAt = now
+
+	return nil
+}
+
+func (s *SQLRepository) Delete(ctx context.Context, id string) error {
+	return s.db.WithinTx(ctx, func(ctx context.Context) error {
+		for _, table := range []string{"memberships", "invitations"} {
+			if _, err := s.db.Executor(ctx).ExecContext(ctx, s.db.Rebind("DELETE FROM "+table+" WHERE org_id = ?"), id); err != nil {
+				return err
+			}
+		}
+
+		res, err := s.db.Executor(ctx).ExecContext(ctx, s.db.Rebind("DELETE FROM orgs WHERE id = ?"), id)
+
+		return checkAffected(res, err, ErrOrgNotFound)
+	})
+}
+
+func (s *SQLRepository) ListByUser(ctx context.Context, userId string) ([]Org, error) {
+	rows, err := s.db.Executor(ctx).QueryContext(
+		ctx,
+		s.db.Rebind("SELECT "+orgColumns+" FROM orgs WHERE id IN (SELECT org_id FROM memberships WHERE user_id = ?) ORDER BY name, id"),
+		userId,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	orgs := []Org{}
+
+	for rows.Next() {
+		o, err := scanOrg(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		orgs = append(orgs, *o)
+	}
+
+	return orgs, rows.Err()
+}
+
+func (s *SQLRepository) AddMember(ctx context.Context, m *Membership) error {
+	orgId, err := tenant.Require(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+
+	_, err = s.db.Executor(ctx).ExecContext(
+		ctx,
+		s.db.Rebind("INSERT INTO memberships (org_id, user_id, role, created_at, updated_at) VALUES (?, ?, ?, ?, ?)"),
+		orgId, m.UserId, m.Role, now, now,
+	)
+
+	if database.IsUniqueViolation(err) {
+		return ErrMemberExists
+	}
+
+	if err != nil {
+		return err
+	}
+
+	m.OrgId = orgId
+	m.CreatedAt = now
+	m.UpdatedAt = now
+
+	return nil
+}
+
+func (s *SQLRepository) GetMember(ctx context.Context, userId string) (*Membership, error) {
+	orgId, err := tenant.Require(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := scanMember(
+		s.db.Executor(ctx).QueryRowContext(ctx, s.db.Rebind("SELECT "+memberColumns+" FROM memberships WHERE org_id = ? AND user_id = ?"), orgId, userId),
+	)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrMemberNotFound
+	}
+
+	return m, err
+}
+
+func (s *SQLRepository) UpdateMember(ctx context.Context, m *Membership) error {
+	orgId, err := tenant.Require(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+
+	res, err := s.db.Executor(ctx).ExecContext(
+		ctx,
+		s.db.Rebind("UPDATE memberships SET role = ?, updated_at = ? WHERE org_id = ? AND user_id = ?"),
+		m.Role, now, orgId, m.UserId,
+	)
+
+	if err := checkAffected(res, err, ErrMemberNotFound); err != nil {
+		return err
+	}
+
+	m.OrgId = orgId
+	m.UpdatedAt = now
+
+	return nil
+}
+
+func (s *SQLRepository) RemoveMember(ctx context.Context, userId string) error {
+	orgId, err := tenant.Require(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	res, err := s.db.Executor(ctx).ExecContext(ctx, s.db.Rebind("DELETE FROM memberships WHERE org_id = ? AND user_id = ?"), orgId, userId)
+
+	return checkAffected(res, err, ErrMemberNotFound)
+}
+
+func (s *SQLRepository) ListMembers(ctx context.Context) ([]Membership, error) {
+	orgId, err := tenant.Require(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.listMembers(ctx, "org_id", orgId)
+}
+
+func (s *SQLRepository) CountRole(ctx context.Context, r Role) (int, error) {
+	orgId, err := tenant.Require(ctx)
+
+	if err != nil {
+		return 0, err
+	}
+
+	var n int
+
+	err = s.db.Executor(ctx).QueryRowContext(
+		ctx,
+		s.db.Rebind("SELECT COUNT(*) FROM memberships WHERE org_id = ? AND role = ?"),
+		orgId, r,
+	).Scan(&n)
+
+	return n, err
+}
+
+func (s *SQLRepository) ListMemberships(ctx context.Context, userId string) ([]Membership, error) {
+	return s.listMembers(ctx, "user_id", userId)
+}
+
+func (s *SQLRepository) CreateInvitation(ctx context.Context, inv *Invitation) error {
+	orgId, err := tenant.Require(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	var (
+		now = time.Now().UTC()
+		id  = utils.NewUUID()
+	)
+
+	err = s.db.WithinTx(ctx, func(ctx context.Context) error {
+		// expired invitations are cleaned up lazily, as new ones are created
+		if _, err := s.db.Executor(ctx).ExecContext(ctx, s.db.Rebind("DELETE FROM invitations WHERE expires_at <= ?"), now); err != nil {
+			return err
+		}
+
+		_, err := s.db.Executor(ctx).ExecContext(
+			ctx,
+			s.db.Rebind("INSERT INTO invitations (id, org_id, email, role, invited_by, created_at, expires_at) VALUES (?, ?, ?, ?, ?, ?, ?)"),
+			id, orgId, inv.Email, inv.Role, inv.InvitedBy, now, inv.ExpiresAt.UTC(),
+		)
+
+		return err
+	})
+
+	if database.IsUniqueViolation(err) {
+		return ErrInvitationExists
+	}
+
+	if err != nil {
+		return err
+	}
+
+	inv.Id = id
+	inv.OrgId = orgId
+	inv.CreatedAt = now
+
+	return nil
+}
+
+func (s *SQLRepository) GetInvitation(ctx context.Context, id string) (*Invitation, error) {
+	orgId, err := tenant.Require(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	inv, err := scanInvitation(
+		s.db.Executor(ctx).QueryRowContext(
+			ctx,
+			s.db.Rebind("SELECT "+invitationColumns+" FROM invitations WHERE org_id = ? AND id = ? AND expires_at > ?"),
+			orgId, id, time.Now().UTC(),
+		),
+	)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvitationNotFound
+	}
+
+	return inv, err
+}
+
+func (s *SQLRepository) ListInvitations(ctx context.Context) ([]Invitation, error) {
+	orgId, err := tenant.Require(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Executor(ctx).QueryContext(
+		ctx,
+		s.db.Rebind("SELECT "+invitationColumns+" FROM invitations WHERE org_id = ? AND expires_at > ? ORDER BY created_at DESC, id"),
+		orgId, time.Now().UTC(),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	invitations := []Invitation{}
+
+	for rows.Next() {
+		inv, err := scanInvitation(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		invitations = append(invitations, *inv)
+	}
+
+	return invitations, rows.Err()
+}
+
+func (s *SQLRepository) DeleteInvitation(ctx context.Context, id string) error {
+	orgId, err := tenant.Require(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	res, err := s.db.Executor(ctx).ExecContext(
+		ctx,
+		s.db.Rebind("DELETE FROM invitations WHERE org_id = ? AND id = ? AND expires_at > ?"),
+		orgId, id, time.Now().UTC(),
+	)
+
+	return checkAffected(res, err, ErrInvitationNotFound)
+}
+
+func (s *SQLRepository) ListUserInvitations(ctx context.Context, email string, userId string) ([]Invitation, error) {
+	rows, err := s.db.Executor(ctx).QueryContext(
+		ctx,
+		s.db.Rebind("SELECT "+invitationColumns+" FROM invitations WHERE email = ? OR invited_by = ? ORDER BY created_at DESC, id"),
+		email, userId,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	invitations := []Invitation{}
+
+	for rows.Next() {
+		inv, err := scanInvitation(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		invitations = append(invitations, *inv)
+	}
+
+	return invitations, rows.Err()
+}
+
+func (s *SQLRepository) EraseUserInvitations(ctx context.Context, email string, userId string) error {
+	return s.db.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.db.Executor(ctx).ExecContext(ctx, s.db.Rebind("DELETE FROM invitations WHERE email = ?"), email); err != nil {
+			return err
+		}
+
+		_, err := s.db.Executor(ctx).ExecContext(ctx, s.db.Rebind("UPDATE invitations SET invited_by = '' WHERE invited_by = ?"), userId)
+
+		return err
+	})
+}
+
+// getOrg returns the org whose column equals val. column must be a trusted identifier.
+func (s *SQLRepository) getOrg(ctx context.Context, column string, val string) (*Org, error) {
+	o, err := scanOrg(
+		s.db.Executor(ctx).QueryRowContext(ctx, s.db.Rebind("SELECT "+orgColumns+" FROM orgs WHERE "+column+" = ?"), val),
+	)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrOrgNotFound
+	}
+
+	return o, err
+}
+
+// listMembers returns the memberships whose column equals val, the earliest first. column must be a trusted identifier.
+func (s *SQLRepository) listMembers(ctx context.Context, column string, val string) ([]Membership, error) {
+	rows, err := s.db.Executor(ctx).QueryContext(
+		ctx,
+		s.db.Rebind("SELECT "+memberColumns+" FROM memberships WHERE "+column+" = ? ORDER BY created_at, user_id"),
+		val,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	members := []Membership{}
+
+	for rows.Next() {
+		m, err := scanMember(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		members = append(members, *m)
+	}
+
+	return members, rows.Err()
+}
+
+// scanOrg scans a row selected with orgColumns into an Org.
+func scanOrg(row interface{ Scan(...any) error }) (*Org, error) {
+	o := &Org{}
+
+	if err := row.Scan(&o.Id, &o.Name, &o.Slug, &o.CreatedAt, &o.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+// scanMember scans a row selected with memberColumns into a Membership.
+func scanMember(row interface{ Scan(...any) error }) (*Membership, error) {
+	m := &Membership{}
+
+	if err := row.Scan(&m.OrgId, &m.UserId, &m.Role, &m.CreatedAt, &m.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// scanInvitation scans a row selected with invitationColumns into an Invitation.
+func scanInvitation(row interface{ Scan(...any) error }) (*Invitation, error) {
+	inv := &Invitation{}
+
+	if err := row.Scan(&inv.Id, &inv.OrgId, &inv.Email, &inv.Role, &inv.InvitedBy, &inv.CreatedAt, &inv.ExpiresAt); err != nil {
+		return nil, err
+	}
+
+	return inv, nil
+}
+
+// checkAffected returns the error of an exec, or notFound when it affected no rows.
+func checkAffected(res sql.Result, err error, notFound error) error {
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return notFound
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package org
+
+// createOrgRequest holds the fields of a new org.
+type createOrgRequest struct {
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+// updateOrgRequest holds the org fields to update. nil fields are left unchanged.
+type updateOrgRequest struct {
+	Name *string `json:"name"`
+	Slug *string `json:"slug"`
+}
+
+// addMemberRequest holds the user to add to the active tenant and its role, RoleMember when empty.
+type addMemberRequest struct {
+	UserId string `json:"userId"`
+	Role   Role   `json:"role"`
+}
+
+// updateMemberRequest holds the new role of a member.
+type updateMemberRequest struct {
+	Role Role `json:"role"`
+}
+
+// inviteMemberRequest holds the email to invite to the active tenant and its role, RoleMember when empty.
+type inviteMemberRequest struct {
+	Email string `json:"email"`
+	Role  Role   `json:"role"`
+}
+
+// previewInvitationRequest holds the token of the invitation to preview. the token is sent in the body
+// rather than the path, which ends up in access logs.
+type previewInvitationRequest struct {
+	Token string `json:"token"`
+}
+
+// acceptInvitationRequest holds the token of the invitation accepted by the authenticated user.
+type acceptInvitationRequest struct {
+	Token string `json:"token"`
+}
+
+// invitationSignupRequest holds the token of an invitation and the account to create to accept it.
+// the email of the account is the one the invitation was sent to.
+type invitationSignupRequest struct {
+	Token    string `json:"token"`
+	Name     string `json:"name"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
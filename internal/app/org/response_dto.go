@@ -0,0 +1,38 @@
+package org
+
+import (
+	"time"
+
+	"github.com/huboh/go-rest-api/internal/app/user"
+)
+
+// orgResponse is an org along with the role of the authenticated user within it.
+type orgResponse struct {
+	Org
+	Role Role `json:"role"`
+}
+
+// tokensResponse holds the tenant scoped tokens issued by the Authenticator.
+type tokensResponse struct {
+	Tokens any `json:"tokens"`
+}
+
+// invitationPreview describes an invitation to its invitee.
+type invitationPreview struct {
+	Org       Org       `json:"org"`
+	Email     string    `json:"email"`
+	Role      Role      `json:"role"`
+	ExpiresAt time.Time `json:"expiresAt"`
+
+	// AccountExists reports whether the invitee already has an account to log in with.
+	AccountExists bool `json:"accountExists"`
+}
+
+// acceptInvitationResponse holds the membership created by accepting an invitation, and tokens scoped to its org.
+type acceptInvitationResponse struct {
+	// User is the account created to accept the invitation, if any.
+	User *user.User `json:"user,omitempty"`
+
+	Membership Membership `json:"membership"`
+	Tokens     any        `json:"tokens"`
+}
@@ -0,0 +1,208 @@
+package org
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/huboh/go-rest-api/internal/pkg/middleware"
+	"github.com/huboh/go-rest-api/internal/pkg/router"
+)
+
+// NewRouter creates the router serving the routes managing the orgs of the authenticated user, wrapped by mws.
+// mws must authenticate requests.
+func NewRouter(s *Service, mws []middleware.Middleware) *router.Router {
+	return router.New(
+		// mount path
+		RouterPath,
+
+		// middlewares
+		mws,
+
+		// routes
+		[]router.Route{
+			{
+				Path:     "",
+				Method:   http.MethodPost,
+				Handler:  http.HandlerFunc(s.handleCreateOrg),
+				Summary:  "Create an org owned by the authenticated user",
+				Request:  createOrgRequest{},
+				Response: orgResponse{},
+			},
+			{
+				Path:     "",
+				Method:   http.MethodGet,
+				Handler:  http.HandlerFunc(s.handleListOrgs),
+				Summary:  "List the orgs of the authenticated user",
+				Response: []orgResponse{},
+			},
+			{
+				Path:     "/{id}/tokens",
+				Method:   http.MethodPost,
+				Handler:  http.HandlerFunc(s.handleIssueTokens),
+				Summary:  "Issue tokens scoped to an org, by id or slug",
+				Response: tokensResponse{},
+			},
+			{
+				Path:     "/invitations/accept",
+				Method:   http.MethodPost,
+				Handler:  http.HandlerFunc(s.handleAcceptInvitation),
+				Summary:  "Accept an invitation as the authenticated user",
+				Request:  acceptInvitationRequest{},
+				Response: acceptInvitationResponse{},
+			},
+		},
+	)
+}
+
+// NewTenantRouter creates the router serving the routes acting on the active tenant, wrapped by mws
+// then TenantMiddleware. mws must authenticate requests.
+func NewTenantRouter(s *Service, mws []middleware.Middleware) *router.Router {
+	return router.New(
+		// mount path
+		TenantRouterPath,
+
+		// middlewares
+		append(slices.Clone(mws), s.TenantMiddleware),
+
+		// routes
+		[]router.Route{
+			{
+				Path:     "",
+				Method:   http.MethodGet,
+				Handler:  http.HandlerFunc(s.handleGetOrg),
+				Summary:  "Get the active org",
+				Response: orgResponse{},
+			},
+			{
+				Path:        "",
+				Method:      http.MethodPatch,
+				Handler:     http.HandlerFunc(s.handleUpdateOrg),
+				Middlewares: []middleware.Middleware{s.RoleGuard(RoleAdmin)},
+				Auth:        []string{"org admin"},
+				Summary:     "Update the active org",
+				Request:     updateOrgRequest{},
+				Response:    Org{},
+			},
+			{
+				Path:        "",
+				Method:      http.MethodDelete,
+				Handler:     http.HandlerFunc(s.handleDeleteOrg),
+				Middlewares: []middleware.Middleware{s.RoleGuard(RoleOwner)},
+				Auth:        []string{"org owner"},
+				Summary:     "Delete the active org",
+			},
+			{
+				Path:     "/members",
+				Method:   http.MethodGet,
+				Handler:  http.HandlerFunc(s.handleListMembers),
+				Summary:  "List the members of the active org",
+				Response: []Membership{},
+			},
+			{
+				Path:        "/members",
+				Method:      http.MethodPost,
+				Handler:     http.HandlerFunc(s.handleAddMember),
+				Middlewares: []middleware.Middleware{s.RoleGuard(RoleAdmin)},
+				Auth:        []string{"org admin"},
+				Summary:     "Add a member to the active org",
+				Request:     addMemberRequest{},
+				Response:    Membership{},
+			},
+			{
+				Path:        "/members/{userId:uuid}",
+				Method:      http.MethodPatch,
+				Handler:     http.HandlerFunc(s.handleUpdateMember),
+				Middlewares: []middleware.Middleware{s.RoleGuard(RoleAdmin)},
+				Auth:        []string{"org admin"},
+				Summary:     "Change the role of a member of the active org",
+				Request:     updateMemberRequest{},
+				Response:    Membership{},
+			},
+			{
+				// members may remove themselves, removeMember checks the others
+				Path:    "/members/{userId:uuid}",
+				Method:  http.MethodDelete,
+				Handler: http.HandlerFunc(s.handleRemoveMember),
+				Summary: "Remove a member from the active org, or leave it",
+			},
+			{
+				Path:        "/invitations",
+				Method:      http.MethodGet,
+				Handler:     http.HandlerFunc(s.handleListInvitations),
+				Middlewares: []middleware.Middleware{s.RoleGuard(RoleAdmin)},
+				Auth:        []string{"org admin"},
+				Summary:     "List the pending invitations of the active org",
+				Response:    []Invitation{},
+			},
+			{
+				Path:        "/invitations",
+				Method:      http.MethodPost,
+				Handler:     http.HandlerFunc(s.handleInviteMember),
+				Middlewares: []middleware.Middleware{s.RoleGuard(RoleAdmin)},
+				Auth:        []string{"org admin"},
+				Summary:     "Invite someone to the active org by email",
+				Request:     inviteMemberRequest{},
+				Response:    Invitation{},
+			},
+			{
+				Path:        "/invitations/{id:uuid}",
+				Method:      http.MethodDelete,
+				Handler:     http.HandlerFunc(s.handleRevokeInvitation),
+				Middlewares: []middleware.Middleware{s.RoleGuard(RoleAdmin)},
+				Auth:        []string{"org admin"},
+				Summary:     "Revoke an invitation to the active org",
+			},
+		},
+	)
+}
+
+// NewInvitationRouter creates the router serving the public routes of invitations, used by invitees
+// without an account or before they log in.
+func NewInvitationRouter(s *Service) *router.Router {
+	return router.New(
+		// mount path
+		InvitationRouterPath,
+
+		// middlewares
+		[]middleware.Middleware{},
+
+		// routes
+		[]router.Route{
+			{
+				Path:     "/preview",
+				Method:   http.MethodPost,
+				Handler:  http.HandlerFunc(s.handlePreviewInvitation),
+				Summary:  "Preview an invitation",
+				Request:  previewInvitationRequest{},
+				Response: invitationPreview{},
+			},
+			{
+				Path:     "/signup",
+				Method:   http.MethodPost,
+				Handler:  http.HandlerFunc(s.handleInvitationSignup),
+				Summary:  "Create an account to accept an invitation",
+				Request:  invitationSignupRequest{},
+				Response: acceptInvitationResponse{},
+			},
+		},
+	)
+}
+
+// Module adds the org routes to the router of the app, see router.Module.
+type Module struct {
+	service *Service
+	mws     []middleware.Middleware
+}
+
+// NewModule creates the module mounting the routers of s, those of authenticated routes being wrapped
+// by mws. mws must authenticate requests.
+func NewModule(s *Service, mws []middleware.Middleware) *Module {
+	return &Module{service: s, mws: mws}
+}
+
+// RegisterRoutes makes Module implement router.Module.
+func (m *Module) RegisterRoutes(r *router.Router) {
+	r.Mount(RouterPath, NewRouter(m.service, m.mws), router.Tagged("orgs"), router.Secured("bearer"), router.Guarded("authenticated"))
+	r.Mount(TenantRouterPath, NewTenantRouter(m.service, m.mws), router.Tagged("orgs"), router.Secured("bearer"), router.Guarded("authenticated", "org member"))
+	r.Mount(InvitationRouterPath, NewInvitationRouter(m.service), router.Tagged("orgs"), router.Public())
+}
@@ -0,0 +1,50 @@
+package org
+
+import (
+	"context"
+
+	"github.com/huboh/go-rest-api/internal/app/user"
+	"github.com/huboh/go-rest-api/internal/pkg/database"
+	"github.com/huboh/go-rest-api/internal/pkg/mail"
+	"github.com/huboh/go-rest-api/internal/pkg/tenant"
+)
+
+// Authenticator creates accounts and issues their tokens.
+type Authenticator interface {
+	// IssueTenantTokens issues tokens of the user with the given id scoped to the tenant with id tenantId.
+	// the result is written as is in responses.
+	IssueTenantTokens(ctx context.Context, userId string, tenantId string) (any, error)
+
+	// CreateAccount creates a user with the given password.
+	CreateAccount(ctx context.Context, name string, email string, username string, password string) (*user.User, error)
+}
+
+// Service holds the dependencies used by the org handlers and middlewares.
+type Service struct {
+	orgs        OrgRepository
+	members     MemberRepository
+	invitations InvitationRepository
+	users       user.UserRepository
+	auth        Authenticator
+	mailer      mail.Sender
+	resolver    *tenant.Resolver
+	inviteCfgs  *invitationConfigs
+	tx          database.TxManager
+}
+
+// NewService creates a new org Service that reads and writes orgs from orgs, their members from members and
+// their invitations from invitations, looks up users in users, creates accounts and tenant scoped tokens with
+// auth, emails invitations with mailer and runs multi-record changes within tx.
+func NewService(orgs OrgRepository, members MemberRepository, invitations InvitationRepository, users user.UserRepository, auth Authenticator, mailer mail.Sender, tx database.TxManager) *Service {
+	return &Service{
+		tx:          tx,
+		orgs:        orgs,
+		auth:        auth,
+		users:       users,
+		mailer:      mailer,
+		members:     members,
+		invitations: invitations,
+		resolver:    tenant.NewResolver(),
+		inviteCfgs:  newInvitationConfigs(),
+	}
+}
@@ -0,0 +1,58 @@
+package org
+
+import "time"
+
+// Role is the access level of a member within an Org.
+type Role string
+
+// recognized Role, from the most to the least privileged
+const (
+	RoleOwner  = Role("owner")
+	RoleAdmin  = Role("admin")
+	RoleMember = Role("member")
+)
+
+// Org is an organization. orgs are the tenants of the app.
+type Org struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+
+	// Slug is the unique, url friendly name of the org. it names the org in tenant subdomains.
+	Slug string `json:"slug"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Membership grants a user a Role within an Org.
+type Membership struct {
+	OrgId     string    `json:"orgId"`
+	UserId    string    `json:"userId"`
+	Role      Role      `json:"role"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Valid reports whether r is a recognized Role.
+func (r Role) Valid() bool {
+	return (r == RoleOwner) || (r == RoleAdmin) || (r == RoleMember)
+}
+
+// AtLeast reports whether r is as privileged as o, or more.
+func (r Role) AtLeast(o Role) bool {
+	return r.rank() >= o.rank()
+}
+
+// rank orders roles by privilege. unknown roles rank lowest.
+func (r Role) rank() int {
+	switch r {
+	case RoleOwner:
+		return 3
+	case RoleAdmin:
+		return 2
+	case RoleMember:
+		return 1
+	}
+
+	return 0
+}
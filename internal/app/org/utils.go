@@ -0,0 +1,42 @@
+package org
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/huboh/go-rest-api/internal/app/user"
+	"github.com/huboh/go-rest-api/internal/pkg/tenant"
+)
+
+type orgKey string
+
+const member = orgKey("member")
+
+// contextWithMember returns a copy of c holding m, the membership of the authenticated user in the active tenant.
+func contextWithMember(c context.Context, m Membership) context.Context {
+	return context.WithValue(c, member, m)
+}
+
+// MemberFromContext returns the membership of the authenticated user in the active tenant,
+// stored by TenantMiddleware.
+func MemberFromContext(ctx context.Context) (Membership, bool) {
+	m, ok := ctx.Value(member).(Membership)
+	return m, ok
+}
+
+// errStatusCode returns the http status code that best describes err.
+func errStatusCode(err error) int {
+	switch {
+	case errors.Is(err, ErrOrgNotFound), errors.Is(err, ErrMemberNotFound), errors.Is(err, ErrInvitationNotFound), errors.Is(err, user.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrOrgExists), errors.Is(err, ErrMemberExists), errors.Is(err, ErrInvitationExists), errors.Is(err, ErrLastOwner), errors.Is(err, user.ErrUserExists):
+		return http.StatusConflict
+	case errors.Is(err, ErrForbidden), errors.Is(err, ErrNotMember), errors.Is(err, ErrInvitationEmail), errors.Is(err, tenant.ErrTenantMismatch):
+		return http.StatusForbidden
+	case errors.Is(err, ErrInvalidOrg), errors.Is(err, ErrInvalidInvitation), errors.Is(err, ErrIncompleteSignup), errors.Is(err, tenant.ErrNoTenant):
+		return http.StatusBadRequest
+	}
+
+	return http.StatusInternalServerError
+}
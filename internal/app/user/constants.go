@@ -1,3 +1,9 @@
 package user
 
 const RouterPath = "/users"
+
+// names of the user routes, see router.URL
+const (
+	GetExportRoute  = "user.getExport"
+	GetErasureRoute = "user.getErasure"
+)
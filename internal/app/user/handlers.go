@@ -1,13 +1,280 @@
 package user
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 
 	"github.com/huboh/go-rest-api/internal/pkg/json"
+	"github.com/huboh/go-rest-api/internal/pkg/query"
+	"github.com/huboh/go-rest-api/internal/pkg/router"
 )
 
-func handleGetHello(w http.ResponseWriter, r *http.Request) {
+func (s *Service) handleGetHello(w http.ResponseWriter, r *http.Request) {
 	json.Write(w, json.Response{
 		Data: "hello",
 	})
 }
+
+func (s *Service) handleGetMe(w http.ResponseWriter, r *http.Request) {
+	id, _ := UserFromContext(r.Context())
+	s.writeUser(w, r, id)
+}
+
+func (s *Service) handleUpdateMe(ctx context.Context, req updateUserRequest) (*User, error) {
+	id, _ := UserFromContext(ctx)
+	return s.updateUser(ctx, id, req, false)
+}
+
+func (s *Service) handleDeleteMe(w http.ResponseWriter, r *http.Request) {
+	id, _ := UserFromContext(r.Context())
+	s.writeDeletedUser(w, r, id)
+}
+
+func (s *Service) handleListUsers(w http.ResponseWriter, r *http.Request) {
+	p, err := s.listQuery.Parse(r)
+
+	if err != nil {
+		json.Write(w, json.Response{
+			StatusCode: http.StatusBadRequest,
+			Error:      json.ErrorFromErr(err, "", ""),
+		})
+		return
+	}
+
+	users, hasMore, total, err := s.listUsers(getListContext(r), p)
+
+	if err != nil {
+		json.Write(w, json.Response{
+			StatusCode: errStatusCode(err),
+			Error:      json.ErrorFromErr(err, "", ""),
+		})
+		return
+	}
+
+	meta, links := query.NewPage(r, p, users, hasMore, userValue)
+	meta.Total = total
+
+	json.Write(w, json.Response{
+		Data:  users,
+		Meta:  meta,
+		Links: links,
+	})
+}
+
+func (s *Service) handleSearchUsers(w http.ResponseWriter, r *http.Request) {
+	req, err := getSearchRequest(r)
+
+	if err != nil {
+		json.Write(w, json.Response{
+			StatusCode: http.StatusBadRequest,
+			Error:      json.ErrorFromErr(err, "", ""),
+		})
+		return
+	}
+
+	hits, total, err := s.searchUsers(r.Context(), req)
+
+	if err != nil {
+		json.Write(w, json.Response{
+			StatusCode: errStatusCode(err),
+			Error:      json.ErrorFromErr(err, "", ""),
+		})
+		return
+	}
+
+	meta, links := query.NewOffsetPage(r, req.Limit, req.Offset, total)
+
+	json.Write(w, json.Response{
+		Data:  hits,
+		Meta:  meta,
+		Links: links,
+	})
+}
+
+func (s *Service) handleGetUser(w http.ResponseWriter, r *http.Request) {
+	s.writeUser(w, r.WithContext(getListContext(r)), r.PathValue("id"))
+}
+
+func (s *Service) handleUpdateUser(ctx context.Context, req updateUserRequest) (*User, error) {
+	return s.updateUser(ctx, req.Id, req, true)
+}
+
+func (s *Service) handleDeleteUser(w http.ResponseWriter, r *http.Request) {
+	s.writeDeletedUser(w, r, r.PathValue("id"))
+}
+
+func (s *Service) handleRestoreUser(w http.ResponseWriter, r *http.Request) {
+	u, err := s.restoreUser(r.Context(), r.PathValue("id"))
+
+	if err != nil {
+		json.Write(w, json.Response{
+			StatusCode: errStatusCode(err),
+			Error:      json.ErrorFromErr(err, "", ""),
+		})
+		return
+	}
+
+	json.Write(w, json.Response{
+		Data: u,
+	})
+}
+
+func (s *Service) handleRequestExport(w http.ResponseWriter, r *http.Request) {
+	id, _ := UserFromContext(r.Context())
+	e, err := s.privacy.RequestExport(r.Context(), id)
+
+	if err != nil {
+		json.Write(w, json.Response{
+			StatusCode: errStatusCode(err),
+			Error:      json.ErrorFromErr(err, "", ""),
+		})
+		return
+	}
+
+	// the export is prepared in the background, its status is polled at its location
+	if loc, err := router.URL(r, GetExportRoute, "id", e.Id); err == nil {
+		w.Header().Set("Location", loc)
+	}
+
+	json.Write(w, json.Response{
+		StatusCode: http.StatusAccepted,
+		Message:    "export requested",
+		Data:       e,
+	})
+}
+
+func (s *Service) handleGetExport(w http.ResponseWriter, r *http.Request) {
+	id, _ := UserFromContext(r.Context())
+	e, err := s.privacy.GetExport(r.Context(), id, r.PathValue("id"))
+
+	if err != nil {
+		json.Write(w, json.Response{
+			StatusCode: errStatusCode(err),
+			Error:      json.ErrorFromErr(err, "", ""),
+		})
+		return
+	}
+
+	json.Write(w, json.Response{
+		Data: e,
+	})
+}
+
+// handleDownloadExport responds with the archive of a ready export as a json file.
+func (s *Service) handleDownloadExport(w http.ResponseWriter, r *http.Request) {
+	id, _ := UserFromContext(r.Context())
+	e, err := s.privacy.DownloadExport(r.Context(), id, r.PathValue("id"))
+
+	if err != nil {
+		json.Write(w, json.Response{
+			StatusCode: errStatusCode(err),
+			Error:      json.ErrorFromErr(err, "", ""),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"export-%s.json\"", e.Id))
+	w.Write(e.Archive)
+}
+
+func (s *Service) handleRequestErasure(w http.ResponseWriter, r *http.Request) {
+	var (
+		req   erasureRequest
+		id, _ = UserFromContext(r.Context())
+	)
+
+	if err := json.UnmarshalBody(r, &req); err != nil {
+		json.Write(w, json.Response{
+			StatusCode: http.StatusBadRequest,
+			Error:      json.ErrorFromErr(err, "", ""),
+		})
+		return
+	}
+
+	e, err := s.requestErasure(r.Context(), id, req)
+
+	if err != nil {
+		json.Write(w, json.Response{
+			StatusCode: errStatusCode(err),
+			Error:      json.ErrorFromErr(err, "", ""),
+		})
+		return
+	}
+
+	if loc, err := router.URL(r, GetErasureRoute); err == nil {
+		w.Header().Set("Location", loc)
+	}
+
+	json.Write(w, json.Response{
+		StatusCode: http.StatusAccepted,
+		Message:    "erasure scheduled",
+		Data:       e,
+	})
+}
+
+func (s *Service) handleGetErasure(w http.ResponseWriter, r *http.Request) {
+	id, _ := UserFromContext(r.Context())
+	e, err := s.privacy.GetErasure(r.Context(), id)
+
+	if err != nil {
+		json.Write(w, json.Response{
+			StatusCode: errStatusCode(err),
+			Error:      json.ErrorFromErr(err, "", ""),
+		})
+		return
+	}
+
+	json.Write(w, json.Response{
+		Data: e,
+	})
+}
+
+func (s *Service) handleCancelErasure(w http.ResponseWriter, r *http.Request) {
+	id, _ := UserFromContext(r.Context())
+
+	if err := s.privacy.CancelErasure(r.Context(), id); err != nil {
+		json.Write(w, json.Response{
+			StatusCode: errStatusCode(err),
+			Error:      json.ErrorFromErr(err, "", ""),
+		})
+		return
+	}
+
+	json.Write(w, json.Response{
+		Message: "erasure canceled",
+	})
+}
+
+// writeUser responds with the user with the given id.
+func (s *Service) writeUser(w http.ResponseWriter, r *http.Request, id string) {
+	u, err := s.getUser(r.Context(), id)
+
+	if err != nil {
+		json.Write(w, json.Response{
+			StatusCode: errStatusCode(err),
+			Error:      json.ErrorFromErr(err, "", ""),
+		})
+		return
+	}
+
+	json.Write(w, json.Response{
+		Data: u,
+	})
+}
+
+// writeDeletedUser deletes the user with the given id.
+func (s *Service) writeDeletedUser(w http.ResponseWriter, r *http.Request, id string) {
+	if err := s.deleteUser(r.Context(), id); err != nil {
+		json.Write(w, json.Response{
+			StatusCode: errStatusCode(err),
+			Error:      json.ErrorFromErr(err, "", ""),
+		})
+		return
+	}
+
+	json.Write(w, json.Response{
+		Message: "user deleted",
+	})
+}
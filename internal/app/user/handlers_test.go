@@ -0,0 +1,88 @@
+package user
+
+import (
+	"context"
+	jsonEncoder "encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/huboh/go-rest-api/internal/pkg/database"
+	"github.com/huboh/go-rest-api/internal/pkg/router"
+	"github.com/huboh/go-rest-api/internal/pkg/search"
+)
+
+func TestHandleUpdateMe(t *testing.T) {
+	t.Setenv("QUERY_CURSOR_SECRET", "secret")
+
+	var (
+		ctx     = context.Background()
+		index   = search.NewMemoryIndex(SearchWeights)
+		users   = NewIndexedRepository(NewMemoryRepository(), index)
+		s       = NewService(users, index, nil, database.NoopTxManager{}, nil)
+		handler = router.Handle(s.handleUpdateMe, router.WithErrorStatus(errStatusCode))
+	)
+
+	jane := &User{Name: "Jane", Email: "jane@example.com", Username: "jane", Role: RoleUser}
+
+	if err := users.Create(ctx, jane); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		fields []string
+	}{
+		{"unset fields", `{}`, http.StatusOK, nil},
+		{"valid fields", `{"name": "Jane Doe", "username": "jane.doe"}`, http.StatusOK, nil},
+		{"invalid fields", `{"name": " ", "email": "jane", "username": "j"}`, http.StatusUnprocessableEntity, []string{"name", "email", "username"}},
+		{"unknown role", `{"role": "owner"}`, http.StatusUnprocessableEntity, []string{"role"}},
+		{"own role", `{"role": "admin"}`, http.StatusForbidden, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				w = httptest.NewRecorder()
+				r = httptest.NewRequest(http.MethodPatch, "/me", strings.NewReader(tt.body))
+			)
+
+			r.Header.Set("Content-Type", "application/json")
+			handler.ServeHTTP(w, r.WithContext(ContextWithUser(ctx, jane.Id)))
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+
+			var res struct {
+				Error struct {
+					Fields []struct {
+						Field string `json:"field"`
+					} `json:"fields"`
+				} `json:"error"`
+			}
+
+			if err := jsonEncoder.Unmarshal(w.Body.Bytes(), &res); err != nil {
+				t.Fatal(err)
+			}
+
+			var fields []string
+
+			for _, f := range res.Error.Fields {
+				fields = append(fields, f.Field)
+			}
+
+			if !reflect.DeepEqual(fields, tt.fields) {
+				t.Errorf("invalid fields = %v, want %v", fields, tt.fields)
+			}
+		})
+	}
+
+	if u, err := users.GetById(ctx, jane.Id); (err != nil) || (u.Name != "Jane Doe") || (u.Username != "jane.doe") || (u.Email != "jane@example.com") {
+		t.Errorf("updated user = %+v, %v, want the valid fields applied only", u, err)
+	}
+}
@@ -0,0 +1,184 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/huboh/go-rest-api/internal/pkg/privacy"
+	"github.com/huboh/go-rest-api/internal/pkg/query"
+	"github.com/huboh/go-rest-api/internal/pkg/search"
+	"github.com/huboh/go-rest-api/internal/pkg/validate"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	// ErrForbidden is returned when a user isn't allowed to perform an action
+	ErrForbidden = errors.New("you are not allowed to perform this action")
+
+	// ErrWrongPassword is returned when a sensitive action is confirmed with a wrong password
+	ErrWrongPassword = errors.New("wrong password")
+)
+
+const (
+	// defSearchLimit is the number of search hits returned when the limit parameter is unset.
+	defSearchLimit = 20
+
+	// maxSearchLimit is the maximum number of search hits returned.
+	maxSearchLimit = 100
+)
+
+// usernameRegexp matches the usernames users may pick.
+var usernameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_.-]{3,32}$`)
+
+func init() {
+	validate.Register("username", validateUsername)
+	validate.Register("role", validateRole)
+}
+
+// validateUsername is the "username" validation rule, checking that fields are usernames users may pick.
+func validateUsername(v reflect.Value, _ string) error {
+	if (v.Kind() == reflect.String) && usernameRegexp.MatchString(v.String()) {
+		return nil
+	}
+
+	return errors.New("must be 3 to 32 letters, digits, dots, dashes or underscores")
+}
+
+// validateRole is the "role" validation rule, checking that fields are recognized roles.
+func validateRole(v reflect.Value, _ string) error {
+	if (v.Kind() == reflect.String) && Role(v.String()).Valid() {
+		return nil
+	}
+
+	return fmt.Errorf("must be one of %s, %s", RoleUser, RoleAdmin)
+}
+
+func (s *Service) getUser(ctx context.Context, id string) (*User, error) {
+	return s.users.GetById(ctx, id)
+}
+
+// listUsers returns the page of users selected by p and, when requested, the number of users matching its filters.
+func (s *Service) listUsers(ctx context.Context, p *query.Params) (users []User, hasMore bool, total *int, err error) {
+	if users, hasMore, err = s.users.List(ctx, p); err != nil {
+		return nil, false, nil, err
+	}
+
+	if p.Total {
+		n, err := s.users.Count(ctx, p)
+
+		if err != nil {
+			return nil, false, nil, err
+		}
+
+		total = &n
+	}
+
+	return users, hasMore, total, nil
+}
+
+// searchUsers returns the page of users matching req, ranked by relevance, and the total number of matches.
+func (s *Service) searchUsers(ctx context.Context, req searchRequest) ([]searchHit, int, error) {
+	result, err := s.index.Search(ctx, search.Query{Text: req.Query, Limit: req.Limit, Offset: req.Offset})
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	hits := []searchHit{}
+
+	for _, h := range result.Hits {
+		u, err := s.users.GetById(ctx, h.Id)
+
+		// the index may briefly lag behind deletions, the total isn't corrected as the hits
+		// of the other pages would still count them
+		if errors.Is(err, ErrUserNotFound) {
+			continue
+		}
+
+		if err != nil {
+			return nil, 0, err
+		}
+
+		hits = append(hits, searchHit{User: *u, Score: h.Score, Highlights: h.Highlights})
+	}
+
+	return hits, result.Total, nil
+}
+
+// updateUser applies the fields set in req, validated by its tags, to the user with the given id.
+// req.Role is only applied when allowRole is true, it returns ErrForbidden otherwise.
+func (s *Service) updateUser(ctx context.Context, id string, req updateUserRequest, allowRole bool) (*User, error) {
+	if (req.Role != nil) && !allowRole {
+		return nil, ErrForbidden
+	}
+
+	u, err := s.users.GetById(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		u.Name = strings.TrimSpace(*req.Name)
+	}
+
+	if req.Email != nil {
+		u.Email = NormalizeEmail(*req.Email)
+	}
+
+	if req.Username != nil {
+		u.Username = *req.Username
+	}
+
+	if req.Role != nil {
+		u.Role = *req.Role
+	}
+
+	if err := s.users.Update(ctx, u); err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
+
+// deleteUser marks the user with the given id as deleted and revokes its tokens, so it is signed out
+// immediately. the account is kept until it is purged.
+func (s *Service) deleteUser(ctx context.Context, id string) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.users.Delete(ctx, id); err != nil {
+			return err
+		}
+
+		return s.revoker.RevokeTokens(ctx, id)
+	})
+}
+
+// restoreUser unmarks the deleted user with the given id. its revoked tokens stay revoked.
+func (s *Service) restoreUser(ctx context.Context, id string) (*User, error) {
+	if err := s.users.Restore(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.users.GetById(ctx, id)
+}
+
+// requestErasure schedules the erasure of the personal data of the user with the given id, once
+// its identity is confirmed by the password in req.
+func (s *Service) requestErasure(ctx context.Context, id string, req erasureRequest) (privacy.Erasure, error) {
+	u, err := s.users.GetById(ctx, id)
+
+	if err != nil {
+		return privacy.Erasure{}, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(req.Password)); err != nil {
+		return privacy.Erasure{}, ErrWrongPassword
+	}
+
+	return s.privacy.RequestErasure(ctx, id)
+}
@@ -0,0 +1,47 @@
+package user
+
+import (
+	"context"
+	"testing"
+
+	"github.com/huboh/go-rest-api/internal/pkg/database"
+	"github.com/huboh/go-rest-api/internal/pkg/search"
+)
+
+func TestSearchUsersSkipsStaleHits(t *testing.T) {
+	t.Setenv("QUERY_CURSOR_SECRET", "secret")
+
+	var (
+		ctx   = context.Background()
+		index = search.NewMemoryIndex(SearchWeights)
+		users = NewIndexedRepository(NewMemoryRepository(), index)
+		s     = NewService(users, index, nil, database.NoopTxManager{}, nil)
+	)
+
+	for _, u := range []*User{
+		{Name: "Jane", Email: "jane@example.com", Username: "jane"},
+		{Name: "Janet", Email: "janet@example.com", Username: "janet"},
+	} {
+		if err := users.Create(ctx, u); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// a user the index lags behind the deletion of
+	stale := &User{Id: "00000000-0000-0000-0000-000000000000", Name: "Janelle", Email: "janelle@example.com", Username: "janelle"}
+
+	if err := index.Index(ctx, userDocument(stale)); err != nil {
+		t.Fatal(err)
+	}
+
+	hits, total, err := s.searchUsers(ctx, searchRequest{Query: "jane", Limit: 10})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the total is the index's, the stale hit is only left out of the page
+	if (len(hits) != 2) || (total != 3) {
+		t.Errorf("searchUsers() = %d hits, total %d, want 2 hits, total 3", len(hits), total)
+	}
+}
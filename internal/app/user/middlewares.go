@@ -0,0 +1,41 @@
+package user
+
+import (
+	"net/http"
+
+	"github.com/huboh/go-rest-api/internal/pkg/json"
+)
+
+// AdminGuardMiddleware rejects requests from users without the admin role.
+// it must be wrapped by a middleware storing the authenticated user with ContextWithUser.
+func (s *Service) AdminGuardMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			id, ok := UserFromContext(r.Context())
+
+			if !ok {
+				json.Write(w, json.Response{
+					StatusCode: http.StatusUnauthorized,
+					Error:      &json.Error{Name: "Unauthorized"},
+				})
+				return
+			}
+
+			u, err := s.users.GetById(r.Context(), id)
+
+			if (err == nil) && (u.Role != RoleAdmin) {
+				err = ErrForbidden
+			}
+
+			if err != nil {
+				json.Write(w, json.Response{
+					StatusCode: http.StatusForbidden,
+					Error:      json.ErrorFromErr(err, "Forbidden", ""),
+				})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		},
+	)
+}
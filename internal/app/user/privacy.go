@@ -0,0 +1,43 @@
+package user
+
+import (
+	"context"
+	"errors"
+
+	"github.com/huboh/go-rest-api/internal/pkg/privacy"
+)
+
+// privacyProvider exports and erases the accounts of users.
+type privacyProvider struct {
+	s *Service
+}
+
+// PrivacyProvider returns the privacy.Provider of the accounts held by s.
+func (s *Service) PrivacyProvider() privacy.Provider {
+	return privacyProvider{s: s}
+}
+
+func (p privacyProvider) Name() string {
+	return "profile"
+}
+
+func (p privacyProvider) Export(ctx context.Context, userId string) (any, error) {
+	return p.s.users.GetById(WithDeleted(ctx), userId)
+}
+
+// Erase deletes the account of the user, unless it already was, and purges it right away.
+func (p privacyProvider) Erase(ctx context.Context, userId string) error {
+	return p.s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		err := p.s.users.Delete(ctx, userId)
+
+		if (err != nil) && !errors.Is(err, ErrUserNotFound) {
+			return err
+		}
+
+		if err := p.s.users.Purge(ctx, userId); (err != nil) && !errors.Is(err, ErrUserNotFound) {
+			return err
+		}
+
+		return nil
+	})
+}
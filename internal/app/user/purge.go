@@ -0,0 +1,180 @@
+package user
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/huboh/go-rest-api/internal/pkg/env"
+	"github.com/huboh/go-rest-api/internal/pkg/utils"
+)
+
+const (
+	// defDeletionRetention is the default time deleted users are kept before being purged.
+	defDeletionRetention = time.Hour * 24 * 30
+
+	// defPurgeInterval is the default time between purges.
+	defPurgeInterval = time.Hour
+
+	// purgeBatchSize is the number of users purged per query.
+	purgeBatchSize = 100
+
+	// maxPurgeRetryDelay is the maximum time between the attempts of failing purges.
+	maxPurgeRetryDelay = time.Hour * 24
+)
+
+// PurgeConfigs holds the settings of a PurgeJob.
+type PurgeConfigs struct {
+	// Retention is the time deleted users are kept before being purged.
+	Retention time.Duration
+
+	// Interval is the time between purges.
+	Interval time.Duration
+}
+
+// NewPurgeConfigs initializes a new PurgeConfigs instance by reading environment variables,
+// falling back to defaults when they are unset.
+func NewPurgeConfigs() *PurgeConfigs {
+	c := &PurgeConfigs{
+		Retention: defDeletionRetention,
+		Interval:  defPurgeInterval,
+	}
+
+	if v := env.Get("USER_DELETION_RETENTION"); v != "" {
+		c.Retention = utils.Must(time.ParseDuration(v))
+	}
+
+	if v := env.Get("USER_PURGE_INTERVAL"); v != "" {
+		c.Interval = utils.Must(time.ParseDuration(v))
+	}
+
+	return c
+}
+
+// Eraser erases the personal data held on users, see privacy.Service.
+type Eraser interface {
+	Erase(ctx context.Context, userId string) error
+}
+
+// purgeRetry is the backoff of a user whose purge failed.
+type purgeRetry struct {
+	attempts int
+	next     time.Time
+}
+
+// PurgeJob permanently removes the users deleted for longer than the retention period.
+type PurgeJob struct {
+	users   UserRepository
+	eraser  Eraser
+	configs *PurgeConfigs
+
+	mu sync.Mutex
+	// retries holds the backoff of the users whose purge failed, by id. it's kept in memory as the
+	// users remain listed until purged, a restart only retries them sooner.
+	retries map[string]purgeRetry
+}
+
+// NewPurgeJob creates a PurgeJob listing deleted users in users and removing them with eraser, so
+// every package holding data on them cleans it up, e.g. their tokens are revoked and their memberships removed.
+func NewPurgeJob(users UserRepository, eraser Eraser, configs *PurgeConfigs) *PurgeJob {
+	return &PurgeJob{
+		users:   users,
+		eraser:  eraser,
+		configs: configs,
+		retries: map[string]purgeRetry{},
+	}
+}
+
+// Run purges users every interval until ctx is done.
+func (j *PurgeJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.configs.Interval)
+	defer ticker.Stop()
+
+	for {
+		if n, err := j.Purge(ctx); err != nil {
+			log.Printf("purging deleted users failed after %d users: %s\n", n, err)
+		} else if n > 0 {
+			log.Printf("purged %d deleted users\n", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge permanently removes the users deleted for longer than the retention period by erasing their
+// personal data, their account included, and returns the number of removed users. failed purges are
+// logged and retried later, after a delay doubling with each attempt, so they don't hold back the
+// users deleted after them.
+func (j *PurgeJob) Purge(ctx context.Context) (int, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var (
+		n       = 0
+		skipped = 0
+		now     = time.Now()
+		before  = now.Add(-j.configs.Retention)
+		listed  = map[string]bool{}
+	)
+
+	for {
+		// purged users leave the list, the skipped ones remain ahead of the next batch
+		users, err := j.users.ListDeleted(ctx, before, skipped, purgeBatchSize)
+
+		if err != nil {
+			return n, err
+		}
+
+		for _, u := range users {
+			listed[u.Id] = true
+			r := j.retries[u.Id]
+
+			if r.next.After(now) {
+				skipped++
+				continue
+			}
+
+			if err := j.eraser.Erase(ctx, u.Id); err != nil {
+				r.attempts++
+				r.next = now.Add(j.retryDelay(r.attempts))
+				j.retries[u.Id] = r
+
+				log.Printf("purging deleted user %s failed, attempt %d, retrying at %s: %s\n", u.Id, r.attempts, r.next.Format(time.RFC3339), err)
+
+				skipped++
+				continue
+			}
+
+			delete(j.retries, u.Id)
+			n++
+		}
+
+		if len(users) < purgeBatchSize {
+			// forget the users restored or purged elsewhere since their purge failed
+			for id := range j.retries {
+				if !listed[id] {
+					delete(j.retries, id)
+				}
+			}
+
+			return n, nil
+		}
+	}
+}
+
+// retryDelay returns the time to wait before the next attempt of a purge that failed attempts times:
+// the purge interval, doubled with each attempt up to maxPurgeRetryDelay.
+func (j *PurgeJob) retryDelay(attempts int) time.Duration {
+	delay := max(j.configs.Interval, time.Second)
+
+	for i := 1; (i < attempts) && (delay < maxPurgeRetryDelay); i++ {
+		delay *= 2
+	}
+
+	return min(delay, maxPurgeRetryDelay)
+}
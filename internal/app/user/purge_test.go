@@ -0,0 +1,131 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// testEraser is an Eraser purging the accounts of users from users, and recording their ids.
+// it fails to erase the users in failing.
+type testEraser struct {
+	users   UserRepository
+	erased  []string
+	failing map[string]bool
+}
+
+func (e *testEraser) Erase(ctx context.Context, userId string) error {
+	e.erased = append(e.erased, userId)
+
+	if e.failing[userId] {
+		return errors.New("erasure failed")
+	}
+
+	return e.users.Purge(ctx, userId)
+}
+
+func TestPurgeJob(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		users  = NewMemoryRepository()
+		eraser = &testEraser{users: users}
+		job    = NewPurgeJob(users, eraser, &PurgeConfigs{Retention: time.Minute})
+	)
+
+	jane := &User{Email: "jane@example.com", Username: "jane"}
+	john := &User{Email: "john@example.com", Username: "john"}
+
+	for _, u := range []*User{jane, john} {
+		if err := users.Create(ctx, u); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := users.Delete(ctx, jane.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	// jane was deleted too recently
+	if n, err := job.Purge(ctx); (err != nil) || (n != 0) {
+		t.Fatalf("Purge() within the retention period = %d, %v, want 0", n, err)
+	}
+
+	job.configs.Retention = 0
+
+	if n, err := job.Purge(ctx); (err != nil) || (n != 1) || (len(eraser.erased) != 1) || (eraser.erased[0] != jane.Id) {
+		t.Fatalf("Purge() = %d, %v, erased %v, want jane erased", n, err, eraser.erased)
+	}
+
+	if _, err := users.GetById(WithDeleted(ctx), jane.Id); err == nil {
+		t.Error("jane wasn't purged")
+	}
+
+	if _, err := users.GetById(ctx, john.Id); err != nil {
+		t.Errorf("john = %v, want him kept", err)
+	}
+}
+
+func TestPurgeJobRetriesFailures(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		users  = NewMemoryRepository()
+		eraser = &testEraser{users: users, failing: map[string]bool{}}
+		job    = NewPurgeJob(users, eraser, &PurgeConfigs{Interval: time.Hour})
+	)
+
+	jane := &User{Email: "jane@example.com", Username: "jane"}
+	john := &User{Email: "john@example.com", Username: "john"}
+
+	for _, u := range []*User{jane, john} {
+		if err := users.Create(ctx, u); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := users.Delete(ctx, u.Id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	eraser.failing[jane.Id] = true
+
+	// jane's failure doesn't hold back john
+	if n, err := job.Purge(ctx); (err != nil) || (n != 1) || (len(eraser.erased) != 2) {
+		t.Fatalf("Purge() with a failing user = %d, %v, erased %v, want john purged", n, err, eraser.erased)
+	}
+
+	if _, err := users.GetById(WithDeleted(ctx), john.Id); err == nil {
+		t.Error("john wasn't purged")
+	}
+
+	if r := job.retries[jane.Id]; (r.attempts != 1) || (r.next.Sub(time.Now()) <= time.Minute*59) {
+		t.Errorf("retry of jane = %+v, want a first attempt an interval later", r)
+	}
+
+	// jane isn't retried before her next attempt
+	if n, err := job.Purge(ctx); (err != nil) || (n != 0) || (len(eraser.erased) != 2) {
+		t.Fatalf("Purge() before the retry = %d, %v, erased %v, want nothing attempted", n, err, eraser.erased)
+	}
+
+	eraser.failing[jane.Id] = false
+	job.retries[jane.Id] = purgeRetry{attempts: 1, next: time.Now().Add(-time.Second)}
+
+	if n, err := job.Purge(ctx); (err != nil) || (n != 1) || (len(job.retries) != 0) {
+		t.Fatalf("Purge() after the retry delay = %d, %v, retries %v, want jane purged", n, err, job.retries)
+	}
+}
+
+func TestPurgeJobRetryDelay(t *testing.T) {
+	job := NewPurgeJob(nil, nil, &PurgeConfigs{Interval: time.Hour})
+
+	for attempts, want := range map[int]time.Duration{
+		1:  time.Hour,
+		2:  time.Hour * 2,
+		3:  time.Hour * 4,
+		10: maxPurgeRetryDelay,
+	} {
+		if got := job.retryDelay(attempts); got != want {
+			t.Errorf("retryDelay(%d) = %s, want %s", attempts, got, want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/huboh/go-rest-api/internal/pkg/query"
+)
+
+var (
+	// ErrUserNotFound is returned when no user matches a lookup
+	ErrUserNotFound = errors.New("user not found")
+
+	// ErrUserExists is returned when a user with the same email or username already exists
+	ErrUserExists = errors.New("user with the same email or username already exists")
+)
+
+// listSpec describes the sorting and filtering parameters accepted when listing users.
+var listSpec = query.Spec{
+	Key:          "id",
+	DefaultSort:  "createdAt",
+	DefaultLimit: 50,
+	MaxLimit:     100,
+	Fields: []query.Field{
+		{Name: "id", Column: "id", Type: query.FieldString, Sortable: true, Operators: []query.Operator{query.OpEq}},
+		{Name: "name", Column: "name", Type: query.FieldString, Sortable: true, Operators: []query.Operator{query.OpEq, query.OpLike}},
+		{Name: "email", Column: "email", Type: query.FieldString, Sortable: true, Operators: []query.Operator{query.OpEq, query.OpLike}},
+		{Name: "username", Column: "username", Type: query.FieldString, Sortable: true, Operators: []query.Operator{query.OpEq, query.OpLike}},
+		{Name: "role", Column: "role", Type: query.FieldString, Operators: []query.Operator{query.OpEq, query.OpNe}},
+		{Name: "createdAt", Column: "created_at", Type: query.FieldTime, Sortable: true, Operators: timeOperators},
+		{Name: "updatedAt", Column: "updated_at", Type: query.FieldTime, Sortable: true, Operators: timeOperators},
+	},
+}
+
+// timeOperators are the operators time fields can be filtered with.
+var timeOperators = []query.Operator{query.OpEq, query.OpGt, query.OpGte, query.OpLt, query.OpLte}
+
+// UserRepository persists users.
+//
+// Deleted users are kept, marked as deleted, until they are purged. they are hidden from every query
+// unless the context was created by WithDeleted, except Restore, ListDeleted and Purge which only
+// act on deleted users.
+type UserRepository interface {
+	// Create stores u, assigning its id and timestamps. users without a role are given RoleUser.
+	//
+	// return ErrUserExists when the email or username is taken
+	Create(ctx context.Context, u *User) error
+
+	// GetById returns the user with the given id, or ErrUserNotFound.
+	GetById(ctx context.Context, id string) (*User, error)
+
+	// GetByEmail returns the user with the given email, or ErrUserNotFound.
+	GetByEmail(ctx context.Context, email string) (*User, error)
+
+	// GetByUsername returns the user with the given username, or ErrUserNotFound.
+	GetByUsername(ctx context.Context, username string) (*User, error)
+
+	// Update replaces the stored user with the same id, refreshing its UpdatedAt.
+	//
+	// return ErrUserNotFound when no such user exists and ErrUserExists when the email or username is taken
+	Update(ctx context.Context, u *User) error
+
+	// Delete marks the user with the given id as deleted, or returns ErrUserNotFound.
+	Delete(ctx context.Context, id string) error
+
+	// Restore unmarks the deleted user with the given id, or returns ErrUserNotFound.
+	Restore(ctx context.Context, id string) error
+
+	// ListDeleted returns up to limit users deleted at or before before, the longest deleted first,
+	// skipping the first offset of them.
+	ListDeleted(ctx context.Context, before time.Time, offset int, limit int) ([]User, error)
+
+	// Purge permanently removes the deleted user with the given id, or returns ErrUserNotFound.
+	Purge(ctx context.Context, id string) error
+
+	// List returns the page of users selected by p. hasMore reports whether more users
+	// follow the page, as returned by query.Slice.
+	List(ctx context.Context, p *query.Params) (users []User, hasMore bool, err error)
+
+	// Count returns the number of users matching the filters of p.
+	Count(ctx context.Context, p *query.Params) (int, error)
+}
+
+// userValue is the query.ValueFunc of users, returning the value of the listSpec field named field.
+func userValue(u User, field string) any {
+	switch field {
+	case "name":
+		return u.Name
+	case "email":
+		return u.Email
+	case "username":
+		return u.Username
+	case "role":
+		return string(u.Role)
+	case "createdAt":
+		return u.CreatedAt
+	case "updatedAt":
+		return u.UpdatedAt
+	}
+
+	return u.Id
+}
@@ -0,0 +1,108 @@
+package user
+
+import (
+	"context"
+
+	"github.com/huboh/go-rest-api/internal/pkg/query"
+	"github.com/huboh/go-rest-api/internal/pkg/search"
+)
+
+// SearchWeights are the weights of the user fields when ranking search hits with a search.MemoryIndex.
+var SearchWeights = map[string]float64{
+	"username": 1,
+	"name":     1,
+	"email":    0.5,
+}
+
+// IndexedRepository is a UserRepository keeping a search.Index in sync with the users it stores.
+// deleted users are removed from the index.
+//
+// The index is updated once a write succeeds. writes of a transaction rolled back afterwards
+// remain indexed, so search hits must still be looked up in the repository.
+type IndexedRepository struct {
+	UserRepository
+	index search.Index
+}
+
+// NewIndexedRepository creates an IndexedRepository storing users in users and indexing them in index.
+func NewIndexedRepository(users UserRepository, index search.Index) *IndexedRepository {
+	return &IndexedRepository{
+		UserRepository: users,
+		index:          index,
+	}
+}
+
+func (r *IndexedRepository) Create(ctx context.Context, u *User) error {
+	if err := r.UserRepository.Create(ctx, u); err != nil {
+		return err
+	}
+
+	return r.index.Index(ctx, userDocument(u))
+}
+
+func (r *IndexedRepository) Update(ctx context.Context, u *User) error {
+	if err := r.UserRepository.Update(ctx, u); err != nil {
+		return err
+	}
+
+	return r.index.Index(ctx, userDocument(u))
+}
+
+func (r *IndexedRepository) Delete(ctx context.Context, id string) error {
+	if err := r.UserRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	return r.index.Remove(ctx, id)
+}
+
+func (r *IndexedRepository) Restore(ctx context.Context, id string) error {
+	if err := r.UserRepository.Restore(ctx, id); err != nil {
+		return err
+	}
+
+	u, err := r.UserRepository.GetById(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	return r.index.Index(ctx, userDocument(u))
+}
+
+// IndexUsers adds every user stored in users to index, e.g. to fill a search.MemoryIndex on startup.
+func IndexUsers(ctx context.Context, users UserRepository, index search.Index) error {
+	p := query.NewParams(listSpec)
+
+	for {
+		page, hasMore, err := users.List(ctx, p)
+
+		if err != nil {
+			return err
+		}
+
+		for _, u := range page {
+			if err := index.Index(ctx, userDocument(&u)); err != nil {
+				return err
+			}
+		}
+
+		if !hasMore {
+			return nil
+		}
+
+		p = query.After(p, page[len(page)-1], userValue)
+	}
+}
+
+// userDocument returns the searchable fields of u.
+func userDocument(u *User) search.Document {
+	return search.Document{
+		Id: u.Id,
+		Fields: map[string]string{
+			"name":     u.Name,
+			"email":    u.Email,
+			"username": u.Username,
+		},
+	}
+}
@@ -0,0 +1,218 @@
+package user
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/huboh/go-rest-api/internal/pkg/query"
+	"github.com/huboh/go-rest-api/internal/pkg/utils"
+)
+
+// MemoryRepository is an in-memory UserRepository, mainly useful for tests and local development.
+type MemoryRepository struct {
+	mu    sync.RWMutex
+	users []User
+}
+
+// NewMemoryRepository creates an empty MemoryRepository.
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{}
+}
+
+func (m *MemoryRepository) Create(ctx context.Context, u *User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.taken(u) {
+		return ErrUserExists
+	}
+
+	now := time.Now().UTC()
+
+	if u.Role == "" {
+		u.Role = RoleUser
+	}
+
+	u.Id = utils.NewUUID()
+	u.CreatedAt = now
+	u.UpdatedAt = now
+
+	m.users = append(m.users, *u)
+
+	return nil
+}
+
+func (m *MemoryRepository) GetById(ctx context.Context, id string) (*User, error) {
+	return m.find(ctx, func(u User) bool { return u.Id == id })
+}
+
+func (m *MemoryRepository) GetByEmail(ctx context.Context, email string) (*User, error) {
+	return m.find(ctx, func(u User) bool { return u.Email == email })
+}
+
+func (m *MemoryRepository) GetByUsername(ctx context.Context, username string) (*User, error) {
+	return m.find(ctx, func(u User) bool { return u.Username == username })
+}
+
+func (m *MemoryRepository) Update(ctx context.Context, u *User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.index(u.Id, func(o User) bool { return visible(ctx, o) })
+
+	if i < 0 {
+		return ErrUserNotFound
+	}
+
+	if m.taken(u) {
+		return ErrUserExists
+	}
+
+	u.CreatedAt = m.users[i].CreatedAt
+	u.UpdatedAt = time.Now().UTC()
+	u.DeletedAt = m.users[i].DeletedAt
+	m.users[i] = *u
+
+	return nil
+}
+
+func (m *MemoryRepository) Delete(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.index(id, func(o User) bool { return o.DeletedAt == nil })
+
+	if i < 0 {
+		return ErrUserNotFound
+	}
+
+	now := time.Now().UTC()
+	m.users[i].DeletedAt = &now
+
+	return nil
+}
+
+func (m *MemoryRepository) Restore(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.index(id, func(o User) bool { return o.DeletedAt != nil })
+
+	if i < 0 {
+		return ErrUserNotFound
+	}
+
+	m.users[i].DeletedAt = nil
+	m.users[i].UpdatedAt = time.Now().UTC()
+
+	return nil
+}
+
+func (m *MemoryRepository) ListDeleted(ctx context.Context, before time.Time, offset int, limit int) ([]User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	users := []User{}
+
+	for _, u := range m.users {
+		if (u.DeletedAt != nil) && !u.DeletedAt.After(before) {
+			users = append(users, u)
+		}
+	}
+
+	slices.SortFunc(users, func(a, b User) int {
+		if c := a.DeletedAt.Compare(*b.DeletedAt); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.Id, b.Id)
+	})
+
+	users = users[min(len(users), max(offset, 0)):]
+
+	return users[:min(len(users), max(limit, 0))], nil
+}
+
+func (m *MemoryRepository) Purge(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.index(id, func(o User) bool { return o.DeletedAt != nil })
+
+	if i < 0 {
+		return ErrUserNotFound
+	}
+
+	m.users = slices.Delete(m.users, i, i+1)
+
+	return nil
+}
+
+func (m *MemoryRepository) List(ctx context.Context, p *query.Params) ([]User, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	users, hasMore := query.Select(m.visible(ctx), p, userValue)
+
+	return users, hasMore, nil
+}
+
+func (m *MemoryRepository) Count(ctx context.Context, p *query.Params) (int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return query.Count(m.visible(ctx), p, userValue), nil
+}
+
+// find returns a copy of the first user visible to ctx matching fn.
+func (m *MemoryRepository) find(ctx context.Context, fn func(User) bool) (*User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	i := slices.IndexFunc(m.users, func(u User) bool { return visible(ctx, u) && fn(u) })
+
+	if i < 0 {
+		return nil, ErrUserNotFound
+	}
+
+	u := m.users[i]
+
+	return &u, nil
+}
+
+// visible returns the users visible to ctx. callers must hold m.mu.
+func (m *MemoryRepository) visible(ctx context.Context) []User {
+	if includesDeleted(ctx) {
+		return m.users
+	}
+
+	users := []User{}
+
+	for _, u := range m.users {
+		if u.DeletedAt == nil {
+			users = append(users, u)
+		}
+	}
+
+	return users
+}
+
+// index returns the position of the user with the given id also matching fn, or -1. callers must hold m.mu.
+func (m *MemoryRepository) index(id string, fn func(User) bool) int {
+	return slices.IndexFunc(m.users, func(u User) bool { return (u.Id == id) && fn(u) })
+}
+
+// taken reports whether another user, deleted or not, already has u's email or username. callers must hold m.mu.
+func (m *MemoryRepository) taken(u *User) bool {
+	return slices.ContainsFunc(m.users, func(o User) bool {
+		return (o.Id != u.Id) && ((o.Email == u.Email) || (o.Username == u.Username))
+	})
+}
+
+// visible reports whether u is visible to queries made with ctx.
+func visible(ctx context.Context, u User) bool {
+	return (u.DeletedAt == nil) || includesDeleted(ctx)
+}
@@ -0,0 +1,235 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/huboh/go-rest-api/internal/pkg/database"
+	"github.com/huboh/go-rest-api/internal/pkg/query"
+	"github.com/huboh/go-rest-api/internal/pkg/utils"
+)
+
+// userColumns are the columns selected by every user query, in scanUser order.
+const userColumns = "id, name, email, username, role, password_hash, created_at, updated_at, deleted_at"
+
+// SQLRepository is a UserRepository backed by a SQL database.
+type SQLRepository struct {
+	db *database.DB
+}
+
+// NewSQLRepository creates a SQLRepository using db.
+func NewSQLRepository(db *database.DB) *SQLRepository {
+	return &SQLRepository{db: db}
+}
+
+func (s *SQLRepository) Create(ctx context.Context, u *User) error {
+	now := time.Now().UTC()
+	id := utils.NewUUID()
+	role := u.Role
+
+	if role == "" {
+		role = RoleUser
+	}
+
+	_, err := s.db.Executor(ctx).ExecContext(
+		ctx,
+		s.db.Rebind("INSERT INTO users (id, name, email, username, role, password_hash, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)"),
+		id, u.Name, u.Email, u.Username, role, u.PasswordHash, now, now,
+	)
+
+	if database.IsUniqueViolation(err) {
+		return ErrUserExists
+	}
+
+	if err != nil {
+		return err
+	}
+
+	u.Id = id
+	u.Role = role
+	u.CreatedAt = now
+	u.UpdatedAt = now
+
+	return nil
+}
+
+func (s *SQLRepository) GetById(ctx context.Context, id string) (*User, error) {
+	return s.get(ctx, "id", id)
+}
+
+func (s *SQLRepository) GetByEmail(ctx context.Context, email string) (*User, error) {
+	return s.get(ctx, "email", email)
+}
+
+func (s *SQLRepository) GetByUsername(ctx context.Context, username string) (*User, error) {
+	return s.get(ctx, "username", username)
+}
+
+func (s *SQLRepository) Update(ctx context.Context, u *User) error {
+	now := time.Now().UTC()
+
+	res, err := s.db.Executor(ctx).ExecContext(
+		ctx,
+		s.db.Rebind("UPDATE users SET name = ?, email = ?, username = ?, role = ?, password_hash = ?, updated_at = ? WHERE id = ?"+activeOnly(ctx)),
+		u.Name, u.Email, u.Username, u.Role, u.PasswordHash, now, u.Id,
+	)
+
+	if database.IsUniqueViolation(err) {
+		return ErrUserExists
+	}
+
+	if err := checkAffected(res, err); err != nil {
+		return err
+	}
+
+	u.UpdatedAt = now
+
+	return nil
+}
+
+func (s *SQLRepository) Delete(ctx context.Context, id string) error {
+	return checkAffected(
+		s.db.Executor(ctx).ExecContext(ctx, s.db.Rebind("UPDATE users SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL"), time.Now().UTC(), id),
+	)
+}
+
+func (s *SQLRepository) Restore(ctx context.Context, id string) error {
+	return checkAffected(
+		s.db.Executor(ctx).ExecContext(ctx, s.db.Rebind("UPDATE users SET deleted_at = NULL, updated_at = ? WHERE id = ? AND deleted_at IS NOT NULL"), time.Now().UTC(), id),
+	)
+}
+
+func (s *SQLRepository) ListDeleted(ctx context.Context, before time.Time, offset int, limit int) ([]User, error) {
+	rows, err := s.db.Executor(ctx).QueryContext(
+		ctx,
+		s.db.Rebind("SELECT "+userColumns+" FROM users WHERE deleted_at IS NOT NULL AND deleted_at <= ? ORDER BY deleted_at, id LIMIT ? OFFSET ?"),
+		before.UTC(), max(limit, 0), max(offset, 0),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return scanUsers(rows)
+}
+
+func (s *SQLRepository) Purge(ctx context.Context, id string) error {
+	return checkAffected(s.db.Executor(ctx).ExecContext(ctx, s.db.Rebind("DELETE FROM users WHERE id = ? AND deleted_at IS NOT NULL"), id))
+}
+
+func (s *SQLRepository) List(ctx context.Context, p *query.Params) ([]User, bool, error) {
+	where, args := p.Where()
+
+	rows, err := s.db.Executor(ctx).QueryContext(
+		ctx,
+		s.db.Rebind("SELECT "+userColumns+" FROM users WHERE "+where+activeOnly(ctx)+" ORDER BY "+p.OrderBy()+" LIMIT ?"),
+		append(args, p.Fetch())...,
+	)
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	users, err := scanUsers(rows)
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	users, hasMore := query.Slice(p, users)
+
+	return users, hasMore, nil
+}
+
+func (s *SQLRepository) Count(ctx context.Context, p *query.Params) (int, error) {
+	var (
+		n           int
+		where, args = p.FilterWhere()
+	)
+
+	err := s.db.Executor(ctx).QueryRowContext(ctx, s.db.Rebind("SELECT COUNT(*) FROM users WHERE "+where+activeOnly(ctx)), args...).Scan(&n)
+
+	return n, err
+}
+
+// get returns the user whose column equals val. column must be a trusted identifier.
+func (s *SQLRepository) get(ctx context.Context, column string, val string) (*User, error) {
+	u, err := scanUser(
+		s.db.Executor(ctx).QueryRowContext(ctx, s.db.Rebind("SELECT "+userColumns+" FROM users WHERE "+column+" = ?"+activeOnly(ctx)), val),
+	)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
+
+	return u, err
+}
+
+// scanUser scans a row selected with userColumns into a User.
+func scanUser(row interface{ Scan(...any) error }) (*User, error) {
+	var (
+		u         = &User{}
+		deletedAt sql.NullTime
+	)
+
+	err := row.Scan(&u.Id, &u.Name, &u.Email, &u.Username, &u.Role, &u.PasswordHash, &u.CreatedAt, &u.UpdatedAt, &deletedAt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if deletedAt.Valid {
+		u.DeletedAt = &deletedAt.Time
+	}
+
+	return u, nil
+}
+
+// scanUsers scans and closes rows selected with userColumns.
+func scanUsers(rows *sql.Rows) ([]User, error) {
+	defer rows.Close()
+
+	users := []User{}
+
+	for rows.Next() {
+		u, err := scanUser(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, *u)
+	}
+
+	return users, rows.Err()
+}
+
+// activeOnly returns the condition hiding deleted users from queries made with ctx, to append to a WHERE clause.
+func activeOnly(ctx context.Context) string {
+	if includesDeleted(ctx) {
+		return ""
+	}
+
+	return " AND deleted_at IS NULL"
+}
+
+// checkAffected returns ErrUserNotFound when an exec succeeded without touching any row.
+func checkAffected(res sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/huboh/go-rest-api/internal/pkg/database"
+	"github.com/huboh/go-rest-api/internal/pkg/database/dbtest"
+	"github.com/huboh/go-rest-api/internal/pkg/query"
+)
+
+func TestMemoryRepository(t *testing.T) {
+	testRepository(t, NewMemoryRepository())
+}
+
+func TestSQLRepository(t *testing.T) {
+	dbtest.Run(t, func(t *testing.T, db *database.DB) {
+		testRepository(t, NewSQLRepository(db))
+	})
+}
+
+// testRepository checks that repo meets the UserRepository contract.
+func testRepository(t *testing.T, repo UserRepository) {
+	ctx := context.Background()
+
+	jane := &User{Name: "Jane", Email: "jane@example.com", Username: "jane", PasswordHash: "hash"}
+
+	if err := repo.Create(ctx, jane); err != nil {
+		t.Fatalf("Create() = %s", err)
+	}
+
+	if (jane.Id == "") || (jane.Role != RoleUser) || jane.CreatedAt.IsZero() {
+		t.Fatalf("Create() didn't assign the id, role and timestamps: %+v", jane)
+	}
+
+	dup := &User{Email: "jane@example.com", Username: "jane2"}
+
+	if err := repo.Create(ctx, dup); !errors.Is(err, ErrUserExists) {
+		t.Fatalf("Create() of a taken email = %v, want ErrUserExists", err)
+	}
+
+	john := &User{Name: "John", Email: "john@example.com", Username: "john", Role: RoleAdmin}
+
+	if err := repo.Create(ctx, john); err != nil {
+		t.Fatalf("Create() = %s", err)
+	}
+
+	for name, get := range map[string]func() (*User, error){
+		"GetById":       func() (*User, error) { return repo.GetById(ctx, jane.Id) },
+		"GetByEmail":    func() (*User, error) { return repo.GetByEmail(ctx, jane.Email) },
+		"GetByUsername": func() (*User, error) { return repo.GetByUsername(ctx, jane.Username) },
+	} {
+		if u, err := get(); (err != nil) || (u.Id != jane.Id) || (u.PasswordHash != "hash") {
+			t.Errorf("%s() = %+v, %v, want jane", name, u, err)
+		}
+	}
+
+	jane.Name = "Jane Doe"
+
+	if err := repo.Update(ctx, jane); err != nil {
+		t.Fatalf("Update() = %s", err)
+	}
+
+	if u, _ := repo.GetById(ctx, jane.Id); (u == nil) || (u.Name != "Jane Doe") {
+		t.Errorf("GetById() after Update() = %+v, want the new name", u)
+	}
+
+	john.Username = "jane"
+
+	if err := repo.Update(ctx, john); !errors.Is(err, ErrUserExists) {
+		t.Errorf("Update() to a taken username = %v, want ErrUserExists", err)
+	}
+
+	p := query.NewParams(listSpec)
+	p.Limit = 1
+
+	users, hasMore, err := repo.List(ctx, p)
+
+	if (err != nil) || (len(users) != 1) || !hasMore {
+		t.Errorf("List() of the first page = %d users, %t, %v, want 1 user and more", len(users), hasMore, err)
+	}
+
+	if n, err := repo.Count(ctx, query.NewParams(listSpec)); (err != nil) || (n != 2) {
+		t.Errorf("Count() = %d, %v, want 2", n, err)
+	}
+
+	// deleted users are hidden until restored, and only purged once deleted
+	if err := repo.Purge(ctx, jane.Id); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("Purge() of a user that isn't deleted = %v, want ErrUserNotFound", err)
+	}
+
+	if err := repo.Delete(ctx, jane.Id); err != nil {
+		t.Fatalf("Delete() = %s", err)
+	}
+
+	if _, err := repo.GetById(ctx, jane.Id); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("GetById() of a deleted user = %v, want ErrUserNotFound", err)
+	}
+
+	if u, err := repo.GetById(WithDeleted(ctx), jane.Id); (err != nil) || (u.DeletedAt == nil) {
+		t.Errorf("GetById() of a deleted user with WithDeleted = %+v, %v", u, err)
+	}
+
+	if deleted, err := repo.ListDeleted(ctx, time.Now().Add(time.Minute), 0, 10); (err != nil) || (len(deleted) != 1) || (deleted[0].Id != jane.Id) {
+		t.Errorf("ListDeleted() = %+v, %v, want jane", deleted, err)
+	}
+
+	if deleted, err := repo.ListDeleted(ctx, time.Now().Add(time.Minute), 1, 10); (err != nil) || (len(deleted) != 0) {
+		t.Errorf("ListDeleted() past jane = %+v, %v, want none", deleted, err)
+	}
+
+	if err := repo.Restore(ctx, jane.Id); err != nil {
+		t.Fatalf("Restore() = %s", err)
+	}
+
+	if _, err := repo.GetById(ctx, jane.Id); err != nil {
+		t.Errorf("GetById() of a restored user = %v", err)
+	}
+
+	if err := repo.Delete(ctx, jane.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.Purge(ctx, jane.Id); err != nil {
+		t.Fatalf("Purge() = %s", err)
+	}
+
+	if _, err := repo.GetById(WithDeleted(ctx), jane.Id); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("GetById() of a purged user = %v, want ErrUserNotFound", err)
+	}
+}
@@ -0,0 +1,24 @@
+package user
+
+// updateUserRequest holds the user fields to update. nil fields are left unchanged.
+type updateUserRequest struct {
+	// Id is the id of the updated user in the path of admin routes, unset on the "/me" routes.
+	Id string `json:"-" path:"id"`
+
+	Name     *string `json:"name" validate:"omitnil,required"`
+	Email    *string `json:"email" validate:"omitnil,required,email"`
+	Username *string `json:"username" validate:"omitnil,required,username"`
+	Role     *Role   `json:"role" validate:"omitnil,required,role"`
+}
+
+// searchRequest holds the query parameters of user searches.
+type searchRequest struct {
+	Query  string
+	Limit  int
+	Offset int
+}
+
+// erasureRequest holds the password confirming an erasure request.
+type erasureRequest struct {
+	Password string `json:"password"`
+}
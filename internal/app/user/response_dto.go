@@ -0,0 +1,8 @@
+package user
+
+// searchHit is a user matching a search.
+type searchHit struct {
+	User       User              `json:"user"`
+	Score      float64           `json:"score"`
+	Highlights map[string]string `json:"highlights"`
+}
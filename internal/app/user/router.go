@@ -4,24 +4,179 @@ import (
 	"net/http"
 
 	"github.com/huboh/go-rest-api/internal/pkg/middleware"
+	"github.com/huboh/go-rest-api/internal/pkg/privacy"
 	"github.com/huboh/go-rest-api/internal/pkg/router"
 )
 
-var (
-	Router = router.New(
+// withDeletedParam describes the query parameter including deleted users in admin lookups.
+var withDeletedParam = router.Parameter{
+	Name:        "withDeleted",
+	In:          "query",
+	Type:        "boolean",
+	Description: "includes deleted users",
+}
+
+// searchParams describes the query parameters of user searches.
+var searchParams = []router.Parameter{
+	{Name: "q", In: "query", Required: true, Description: "the search query"},
+	{Name: "limit", In: "query", Type: "integer", Description: "the page size"},
+	{Name: "offset", In: "query", Type: "integer", Description: "the number of hits to skip"},
+}
+
+// NewRouter creates the router serving the user routes of s, wrapped by mws.
+// mws must authenticate requests, the "/me" routes act on the user stored by ContextWithUser.
+func NewRouter(s *Service, mws []middleware.Middleware) *router.Router {
+	return router.New(
 		// mount path
 		RouterPath,
 
 		// middlewares
-		[]middleware.Middleware{},
+		mws,
 
 		// routes
 		[]router.Route{
 			{
-				Path:    "/hello",
+				Path:     "/hello",
+				Method:   http.MethodGet,
+				Handler:  http.HandlerFunc(s.handleGetHello),
+				Summary:  "Say hello",
+				Response: "",
+			},
+			{
+				Path:     "/me",
+				Method:   http.MethodGet,
+				Handler:  http.HandlerFunc(s.handleGetMe),
+				Summary:  "Get the authenticated user",
+				Response: User{},
+			},
+			{
+				Path:    "/me",
+				Method:  http.MethodPatch,
+				Handler: router.Handle(s.handleUpdateMe, router.WithErrorStatus(errStatusCode)),
+				Summary: "Update the authenticated user",
+			},
+			{
+				Path:    "/me",
+				Method:  http.MethodDelete,
+				Handler: http.HandlerFunc(s.handleDeleteMe),
+				Summary: "Delete the authenticated user",
+			},
+			{
+				Path:     "/me/export",
+				Method:   http.MethodPost,
+				Handler:  http.HandlerFunc(s.handleRequestExport),
+				Summary:  "Request an export of the personal data of the authenticated user",
+				Response: privacy.Export{},
+			},
+			{
+				Name:     GetExportRoute,
+				Path:     "/me/exports/{id:uuid}",
+				Method:   http.MethodGet,
+				Handler:  http.HandlerFunc(s.handleGetExport),
+				Summary:  "Get a personal data export",
+				Response: privacy.Export{},
+			},
+			{
+				Path:    "/me/exports/{id:uuid}/download",
 				Method:  http.MethodGet,
-				Handler: http.HandlerFunc(handleGetHello),
+				Handler: http.HandlerFunc(s.handleDownloadExport),
+				Summary: "Download the archive of a personal data export, as a json attachment",
+			},
+			{
+				Path:     "/me/erase",
+				Method:   http.MethodPost,
+				Handler:  http.HandlerFunc(s.handleRequestErasure),
+				Summary:  "Schedule the erasure of the personal data of the authenticated user",
+				Request:  erasureRequest{},
+				Response: privacy.Erasure{},
+			},
+			{
+				Name:     GetErasureRoute,
+				Path:     "/me/erase",
+				Method:   http.MethodGet,
+				Handler:  http.HandlerFunc(s.handleGetErasure),
+				Summary:  "Get the scheduled erasure of the authenticated user",
+				Response: privacy.Erasure{},
+			},
+			{
+				Path:    "/me/erase",
+				Method:  http.MethodDelete,
+				Handler: http.HandlerFunc(s.handleCancelErasure),
+				Summary: "Cancel the scheduled erasure of the authenticated user",
+			},
+
+			// admin routes
+			{
+				Path:        "",
+				Method:      http.MethodGet,
+				Handler:     http.HandlerFunc(s.handleListUsers),
+				Middlewares: []middleware.Middleware{s.AdminGuardMiddleware},
+				Auth:        []string{"admin"},
+				Summary:     "List users",
+				Response:    []User{},
+				Params:      append(listSpec.Parameters(), withDeletedParam),
+			},
+			{
+				Path:        "/search",
+				Method:      http.MethodGet,
+				Handler:     http.HandlerFunc(s.handleSearchUsers),
+				Middlewares: []middleware.Middleware{s.AdminGuardMiddleware},
+				Auth:        []string{"admin"},
+				Summary:     "Search users by name, email and username",
+				Response:    []searchHit{},
+				Params:      searchParams,
+			},
+			{
+				Path:        "/{id:uuid}",
+				Method:      http.MethodGet,
+				Handler:     http.HandlerFunc(s.handleGetUser),
+				Middlewares: []middleware.Middleware{s.AdminGuardMiddleware},
+				Auth:        []string{"admin"},
+				Summary:     "Get a user",
+				Response:    User{},
+				Params:      []router.Parameter{withDeletedParam},
+			},
+			{
+				Path:        "/{id:uuid}",
+				Method:      http.MethodPatch,
+				Handler:     router.Handle(s.handleUpdateUser, router.WithErrorStatus(errStatusCode)),
+				Middlewares: []middleware.Middleware{s.AdminGuardMiddleware},
+				Auth:        []string{"admin"},
+				Summary:     "Update a user",
+			},
+			{
+				Path:        "/{id:uuid}",
+				Method:      http.MethodDelete,
+				Handler:     http.HandlerFunc(s.handleDeleteUser),
+				Middlewares: []middleware.Middleware{s.AdminGuardMiddleware},
+				Auth:        []string{"admin"},
+				Summary:     "Delete a user",
+			},
+			{
+				Path:        "/{id:uuid}/restore",
+				Method:      http.MethodPost,
+				Handler:     http.HandlerFunc(s.handleRestoreUser),
+				Middlewares: []middleware.Middleware{s.AdminGuardMiddleware},
+				Auth:        []string{"admin"},
+				Summary:     "Restore a deleted user",
+				Response:    User{},
 			},
 		},
 	)
-)
+}
+
+// Module adds the user routes to the router of the app, see router.Module.
+type Module struct {
+	service *Service
+	mws     []middleware.Middleware
+}
+
+// NewModule creates the module mounting the router of s, wrapped by mws. mws must authenticate requests.
+func NewModule(s *Service, mws []middleware.Middleware) *Module {
+	return &Module{service: s, mws: mws}
+}
+
+// RegisterRoutes makes Module implement router.Module.
+func (m *Module) RegisterRoutes(r *router.Router) {
+	r.Mount(RouterPath, NewRouter(m.service, m.mws), router.Tagged("users"), router.Secured("bearer"), router.Guarded("authenticated"))
+}
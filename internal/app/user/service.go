@@ -0,0 +1,40 @@
+package user
+
+import (
+	"context"
+
+	"github.com/huboh/go-rest-api/internal/pkg/database"
+	"github.com/huboh/go-rest-api/internal/pkg/privacy"
+	"github.com/huboh/go-rest-api/internal/pkg/query"
+	"github.com/huboh/go-rest-api/internal/pkg/search"
+)
+
+// TokenRevoker revokes the tokens issued to users.
+type TokenRevoker interface {
+	// RevokeTokens revokes every token issued to the user with the given id so far.
+	RevokeTokens(ctx context.Context, id string) error
+}
+
+// Service holds the dependencies used by the user handlers.
+type Service struct {
+	users     UserRepository
+	index     search.Index
+	revoker   TokenRevoker
+	tx        database.TxManager
+	privacy   *privacy.Service
+	listQuery *query.Parser
+}
+
+// NewService creates a new user Service that reads and writes users from users, searches them in index,
+// revokes the tokens of deleted users with revoker, runs multi-record changes within tx and exports or
+// erases the personal data of users with privacy.
+func NewService(users UserRepository, index search.Index, revoker TokenRevoker, tx database.TxManager, privacy *privacy.Service) *Service {
+	return &Service{
+		tx:        tx,
+		privacy:   privacy,
+		users:     users,
+		index:     index,
+		revoker:   revoker,
+		listQuery: query.NewParser(listSpec),
+	}
+}
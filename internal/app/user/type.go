@@ -1,7 +1,34 @@
 package user
 
+import "time"
+
+// Role is the access level of a User.
+type Role string
+
+// recognized Role
+const (
+	RoleUser  = Role("user")
+	RoleAdmin = Role("admin")
+)
+
 type User struct {
+	Id       string `json:"id"`
 	Name     string `json:"name"`
 	Email    string `json:"email"`
 	Username string `json:"username"`
+	Role     Role   `json:"role"`
+
+	// PasswordHash is the bcrypt hash of the user's password. it is never serialized.
+	PasswordHash string `json:"-"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	// DeletedAt is the time the user was deleted. it is nil for active users.
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+}
+
+// Valid reports whether r is a recognized Role.
+func (r Role) Valid() bool {
+	return (r == RoleUser) || (r == RoleAdmin)
 }
@@ -1,10 +1,21 @@
 package user
 
-import "context"
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/huboh/go-rest-api/internal/pkg/privacy"
+)
 
 type userKey string
 
-const user = userKey("user")
+const (
+	user        = userKey("user")
+	withDeleted = userKey("withDeleted")
+)
 
 func ContextWithUser(c context.Context, u string) context.Context {
 	return context.WithValue(c, user, u)
@@ -14,3 +25,71 @@ func UserFromContext(ctx context.Context) (string, bool) {
 	u, ok := ctx.Value(user).(string)
 	return u, ok
 }
+
+// WithDeleted returns a copy of c in which UserRepository queries include deleted users.
+func WithDeleted(c context.Context) context.Context {
+	return context.WithValue(c, withDeleted, true)
+}
+
+// includesDeleted reports whether UserRepository queries made with ctx include deleted users.
+func includesDeleted(ctx context.Context) bool {
+	v, _ := ctx.Value(withDeleted).(bool)
+	return v
+}
+
+// NormalizeEmail trims and lowercases an email address so lookups are case insensitive.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// getListContext returns the context of r, including deleted users when the "withDeleted" query parameter is true.
+func getListContext(r *http.Request) context.Context {
+	if ok, _ := strconv.ParseBool(r.URL.Query().Get("withDeleted")); ok {
+		return WithDeleted(r.Context())
+	}
+
+	return r.Context()
+}
+
+// getSearchRequest reads the "q", "limit" and "offset" query parameters of r.
+func getSearchRequest(r *http.Request) (searchRequest, error) {
+	var (
+		err error
+		q   = r.URL.Query()
+		req = searchRequest{Query: strings.TrimSpace(q.Get("q")), Limit: defSearchLimit}
+	)
+
+	if req.Query == "" {
+		return req, errors.New("q is required")
+	}
+
+	if v := q.Get("limit"); v != "" {
+		if req.Limit, err = strconv.Atoi(v); (err != nil) || (req.Limit < 1) {
+			return req, errors.New("limit must be a positive integer")
+		}
+
+		req.Limit = min(req.Limit, maxSearchLimit)
+	}
+
+	if v := q.Get("offset"); v != "" {
+		if req.Offset, err = strconv.Atoi(v); (err != nil) || (req.Offset < 0) {
+			return req, errors.New("offset must be a non-negative integer")
+		}
+	}
+
+	return req, nil
+}
+
+// errStatusCode returns the http status code that best describes err.
+func errStatusCode(err error) int {
+	switch {
+	case errors.Is(err, ErrUserNotFound), errors.Is(err, privacy.ErrExportNotFound), errors.Is(err, privacy.ErrErasureNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrUserExists), errors.Is(err, privacy.ErrExportNotReady), errors.Is(err, privacy.ErrErasureExists):
+		return http.StatusConflict
+	case errors.Is(err, ErrForbidden), errors.Is(err, ErrWrongPassword):
+		return http.StatusForbidden
+	}
+
+	return http.StatusInternalServerError
+}
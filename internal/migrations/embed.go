@@ -0,0 +1,23 @@
+// Package migrations embeds the versioned SQL schema migrations of the app, one directory per database driver.
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+
+	"github.com/huboh/go-rest-api/internal/pkg/database"
+)
+
+//go:embed sqlite/*.sql postgres/*.sql
+var files embed.FS
+
+// FS returns the migrations written for the driver d.
+func FS(d database.Driver) (fs.FS, error) {
+	switch d {
+	case database.DriverSQLite, database.DriverPostgres:
+		return fs.Sub(files, string(d))
+	}
+
+	return nil, fmt.Errorf("no migrations for database driver \"%s\"", d)
+}
@@ -0,0 +1,2 @@
+DROP INDEX users_search_idx;
+ALTER TABLE users DROP COLUMN search;
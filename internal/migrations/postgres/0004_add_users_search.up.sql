@@ -0,0 +1,7 @@
+ALTER TABLE users ADD COLUMN search TSVECTOR GENERATED ALWAYS AS (
+	setweight(to_tsvector('simple', username), 'A') ||
+	setweight(to_tsvector('simple', name), 'A') ||
+	setweight(to_tsvector('simple', email), 'B')
+) STORED;
+
+CREATE INDEX users_search_idx ON users USING GIN (search);
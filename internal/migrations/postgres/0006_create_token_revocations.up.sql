@@ -0,0 +1,4 @@
+CREATE TABLE token_revocations (
+	subject    TEXT PRIMARY KEY,
+	revoked_at TIMESTAMPTZ NOT NULL
+);
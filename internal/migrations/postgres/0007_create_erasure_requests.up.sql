@@ -0,0 +1,7 @@
+CREATE TABLE erasure_requests (
+	user_id      TEXT PRIMARY KEY,
+	requested_at TIMESTAMPTZ NOT NULL,
+	erase_at     TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX erasure_requests_erase_at_idx ON erasure_requests (erase_at);
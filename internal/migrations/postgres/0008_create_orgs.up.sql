@@ -0,0 +1,18 @@
+CREATE TABLE orgs (
+	id         TEXT PRIMARY KEY,
+	name       TEXT NOT NULL,
+	slug       TEXT NOT NULL UNIQUE,
+	created_at TIMESTAMPTZ NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE memberships (
+	org_id     TEXT NOT NULL REFERENCES orgs (id) ON DELETE CASCADE,
+	user_id    TEXT NOT NULL,
+	role       TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY (org_id, user_id)
+);
+
+CREATE INDEX memberships_user_id_idx ON memberships (user_id);
@@ -0,0 +1,11 @@
+CREATE TABLE exports (
+	id         TEXT PRIMARY KEY,
+	user_id    TEXT NOT NULL,
+	status     TEXT NOT NULL,
+	archive    BYTEA,
+	created_at TIMESTAMPTZ NOT NULL,
+	expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX exports_user_id_idx ON exports (user_id);
+CREATE INDEX exports_expires_at_idx ON exports (expires_at);
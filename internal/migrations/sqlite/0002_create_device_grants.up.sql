@@ -0,0 +1,11 @@
+CREATE TABLE device_grants (
+	device_code  TEXT PRIMARY KEY,
+	user_code    TEXT NOT NULL UNIQUE,
+	client_id    TEXT NOT NULL,
+	scope        TEXT NOT NULL,
+	status       TEXT NOT NULL,
+	subject      TEXT NOT NULL,
+	expires_at   TIMESTAMP NOT NULL,
+	interval_ms  INTEGER NOT NULL,
+	last_poll_at TIMESTAMP
+);
@@ -0,0 +1,7 @@
+CREATE TABLE erasure_requests (
+	user_id      TEXT PRIMARY KEY,
+	requested_at TIMESTAMP NOT NULL,
+	erase_at     TIMESTAMP NOT NULL
+);
+
+CREATE INDEX erasure_requests_erase_at_idx ON erasure_requests (erase_at);
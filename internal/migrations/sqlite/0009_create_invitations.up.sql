@@ -0,0 +1,10 @@
+CREATE TABLE invitations (
+	id         TEXT PRIMARY KEY,
+	org_id     TEXT NOT NULL REFERENCES orgs (id) ON DELETE CASCADE,
+	email      TEXT NOT NULL,
+	role       TEXT NOT NULL,
+	invited_by TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	expires_at TIMESTAMP NOT NULL,
+	UNIQUE (org_id, email)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE erasure_requests DROP COLUMN next_attempt_at;
+ALTER TABLE erasure_requests DROP COLUMN attempts;
@@ -0,0 +1,2 @@
+ALTER TABLE erasure_requests ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE erasure_requests ADD COLUMN next_attempt_at TIMESTAMP;
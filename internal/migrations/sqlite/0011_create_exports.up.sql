@@ -0,0 +1,11 @@
+CREATE TABLE exports (
+	id         TEXT PRIMARY KEY,
+	user_id    TEXT NOT NULL,
+	status     TEXT NOT NULL,
+	archive    BLOB,
+	created_at TIMESTAMP NOT NULL,
+	expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX exports_user_id_idx ON exports (user_id);
+CREATE INDEX exports_expires_at_idx ON exports (expires_at);
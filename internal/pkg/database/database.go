@@ -0,0 +1,220 @@
+// Package database provides utilities for opening and working with SQL databases.
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/huboh/go-rest-api/internal/pkg/env"
+	"github.com/huboh/go-rest-api/internal/pkg/utils"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	_ "modernc.org/sqlite"
+)
+
+// Driver is the name of a supported database driver.
+type Driver string
+
+// recognized Driver
+const (
+	DriverMemory   = Driver("memory")
+	DriverSQLite   = Driver("sqlite")
+	DriverPostgres = Driver("postgres")
+)
+
+const (
+	defDriver = DriverSQLite
+	defDsn    = "file:data.db?_pragma=busy_timeout(5000)"
+
+	defMaxOpenConns    = 25
+	defMaxIdleConns    = 25
+	defConnMaxLifetime = time.Minute * 30
+	defConnMaxIdleTime = time.Minute * 5
+)
+
+// pgUniqueViolation is the postgres error code of unique constraint violations.
+const pgUniqueViolation = "23505"
+
+// Config holds the settings used to open a DB and size its connection pool.
+type Config struct {
+	Driver Driver
+	Dsn    string
+
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// NewConfig initializes a new Config instance by reading environment variables,
+// falling back to defaults when they are unset.
+func NewConfig() *Config {
+	c := &Config{
+		Driver:          Driver(env.Get("DB_DRIVER")),
+		Dsn:             env.Get("DB_DSN"),
+		MaxOpenConns:    defMaxOpenConns,
+		MaxIdleConns:    defMaxIdleConns,
+		ConnMaxLifetime: defConnMaxLifetime,
+		ConnMaxIdleTime: defConnMaxIdleTime,
+	}
+
+	if c.Driver == "" {
+		c.Driver = defDriver
+	}
+
+	if (c.Dsn == "") && (c.Driver == DriverSQLite) {
+		c.Dsn = defDsn
+	}
+
+	if v := env.Get("DB_MAX_OPEN_CONNS"); v != "" {
+		c.MaxOpenConns = utils.Must(strconv.Atoi(v))
+	}
+
+	if v := env.Get("DB_MAX_IDLE_CONNS"); v != "" {
+		c.MaxIdleConns = utils.Must(strconv.Atoi(v))
+	}
+
+	if v := env.Get("DB_CONN_MAX_LIFETIME"); v != "" {
+		c.ConnMaxLifetime = utils.Must(time.ParseDuration(v))
+	}
+
+	if v := env.Get("DB_CONN_MAX_IDLE_TIME"); v != "" {
+		c.ConnMaxIdleTime = utils.Must(time.ParseDuration(v))
+	}
+
+	return c
+}
+
+// DB is a pool of database connections bound to a Driver.
+type DB struct {
+	*sql.DB
+
+	// Driver is the driver the connections were opened with
+	Driver Driver
+}
+
+// Open opens a database using the configs c and verifies the connection.
+func Open(ctx context.Context, c *Config) (*DB, error) {
+	var driverName string
+
+	switch c.Driver {
+	case DriverSQLite:
+		driverName = "sqlite"
+	case DriverPostgres:
+		driverName = "pgx"
+	default:
+		return nil, fmt.Errorf("unsupported database driver \"%s\"", c.Driver)
+	}
+
+	conn, err := sql.Open(driverName, c.Dsn)
+
+	if err != nil {
+		return nil, err
+	}
+
+	conn.SetMaxOpenConns(c.MaxOpenConns)
+	conn.SetMaxIdleConns(c.MaxIdleConns)
+	conn.SetConnMaxLifetime(c.ConnMaxLifetime)
+	conn.SetConnMaxIdleTime(c.ConnMaxIdleTime)
+
+	if c.Driver == DriverSQLite {
+		// sqlite allows a single writer, sharing one connection avoids "database is locked" errors
+		conn.SetMaxOpenConns(1)
+	}
+
+	if err := conn.PingContext(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &DB{DB: conn, Driver: c.Driver}, nil
+}
+
+// Rebind rewrites the "?" placeholders of query into the placeholder style of the db's driver.
+//
+// question marks within quoted strings, quoted identifiers and comments are kept, as are those of the
+// postgres "?|" and "?&" jsonb operators. the "?" operator, which can't be told from a placeholder,
+// is written "??".
+func (db *DB) Rebind(query string) string {
+	if db.Driver != DriverPostgres {
+		return query
+	}
+
+	var (
+		n  int
+		sb strings.Builder
+	)
+
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+
+		switch {
+		case (c == '\'') || (c == '"'):
+			// doubled quotes escape quotes, the scan goes on after the closing quote either way
+			end := strings.IndexByte(query[i+1:], c) + i + 1
+
+			if end == i {
+				// unterminated, the rest of the query is quoted
+				end = len(query) - 1
+			}
+
+			sb.WriteString(query[i : end+1])
+			i = end
+
+		case strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+
+			if end < 0 {
+				end = len(query) - i
+			}
+
+			sb.WriteString(query[i : i+end])
+			i += end - 1
+
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+
+			if end < 0 {
+				end = len(query) - i - 4
+			}
+
+			sb.WriteString(query[i : i+end+4])
+			i += end + 3
+
+		case c != '?':
+			sb.WriteByte(c)
+
+		case strings.HasPrefix(query[i:], "??"):
+			sb.WriteByte('?')
+			i++
+
+		case strings.HasPrefix(query[i:], "?&"), strings.HasPrefix(query[i:], "?|") && !strings.HasPrefix(query[i:], "?||"):
+			// "?||" is a placeholder concatenated to a value
+			sb.WriteString(query[i : i+2])
+			i++
+
+		default:
+			n++
+			sb.WriteString("$" + strconv.Itoa(n))
+		}
+	}
+
+	return sb.String()
+}
+
+// IsUniqueViolation reports whether err was caused by a unique constraint violation.
+func IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == pgUniqueViolation
+	}
+
+	return (err != nil) && strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
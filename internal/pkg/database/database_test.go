@@ -0,0 +1,38 @@
+package database
+
+import "testing"
+
+func TestRebind(t *testing.T) {
+	var (
+		pg     = &DB{Driver: DriverPostgres}
+		sqlite = &DB{Driver: DriverSQLite}
+	)
+
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"placeholders", "SELECT * FROM users WHERE id = ? AND email = ?", "SELECT * FROM users WHERE id = $1 AND email = $2"},
+		{"string literal", "SELECT '?', 'it''s ?' FROM t WHERE a = ?", "SELECT '?', 'it''s ?' FROM t WHERE a = $1"},
+		{"quoted identifier", `SELECT "what?" FROM t WHERE a = ?`, `SELECT "what?" FROM t WHERE a = $1`},
+		{"line comment", "SELECT a -- why?\nFROM t WHERE a = ?", "SELECT a -- why?\nFROM t WHERE a = $1"},
+		{"block comment", "SELECT /* a? */ a FROM t WHERE a = ?", "SELECT /* a? */ a FROM t WHERE a = $1"},
+		{"jsonb operators", "SELECT * FROM t WHERE data ?| ? AND data ?& ?", "SELECT * FROM t WHERE data ?| $1 AND data ?& $2"},
+		{"escaped operator", "SELECT * FROM t WHERE data ?? ?", "SELECT * FROM t WHERE data ? $1"},
+		{"concatenation", "SELECT ?||name FROM t", "SELECT $1||name FROM t"},
+		{"unterminated string", "SELECT ? FROM t WHERE a = 'x?", "SELECT $1 FROM t WHERE a = 'x?"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pg.Rebind(tt.query); got != tt.want {
+				t.Errorf("Rebind(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+
+			if got := sqlite.Rebind(tt.query); got != tt.query {
+				t.Errorf("sqlite Rebind(%q) = %q, want the query unchanged", tt.query, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package database
+
+import (
+	"context"
+	"time"
+)
+
+// HealthStatus is the reported state of a database.
+type HealthStatus string
+
+// recognized HealthStatus
+const (
+	HealthStatusUp   = HealthStatus("up")
+	HealthStatusDown = HealthStatus("down")
+)
+
+// Health represents the health of a DB and its connection pool.
+type Health struct {
+	// Status is either "up" or "down".
+	Status HealthStatus `json:"status"`
+
+	// Error is the reason the database is down. this field is omitted when it is up.
+	Error string `json:"error,omitempty"`
+
+	// Driver is the driver the connections were opened with.
+	Driver Driver `json:"driver"`
+
+	// Latency is the round trip time of the health check ping.
+	Latency string `json:"latency"`
+
+	// OpenConnections is the number of established connections, both in use and idle.
+	OpenConnections int `json:"openConnections"`
+
+	// InUse is the number of connections currently in use.
+	InUse int `json:"inUse"`
+
+	// Idle is the number of idle connections.
+	Idle int `json:"idle"`
+
+	// WaitCount is the total number of connections waited for.
+	WaitCount int64 `json:"waitCount"`
+
+	// WaitDuration is the total time blocked waiting for a new connection.
+	WaitDuration string `json:"waitDuration"`
+}
+
+// Health pings the database and reports its state along with the connection pool statistics.
+func (db *DB) Health(ctx context.Context) Health {
+	now := time.Now()
+	err := db.PingContext(ctx)
+	stats := db.Stats()
+
+	h := Health{
+		Status:          HealthStatusUp,
+		Driver:          db.Driver,
+		Latency:         time.Since(now).String(),
+		OpenConnections: stats.OpenConnections,
+		InUse:           stats.InUse,
+		Idle:            stats.Idle,
+		WaitCount:       stats.WaitCount,
+		WaitDuration:    stats.WaitDuration.String(),
+	}
+
+	if err != nil {
+		h.Status = HealthStatusDown
+		h.Error = err.Error()
+	}
+
+	return h
+}
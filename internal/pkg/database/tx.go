@@ -0,0 +1,154 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/huboh/go-rest-api/internal/pkg/json"
+	"github.com/huboh/go-rest-api/internal/pkg/middleware"
+)
+
+// txKey is the context key of the transaction started by DB.WithinTx.
+type txKey struct{}
+
+// Executor runs queries. it is implemented by both *sql.DB and *sql.Tx.
+type Executor interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// TxManager runs functions within a transaction.
+type TxManager interface {
+	// WithinTx calls fn with a context carrying a transaction. The transaction is committed when fn
+	// returns nil and rolled back when it returns an error or panics; panics are re-raised after
+	// the rollback. When ctx already carries a transaction fn joins it instead of starting a new one.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// NoopTxManager is a TxManager for stores without transactions, such as the in-memory ones.
+// It calls functions directly, so their changes aren't rolled back on errors.
+type NoopTxManager struct{}
+
+func (NoopTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// WithinTx makes DB meet the TxManager interface
+func (db *DB) WithinTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if val := recover(); val != nil {
+			tx.Rollback()
+			panic(val)
+		}
+
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				err = fmt.Errorf("%w (rollback failed: %w)", err, rbErr)
+			}
+			return
+		}
+
+		err = tx.Commit()
+	}()
+
+	return fn(context.WithValue(ctx, txKey{}, tx))
+}
+
+// Executor returns the transaction carried by ctx, or the connection pool when there is none.
+// Data access code should run every query through it so it joins transactions started by WithinTx.
+func (db *DB) Executor(ctx context.Context) Executor {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+
+	return db.DB
+}
+
+// Transactional is a middleware that serves each request within a transaction of tm.
+//
+// The response is buffered and only sent once the outcome of the transaction is known: it is committed
+// when the handler responds with a status code below 400 and rolled back otherwise. Panics roll back the
+// transaction and are re-raised, so middleware.PanicRecoverer must wrap this middleware to respond to them.
+func Transactional(tm TxManager) middleware.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				bw := &bufferedWriter{header: http.Header{}, status: http.StatusOK}
+
+				err := tm.WithinTx(r.Context(), func(ctx context.Context) error {
+					next.ServeHTTP(bw, r.WithContext(ctx))
+
+					if bw.status >= http.StatusBadRequest {
+						return errRollback
+					}
+
+					return nil
+				})
+
+				if (err != nil) && !errors.Is(err, errRollback) {
+					log.Printf("transaction for %s %s failed: %s\n", r.Method, r.URL.Path, err)
+
+					json.Write(w, json.Response{
+						StatusCode: http.StatusInternalServerError,
+						Error:      json.ErrorFromErr(err, "", ""),
+					})
+					return
+				}
+
+				bw.flush(w)
+			},
+		)
+	}
+}
+
+// errRollback is returned from a transaction to roll it back without reporting an error.
+var errRollback = errors.New("rollback")
+
+// bufferedWriter is a http.ResponseWriter that holds the response until it is flushed.
+type bufferedWriter struct {
+	header      http.Header
+	status      int
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func (bw *bufferedWriter) Header() http.Header {
+	return bw.header
+}
+
+func (bw *bufferedWriter) Write(b []byte) (int, error) {
+	return bw.body.Write(b)
+}
+
+func (bw *bufferedWriter) WriteHeader(status int) {
+	if !bw.wroteHeader {
+		bw.status = status
+		bw.wroteHeader = true
+	}
+}
+
+// flush writes the buffered response to w.
+func (bw *bufferedWriter) flush(w http.ResponseWriter) {
+	for k, v := range bw.header {
+		w.Header()[k] = v
+	}
+
+	w.WriteHeader(bw.status)
+	w.Write(bw.body.Bytes())
+}
@@ -27,10 +27,41 @@ type Response struct {
 	// Data is the response data.
 	Data any `json:"data,omitempty"`
 
+	// Meta describes the page of list responses. this field is omitted from the response if it is nil.
+	Meta *Meta `json:"meta,omitempty"`
+
+	// Links are the urls of the pages of list responses. this field is omitted from the response if it is nil.
+	Links *Links `json:"links,omitempty"`
+
 	// Error is the response error. this field is omitted from the response if it is nil.
 	Error *Error `json:"error,omitempty"`
 }
 
+// Meta represent the pagination details of list responses.
+type Meta struct {
+	// Limit is the page size.
+	Limit int `json:"limit"`
+
+	// Offset is the number of items preceding offset paginated pages. this field is omitted for cursor paginated ones.
+	Offset int `json:"offset,omitempty"`
+
+	// Total is the number of items across all pages. this field is omitted unless it was requested.
+	Total *int `json:"total,omitempty"`
+
+	// NextCursor is the cursor of the next page. this field is omitted on the last page.
+	NextCursor string `json:"nextCursor,omitempty"`
+
+	// PrevCursor is the cursor of the previous page. this field is omitted on the first page.
+	PrevCursor string `json:"prevCursor,omitempty"`
+}
+
+// Links represent the urls of the pages of list responses.
+type Links struct {
+	Self string `json:"self"`
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
+
 // Error represent the response error object.
 type Error struct {
 	// Name is the name of the error.
@@ -44,6 +75,21 @@ type Error struct {
 
 	// Message is the error message
 	Message string `json:"message,omitempty"`
+
+	// Fields lists the invalid fields of requests failing validation. this field is omitted from the response if it is empty.
+	Fields []FieldError `json:"fields,omitempty"`
+}
+
+// FieldError represent an invalid field of a request.
+type FieldError struct {
+	// Field is the path of the field, e.g. "email" or "members[0].role".
+	Field string `json:"field"`
+
+	// Code is the machine-readable reason the field is invalid, e.g. "required".
+	Code string `json:"code"`
+
+	// Message describes the reason the field is invalid.
+	Message string `json:"message"`
 }
 
 func NewError(name string, msg string, cause string, stack string) *Error {
@@ -0,0 +1,154 @@
+// Package mail sends emails through a pluggable Sender.
+package mail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/huboh/go-rest-api/internal/pkg/env"
+	"github.com/huboh/go-rest-api/internal/pkg/utils"
+)
+
+// recognized senders
+const (
+	SenderFile = "file"
+)
+
+// ErrInvalidHeader is returned when sending a message whose addresses or subject are malformed, e.g.
+// contain line breaks that would inject headers.
+var ErrInvalidHeader = errors.New("invalid mail header")
+
+// defFileDir is the default directory of the FileSender.
+const defFileDir = "mail"
+
+// Message is a plain text email.
+type Message struct {
+	From    string
+	To      string
+	Subject string
+	Text    string
+}
+
+// Sender sends emails.
+type Sender interface {
+	// Send sends m. the sender's default address is used when m.From is empty.
+	Send(ctx context.Context, m Message) error
+}
+
+// NewSender creates the Sender selected by the MAIL_SENDER environment variable, "file" by default.
+func NewSender() (Sender, error) {
+	from := env.Get("MAIL_FROM")
+
+	switch s := env.Get("MAIL_SENDER"); s {
+	case "", SenderFile:
+		dir := env.Get("MAIL_FILE_DIR")
+
+		if dir == "" {
+			dir = defFileDir
+		}
+
+		return NewFileSender(dir, from), nil
+
+	default:
+		return nil, fmt.Errorf("unknown mail sender \"%s\"", s)
+	}
+}
+
+// FileSender is a Sender writing emails as .eml files in a directory instead of sending them.
+// it is meant for local development.
+type FileSender struct {
+	mu   sync.Mutex
+	dir  string
+	from string
+}
+
+// NewFileSender creates a FileSender writing emails from from in dir, created if missing.
+func NewFileSender(dir string, from string) *FileSender {
+	return &FileSender{dir: dir, from: from}
+}
+
+func (f *FileSender) Send(ctx context.Context, m Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if m.From == "" {
+		m.From = f.from
+	}
+
+	header, err := m.header()
+
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(f.dir, 0o755); err != nil {
+		return err
+	}
+
+	var (
+		now  = time.Now().UTC()
+		name = fmt.Sprintf("%s-%s.eml", now.Format("20060102T150405.000000000"), utils.NewUUID())
+		sb   strings.Builder
+	)
+
+	fmt.Fprintf(&sb, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	sb.WriteString(header)
+	fmt.Fprintf(&sb, "Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	sb.WriteString(m.Text)
+
+	return os.WriteFile(filepath.Join(f.dir, name), []byte(sb.String()), 0o644)
+}
+
+// header returns the From, To and Subject headers of m. the display names of its addresses and its subject
+// are encoded, and it returns ErrInvalidHeader when they contain control characters.
+func (m Message) header() (string, error) {
+	var sb strings.Builder
+
+	for _, h := range []struct{ name, value string }{{"From", m.From}, {"To", m.To}} {
+		if h.value == "" {
+			continue
+		}
+
+		addr, err := parseAddress(h.value)
+
+		if err != nil {
+			return "", fmt.Errorf("%w %s: %w", ErrInvalidHeader, h.name, err)
+		}
+
+		fmt.Fprintf(&sb, "%s: %s\r\n", h.name, addr)
+	}
+
+	if strings.ContainsFunc(m.Subject, unicode.IsControl) {
+		return "", fmt.Errorf("%w Subject: must not contain control characters", ErrInvalidHeader)
+	}
+
+	fmt.Fprintf(&sb, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+
+	return sb.String(), nil
+}
+
+// parseAddress parses s, a single address with or without a display name, and returns it formatted
+// for headers. it rejects control characters, e.g. line breaks.
+func parseAddress(s string) (string, error) {
+	if strings.ContainsFunc(s, unicode.IsControl) {
+		return "", errors.New("must not contain control characters")
+	}
+
+	addr, err := mail.ParseAddress(s)
+
+	if err != nil {
+		return "", err
+	}
+
+	// String encodes the display name
+	return addr.String(), nil
+}
@@ -0,0 +1,55 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileSenderSend(t *testing.T) {
+	var (
+		dir = t.TempDir()
+		f   = NewFileSender(dir, "Acme <noreply@example.com>")
+	)
+
+	err := f.Send(context.Background(), Message{To: "jane@example.com", Subject: "Bienvenue à Acme", Text: "hello"})
+
+	if err != nil {
+		t.Fatalf("Send() = %s", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+
+	if len(files) != 1 {
+		t.Fatalf("files = %v, want one email", files)
+	}
+
+	data, _ := os.ReadFile(files[0])
+
+	for _, want := range []string{
+		"From: \"Acme\" <noreply@example.com>\r\n",
+		"To: <jane@example.com>\r\n",
+		"Subject: =?utf-8?q?Bienvenue_=C3=A0_Acme?=\r\n",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("email = %q, want it to contain %q", data, want)
+		}
+	}
+}
+
+func TestFileSenderSendRejectsHeaderInjection(t *testing.T) {
+	f := NewFileSender(t.TempDir(), "")
+
+	for _, m := range []Message{
+		{To: "jane@example.com", Subject: "Hi\r\nBcc: eve@example.com"},
+		{To: "jane@example.com\r\nBcc: eve@example.com", Subject: "Hi"},
+		{To: "\"Jane\r\nBcc: eve@example.com\" <jane@example.com>", Subject: "Hi"},
+	} {
+		if err := f.Send(context.Background(), m); !errors.Is(err, ErrInvalidHeader) {
+			t.Errorf("Send(%q) = %v, want ErrInvalidHeader", m, err)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package migrate
+
+import (
+	"context"
+	"time"
+
+	"github.com/huboh/go-rest-api/internal/pkg/database"
+)
+
+// advisoryLockKey is the postgres advisory lock key held while migrating. it is an arbitrary
+// constant shared by every process migrating the same database.
+const advisoryLockKey = 7_361_428_911
+
+// tables create the tables used to track and lock migrations, keyed by driver.
+var tables = map[database.Driver][]string{
+	database.DriverSQLite: {
+		`CREATE TABLE IF NOT EXISTS schema_migrations (
+			version    INTEGER PRIMARY KEY,
+			name       TEXT NOT NULL,
+			checksum   TEXT NOT NULL,
+			applied_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS schema_migrations_lock (
+			id        INTEGER PRIMARY KEY CHECK (id = 1),
+			locked_at TIMESTAMP NOT NULL
+		)`,
+	},
+
+	database.DriverPostgres: {
+		`CREATE TABLE IF NOT EXISTS schema_migrations (
+			version    BIGINT PRIMARY KEY,
+			name       TEXT NOT NULL,
+			checksum   TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL
+		)`,
+	},
+}
+
+// ensureTables creates the migration tracking tables if they don't exist yet.
+func (m *Migrator) ensureTables(ctx context.Context) error {
+	for _, table := range tables[m.db.Driver] {
+		if _, err := m.db.ExecContext(ctx, table); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// lock acquires the migrations lock, returning ErrLocked when another process holds it.
+// The returned func releases the lock.
+//
+// Postgres uses a session level advisory lock, which is released even if the process dies.
+// SQLite has none, so a row in schema_migrations_lock is used instead; if a migration
+// crashed it may have to be deleted by hand.
+func (m *Migrator) lock(ctx context.Context) (func(), error) {
+	if m.db.Driver == database.DriverPostgres {
+		conn, err := m.db.Conn(ctx)
+
+		if err != nil {
+			return nil, err
+		}
+
+		var locked bool
+
+		if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", advisoryLockKey).Scan(&locked); err != nil {
+			conn.Close()
+			return nil, err
+		}
+
+		if !locked {
+			conn.Close()
+			return nil, ErrLocked
+		}
+
+		return func() {
+			conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", advisoryLockKey)
+			conn.Close()
+		}, nil
+	}
+
+	_, err := m.db.ExecContext(ctx, "INSERT INTO schema_migrations_lock (id, locked_at) VALUES (1, ?)", time.Now().UTC())
+
+	if database.IsUniqueViolation(err) {
+		return nil, ErrLocked
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return func() {
+		m.db.ExecContext(context.Background(), "DELETE FROM schema_migrations_lock WHERE id = 1")
+	}, nil
+}
@@ -0,0 +1,368 @@
+// Package migrate provides versioned SQL schema migrations.
+//
+// Migrations are read from a fs.FS holding pairs of files named "<version>_<name>.up.sql" and
+// "<version>_<name>.down.sql". Applied migrations are recorded, along with a checksum of their
+// up script, in the schema_migrations table.
+package migrate
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/huboh/go-rest-api/internal/pkg/database"
+)
+
+var (
+	// ErrLocked is returned when another process holds the migrations lock
+	ErrLocked = errors.New("migrations are locked by another process")
+
+	// ErrChecksumMismatch is returned when an applied migration was modified after it ran
+	ErrChecksumMismatch = errors.New("applied migration checksum mismatch")
+
+	// ErrUnknownVersion is returned when migrating to a version that doesn't exist
+	ErrUnknownVersion = errors.New("unknown migration version")
+)
+
+// fileRegexp matches migration file names, capturing the version, name and direction.
+var fileRegexp = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is a single versioned schema change.
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// Status represents the state of a Migration in the database.
+type Status struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"appliedAt,omitempty"`
+
+	// Modified reports whether the migration changed since it was applied.
+	Modified bool `json:"modified"`
+}
+
+// record is a row of the schema_migrations table.
+type record struct {
+	Version   int64
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// Migrator applies and reverts migrations on a database.
+type Migrator struct {
+	db         *database.DB
+	migrations []Migration
+}
+
+// New creates a Migrator for db using the migrations found in fsys.
+func New(db *database.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := load(fsys)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Up applies every pending migration.
+func (m *Migrator) Up(ctx context.Context) error {
+	if len(m.migrations) == 0 {
+		return nil
+	}
+
+	return m.Goto(ctx, m.migrations[len(m.migrations)-1].Version)
+}
+
+// Down reverts the last n applied migrations.
+func (m *Migrator) Down(ctx context.Context, n int) error {
+	return m.withLock(ctx, func(records []record) error {
+		for i := len(records) - 1; (i >= 0) && (n > 0); i, n = i-1, n-1 {
+			if err := m.revert(ctx, records[i]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Goto applies or reverts migrations until version is the last applied one.
+// A version of 0 reverts every migration.
+func (m *Migrator) Goto(ctx context.Context, version int64) error {
+	if (version != 0) && (m.find(version) < 0) {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+
+	return m.withLock(ctx, func(records []record) error {
+		// revert newer migrations, latest first
+		for i := len(records) - 1; i >= 0; i-- {
+			if records[i].Version <= version {
+				break
+			}
+
+			if err := m.revert(ctx, records[i]); err != nil {
+				return err
+			}
+		}
+
+		// apply missing older migrations, oldest first
+		for _, mig := range m.migrations {
+			if mig.Version > version {
+				break
+			}
+
+			if slices.ContainsFunc(records, func(r record) bool { return r.Version == mig.Version }) {
+				continue
+			}
+
+			if err := m.apply(ctx, mig); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Status returns the state of every known migration, and of applied migrations missing from the source.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	if err := m.ensureTables(ctx); err != nil {
+		return nil, err
+	}
+
+	records, err := m.records(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := []Status{}
+
+	for _, mig := range m.migrations {
+		s := Status{Version: mig.Version, Name: mig.Name}
+
+		if i := slices.IndexFunc(records, func(r record) bool { return r.Version == mig.Version }); i >= 0 {
+			s.Applied = true
+			s.AppliedAt = &records[i].AppliedAt
+			s.Modified = records[i].Checksum != mig.Checksum
+		}
+
+		statuses = append(statuses, s)
+	}
+
+	for _, r := range records {
+		if m.find(r.Version) < 0 {
+			statuses = append(statuses, Status{
+				Version:   r.Version,
+				Name:      r.Name,
+				Applied:   true,
+				AppliedAt: &r.AppliedAt,
+				Modified:  true,
+			})
+		}
+	}
+
+	slices.SortFunc(statuses, func(a, b Status) int { return cmp.Compare(a.Version, b.Version) })
+
+	return statuses, nil
+}
+
+// apply runs the up script of mig and records it in a single transaction.
+func (m *Migrator) apply(ctx context.Context, mig Migration) error {
+	log.Printf("applying migration %d_%s\n", mig.Version, mig.Name)
+
+	tx, err := m.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, mig.Up); err != nil {
+		return fmt.Errorf("migration %d_%s: %w", mig.Version, mig.Name, err)
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		m.db.Rebind("INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)"),
+		mig.Version, mig.Name, mig.Checksum, time.Now().UTC(),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// revert runs the down script of the applied migration r and removes its record in a single transaction.
+func (m *Migrator) revert(ctx context.Context, r record) error {
+	i := m.find(r.Version)
+
+	if i < 0 {
+		return fmt.Errorf("%w: no down script for applied migration %d_%s", ErrUnknownVersion, r.Version, r.Name)
+	}
+
+	mig := m.migrations[i]
+	log.Printf("reverting migration %d_%s\n", mig.Version, mig.Name)
+
+	tx, err := m.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, mig.Down); err != nil {
+		return fmt.Errorf("migration %d_%s: %w", mig.Version, mig.Name, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, m.db.Rebind("DELETE FROM schema_migrations WHERE version = ?"), mig.Version); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// withLock runs fn with the applied migration records while holding the migrations lock.
+// It refuses to run when an applied migration was modified since it ran.
+func (m *Migrator) withLock(ctx context.Context, fn func(records []record) error) error {
+	if err := m.ensureTables(ctx); err != nil {
+		return err
+	}
+
+	unlock, err := m.lock(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
+	records, err := m.records(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	for _, r := range records {
+		if i := m.find(r.Version); (i >= 0) && (m.migrations[i].Checksum != r.Checksum) {
+			return fmt.Errorf("%w: %d_%s", ErrChecksumMismatch, r.Version, r.Name)
+		}
+	}
+
+	return fn(records)
+}
+
+// records returns the applied migrations ordered by version.
+func (m *Migrator) records(ctx context.Context) ([]record, error) {
+	rows, err := m.db.QueryContext(ctx, "SELECT version, name, checksum, applied_at FROM schema_migrations ORDER BY version")
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	records := []record{}
+
+	for rows.Next() {
+		var r record
+
+		if err := rows.Scan(&r.Version, &r.Name, &r.Checksum, &r.AppliedAt); err != nil {
+			return nil, err
+		}
+
+		records = append(records, r)
+	}
+
+	return records, rows.Err()
+}
+
+// find returns the index of the migration with the given version, or -1.
+func (m *Migrator) find(version int64) int {
+	return slices.IndexFunc(m.migrations, func(mig Migration) bool { return mig.Version == version })
+}
+
+// load reads and pairs the migration files of fsys, ordered by version.
+func load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+
+	for _, entry := range entries {
+		matches := fileRegexp.FindStringSubmatch(entry.Name())
+
+		if entry.IsDir() || (matches == nil) {
+			continue
+		}
+
+		version, err := strconv.ParseInt(matches[1], 10, 64)
+
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := fs.ReadFile(fsys, entry.Name())
+
+		if err != nil {
+			return nil, err
+		}
+
+		mig, ok := byVersion[version]
+
+		if !ok {
+			mig = &Migration{Version: version, Name: matches[2]}
+			byVersion[version] = mig
+		}
+
+		if mig.Name != matches[2] {
+			return nil, fmt.Errorf("migration version %d is used by both %s and %s", version, mig.Name, matches[2])
+		}
+
+		if matches[3] == "up" {
+			sum := sha256.Sum256(data)
+			mig.Up = string(data)
+			mig.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			mig.Down = string(data)
+		}
+	}
+
+	migrations := []Migration{}
+
+	for _, mig := range byVersion {
+		if (mig.Up == "") || (mig.Down == "") {
+			return nil, fmt.Errorf("migration %d_%s must have both an up and a down script", mig.Version, mig.Name)
+		}
+
+		migrations = append(migrations, *mig)
+	}
+
+	slices.SortFunc(migrations, func(a, b Migration) int { return cmp.Compare(a.Version, b.Version) })
+
+	return migrations, nil
+}
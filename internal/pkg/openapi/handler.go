@@ -0,0 +1,47 @@
+package openapi
+
+import (
+	_ "embed"
+	encjson "encoding/json"
+	"html/template"
+	"net/http"
+	"sync"
+
+	"github.com/huboh/go-rest-api/internal/pkg/json"
+)
+
+//go:embed docs.html
+var docsHTML string
+
+// docsTemplate renders the docs page of the document served at a url
+var docsTemplate = template.Must(template.New("docs").Parse(docsHTML))
+
+// Handler returns the handler serving the document returned by generate as json. the document is
+// generated on the first request, so it can describe the router serving it.
+func Handler(generate func() (*Document, error)) http.Handler {
+	generateOnce := sync.OnceValues(generate)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		doc, err := generateOnce()
+
+		if err != nil {
+			json.Write(w, json.Response{
+				StatusCode: http.StatusInternalServerError,
+				Error:      json.ErrorFromErr(err, "", ""),
+			})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		encjson.NewEncoder(w).Encode(doc)
+	})
+}
+
+// DocsHandler returns the handler serving a Redoc page rendering the document served at specURL.
+// the page loads Redoc from its CDN, it is meant for local use.
+func DocsHandler(title string, specURL string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		docsTemplate.Execute(w, map[string]string{"Title": title, "SpecURL": specURL})
+	})
+}
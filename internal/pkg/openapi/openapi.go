@@ -0,0 +1,269 @@
+// Package openapi generates OpenAPI 3.1 documents describing the routes of a router.Router,
+// from the metadata of the routes and by reflecting over their request and response types.
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/huboh/go-rest-api/internal/pkg/json"
+	"github.com/huboh/go-rest-api/internal/pkg/router"
+)
+
+// Version is the OpenAPI version of generated documents.
+const Version = "3.1.0"
+
+// Document is an OpenAPI document.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info describes the api.
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem maps the lowercase methods of a path to their operation.
+type PathItem map[string]*Operation
+
+// Operation describes a route.
+type Operation struct {
+	Summary     string                `json:"summary,omitempty"`
+	OperationId string                `json:"operationId"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+}
+
+// Parameter describes a path, query or header parameter of an operation.
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody describes the request body of an operation.
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response describes a response of an operation.
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema of a body.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the schemas and security schemes referenced by the document.
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme describes a way requests are authenticated, named by the Security of routes.
+type SecurityScheme struct {
+	// Type is the scheme type, e.g. "http" or "apiKey".
+	Type string `json:"type"`
+
+	// Scheme is the http authorization scheme of "http" schemes, e.g. "bearer".
+	Scheme string `json:"scheme,omitempty"`
+
+	// BearerFormat hints at the format of bearer tokens, e.g. "JWT".
+	BearerFormat string `json:"bearerFormat,omitempty"`
+
+	// Name and In are the name and location of the parameter holding the key of "apiKey" schemes.
+	Name string `json:"name,omitempty"`
+	In   string `json:"in,omitempty"`
+
+	Description string `json:"description,omitempty"`
+}
+
+// envelope is the type every response body is wrapped in.
+var envelope = reflect.TypeOf(json.Response{})
+
+// Generate returns the document describing the routes of r. the schemes named by the Security
+// of routes must be in schemes.
+func Generate(r *router.Router, info Info, schemes map[string]SecurityScheme) (*Document, error) {
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   map[string]PathItem{},
+		Components: Components{
+			Schemas:         map[string]*Schema{},
+			SecuritySchemes: schemes,
+		},
+	}
+
+	var (
+		g         = &generator{schemas: doc.Components.Schemas}
+		errSchema = g.schema(envelope)
+	)
+
+	err := r.Walk(func(route router.RouteInfo) error {
+		if route.Method == "" {
+			return nil
+		}
+
+		op := g.operation(route, errSchema)
+
+		if len(route.Security) > 0 {
+			// requests must satisfy every scheme of the route
+			requirement := map[string][]string{}
+
+			for _, name := range route.Security {
+				if _, ok := schemes[name]; !ok {
+					return fmt.Errorf("openapi: unknown security scheme %q of %s %s", name, route.Method, route.Path)
+				}
+
+				requirement[name] = []string{}
+			}
+
+			op.Security = []map[string][]string{requirement}
+		}
+
+		path := openapiPath(route.Pattern)
+
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = PathItem{}
+		}
+
+		doc.Paths[path][strings.ToLower(route.Method)] = op
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// operation returns the operation describing route. errSchema is the schema of error responses.
+func (g *generator) operation(route router.RouteInfo, errSchema *Schema) *Operation {
+	op := &Operation{
+		Summary:     route.Summary,
+		OperationId: operationId(route),
+		Tags:        route.Tags,
+		Parameters:  []Parameter{},
+		Deprecated:  route.Deprecated,
+		Responses: map[string]Response{
+			"default": {
+				Description: "error",
+				Content:     jsonContent(errSchema),
+			},
+		},
+	}
+
+	for _, p := range route.PathParams {
+		op.Parameters = append(op.Parameters, Parameter{Name: p.Name, In: "path", Required: true, Schema: pathParamSchema(p)})
+	}
+
+	for _, p := range route.Params {
+		typ := p.Type
+
+		if typ == "" {
+			typ = "string"
+		}
+
+		op.Parameters = append(op.Parameters, Parameter{
+			Name:        p.Name,
+			In:          p.In,
+			Description: p.Description,
+			Required:    p.Required,
+			Schema:      &Schema{Type: typ},
+		})
+	}
+
+	if route.Request != nil {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  jsonContent(g.schema(reflect.TypeOf(route.Request))),
+		}
+	}
+
+	// responses are wrapped in the json.Response envelope, the route Response being its data
+	success := g.schema(envelope)
+
+	if route.Response != nil {
+		success = &Schema{
+			AllOf: []*Schema{
+				success,
+				{Type: "object", Properties: map[string]*Schema{"data": g.schema(reflect.TypeOf(route.Response))}},
+			},
+		}
+	}
+
+	op.Responses["200"] = Response{Description: "success", Content: jsonContent(success)}
+
+	return op
+}
+
+// pathParamSchema returns the schema of the values of the path parameter p.
+func pathParamSchema(p router.PathParam) *Schema {
+	switch p.Type {
+	case "int":
+		return &Schema{Type: "integer"}
+	case "uint":
+		return &Schema{Type: "integer", Minimum: new(float64)}
+	case "uuid":
+		return &Schema{Type: "string", Format: "uuid"}
+	}
+
+	if p.Pattern != "" {
+		return &Schema{Type: "string", Pattern: "^(?:" + p.Pattern + ")$"}
+	}
+
+	return &Schema{Type: "string"}
+}
+
+// openapiPath returns the OpenAPI path of a ServeMux pattern path, e.g. "/files/{path...}" becomes "/files/{path}".
+func openapiPath(pattern string) string {
+	pattern = strings.TrimSuffix(pattern, "{$}")
+	pattern = strings.ReplaceAll(pattern, "...}", "}")
+
+	if len(pattern) > 1 {
+		pattern = strings.TrimSuffix(pattern, "/")
+	}
+
+	return pattern
+}
+
+// operationId returns a unique id of route, e.g. "getUsersId" for "GET /users/{id}".
+func operationId(route router.RouteInfo) string {
+	var b strings.Builder
+
+	b.WriteString(strings.ToLower(route.Method))
+
+	for _, part := range strings.FieldsFunc(route.Pattern, func(r rune) bool { return !isAlnum(r) }) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+
+	return b.String()
+}
+
+// jsonContent returns the content of json bodies with schema s.
+func jsonContent(s *Schema) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: s}}
+}
+
+// isAlnum reports whether r is an ascii letter or digit.
+func isAlnum(r rune) bool {
+	return ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9')
+}
@@ -0,0 +1,251 @@
+package privacy
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrErasureNotFound is returned when the user has no pending erasure request
+	ErrErasureNotFound = errors.New("erasure request not found")
+
+	// ErrErasureExists is returned when requesting an erasure while one is pending
+	ErrErasureExists = errors.New("erasure already requested")
+)
+
+const (
+	// erasureBatchSize is the number of due erasures fetched at once.
+	erasureBatchSize = 100
+
+	// maxErasureRetryDelay is the maximum time between the attempts of failing erasures.
+	maxErasureRetryDelay = time.Hour * 24
+)
+
+// Erasure is a request of a user to erase its personal data.
+type Erasure struct {
+	UserId      string    `json:"userId"`
+	RequestedAt time.Time `json:"requestedAt"`
+
+	// EraseAt is the time the data is erased at, unless the request is canceled.
+	EraseAt time.Time `json:"eraseAt"`
+
+	// Attempts is the number of failed attempts at erasing the data.
+	Attempts int `json:"-"`
+
+	// NextAttemptAt is the time a failed erasure is retried at, zero until an attempt fails.
+	NextAttemptAt time.Time `json:"-"`
+}
+
+// ErasureStore persists erasure requests.
+type ErasureStore interface {
+	// Create stores e, or returns ErrErasureExists when the user already has a request.
+	Create(ctx context.Context, e Erasure) error
+
+	// Get returns the request of the user with the given id, or ErrErasureNotFound.
+	Get(ctx context.Context, userId string) (Erasure, error)
+
+	// Delete removes the request of the user with the given id, or returns ErrErasureNotFound.
+	Delete(ctx context.Context, userId string) error
+
+	// Retry records a failed attempt at erasing the data of the user with the given id, setting the
+	// Attempts and NextAttemptAt of its request. it returns ErrErasureNotFound when there is none.
+	Retry(ctx context.Context, userId string, attempts int, next time.Time) error
+
+	// Due returns up to limit requests to erase at or before at, the earliest first. requests whose
+	// NextAttemptAt is after at aren't due yet.
+	Due(ctx context.Context, at time.Time, limit int) ([]Erasure, error)
+}
+
+// MemoryErasureStore is an in-memory ErasureStore, mainly useful for tests and local development.
+type MemoryErasureStore struct {
+	mu       sync.Mutex
+	erasures map[string]Erasure
+}
+
+// NewMemoryErasureStore creates an empty MemoryErasureStore.
+func NewMemoryErasureStore() *MemoryErasureStore {
+	return &MemoryErasureStore{
+		erasures: map[string]Erasure{},
+	}
+}
+
+func (m *MemoryErasureStore) Create(ctx context.Context, e Erasure) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.erasures[e.UserId]; ok {
+		return ErrErasureExists
+	}
+
+	m.erasures[e.UserId] = e
+
+	return nil
+}
+
+func (m *MemoryErasureStore) Get(ctx context.Context, userId string) (Erasure, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.erasures[userId]
+
+	if !ok {
+		return Erasure{}, ErrErasureNotFound
+	}
+
+	return e, nil
+}
+
+func (m *MemoryErasureStore) Delete(ctx context.Context, userId string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.erasures[userId]; !ok {
+		return ErrErasureNotFound
+	}
+
+	delete(m.erasures, userId)
+
+	return nil
+}
+
+func (m *MemoryErasureStore) Retry(ctx context.Context, userId string, attempts int, next time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.erasures[userId]
+
+	if !ok {
+		return ErrErasureNotFound
+	}
+
+	e.Attempts = attempts
+	e.NextAttemptAt = next
+	m.erasures[userId] = e
+
+	return nil
+}
+
+func (m *MemoryErasureStore) Due(ctx context.Context, at time.Time, limit int) ([]Erasure, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	due := []Erasure{}
+
+	for _, e := range m.erasures {
+		if !e.EraseAt.After(at) && !e.NextAttemptAt.After(at) {
+			due = append(due, e)
+		}
+	}
+
+	slices.SortFunc(due, func(a, b Erasure) int { return a.EraseAt.Compare(b.EraseAt) })
+
+	return due[:min(len(due), max(limit, 0))], nil
+}
+
+// RequestErasure schedules the erasure of the personal data of the user with the given id once the
+// erasure delay is over. callers must have verified the identity of the user.
+func (s *Service) RequestErasure(ctx context.Context, userId string) (Erasure, error) {
+	now := time.Now().UTC()
+
+	e := Erasure{
+		UserId:      userId,
+		RequestedAt: now,
+		EraseAt:     now.Add(s.configs.ErasureDelay),
+	}
+
+	if err := s.erasures.Create(ctx, e); err != nil {
+		return Erasure{}, err
+	}
+
+	return e, nil
+}
+
+// GetErasure returns the pending erasure request of the user with the given id, or ErrErasureNotFound.
+func (s *Service) GetErasure(ctx context.Context, userId string) (Erasure, error) {
+	return s.erasures.Get(ctx, userId)
+}
+
+// CancelErasure cancels the pending erasure request of the user with the given id, or returns ErrErasureNotFound.
+func (s *Service) CancelErasure(ctx context.Context, userId string) error {
+	return s.erasures.Delete(ctx, userId)
+}
+
+// Run erases the data of due erasure requests every erasure interval until ctx is done.
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.configs.ErasureInterval)
+	defer ticker.Stop()
+
+	for {
+		if n, err := s.EraseDue(ctx); err != nil {
+			log.Printf("erasing personal data failed after %d users: %s\n", n, err)
+		} else if n > 0 {
+			log.Printf("erased the personal data of %d users\n", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// EraseDue erases the data of due erasure requests and returns the number of erased users.
+// requests are only removed once every provider succeeded. failed erasures are logged and retried
+// later, after a delay doubling with each attempt, so they don't hold back the requests due after them.
+func (s *Service) EraseDue(ctx context.Context) (int, error) {
+	var (
+		n   = 0
+		now = time.Now()
+	)
+
+	for {
+		// failed requests are retried after now, they aren't due again in this run
+		due, err := s.erasures.Due(ctx, now, erasureBatchSize)
+
+		if err != nil {
+			return n, err
+		}
+
+		for _, e := range due {
+			if err := s.Erase(ctx, e.UserId); err != nil {
+				attempts := e.Attempts + 1
+				next := now.Add(s.retryDelay(attempts))
+
+				log.Printf("erasing the personal data of user %s failed, attempt %d, retrying at %s: %s\n", e.UserId, attempts, next.Format(time.RFC3339), err)
+
+				if err := s.erasures.Retry(ctx, e.UserId, attempts, next); err != nil {
+					return n, err
+				}
+
+				continue
+			}
+
+			if err := s.erasures.Delete(ctx, e.UserId); err != nil {
+				return n, err
+			}
+
+			n++
+		}
+
+		if len(due) < erasureBatchSize {
+			return n, nil
+		}
+	}
+}
+
+// retryDelay returns the time to wait before the next attempt of an erasure that failed attempts times:
+// the erasure interval, doubled with each attempt up to maxErasureRetryDelay.
+func (s *Service) retryDelay(attempts int) time.Duration {
+	delay := max(s.configs.ErasureInterval, time.Second)
+
+	for i := 1; (i < attempts) && (delay < maxErasureRetryDelay); i++ {
+		delay *= 2
+	}
+
+	return min(delay, maxErasureRetryDelay)
+}
@@ -0,0 +1,127 @@
+package privacy
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/huboh/go-rest-api/internal/pkg/database"
+)
+
+// erasureColumns are the columns selected by every erasure query, in scanErasure order.
+const erasureColumns = "user_id, requested_at, erase_at, attempts, next_attempt_at"
+
+// SQLErasureStore is an ErasureStore backed by a SQL database.
+type SQLErasureStore struct {
+	db *database.DB
+}
+
+// NewSQLErasureStore creates a SQLErasureStore using db.
+func NewSQLErasureStore(db *database.DB) *SQLErasureStore {
+	return &SQLErasureStore{db: db}
+}
+
+func (s *SQLErasureStore) Create(ctx context.Context, e Erasure) error {
+	_, err := s.db.Executor(ctx).ExecContext(
+		ctx,
+		s.db.Rebind("INSERT INTO erasure_requests (user_id, requested_at, erase_at) VALUES (?, ?, ?)"),
+		e.UserId, e.RequestedAt.UTC(), e.EraseAt.UTC(),
+	)
+
+	if database.IsUniqueViolation(err) {
+		return ErrErasureExists
+	}
+
+	return err
+}
+
+func (s *SQLErasureStore) Get(ctx context.Context, userId string) (Erasure, error) {
+	e, err := scanErasure(s.db.Executor(ctx).QueryRowContext(
+		ctx,
+		s.db.Rebind("SELECT "+erasureColumns+" FROM erasure_requests WHERE user_id = ?"),
+		userId,
+	))
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return Erasure{}, ErrErasureNotFound
+	}
+
+	return e, err
+}
+
+func (s *SQLErasureStore) Delete(ctx context.Context, userId string) error {
+	res, err := s.db.Executor(ctx).ExecContext(ctx, s.db.Rebind("DELETE FROM erasure_requests WHERE user_id = ?"), userId)
+
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); (err == nil) && (n == 0) {
+		return ErrErasureNotFound
+	}
+
+	return err
+}
+
+func (s *SQLErasureStore) Retry(ctx context.Context, userId string, attempts int, next time.Time) error {
+	res, err := s.db.Executor(ctx).ExecContext(
+		ctx,
+		s.db.Rebind("UPDATE erasure_requests SET attempts = ?, next_attempt_at = ? WHERE user_id = ?"),
+		attempts, next.UTC(), userId,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); (err == nil) && (n == 0) {
+		return ErrErasureNotFound
+	}
+
+	return err
+}
+
+func (s *SQLErasureStore) Due(ctx context.Context, at time.Time, limit int) ([]Erasure, error) {
+	rows, err := s.db.Executor(ctx).QueryContext(
+		ctx,
+		s.db.Rebind("SELECT "+erasureColumns+" FROM erasure_requests WHERE erase_at <= ? AND (next_attempt_at IS NULL OR next_attempt_at <= ?) ORDER BY erase_at LIMIT ?"),
+		at.UTC(), at.UTC(), max(limit, 0),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	due := []Erasure{}
+
+	for rows.Next() {
+		e, err := scanErasure(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		due = append(due, e)
+	}
+
+	return due, rows.Err()
+}
+
+// scanErasure scans a row selected with erasureColumns into an Erasure.
+func scanErasure(row interface{ Scan(...any) error }) (Erasure, error) {
+	var (
+		e    Erasure
+		next sql.NullTime
+	)
+
+	if err := row.Scan(&e.UserId, &e.RequestedAt, &e.EraseAt, &e.Attempts, &next); err != nil {
+		return Erasure{}, err
+	}
+
+	e.NextAttemptAt = next.Time
+
+	return e, nil
+}
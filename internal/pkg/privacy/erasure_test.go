@@ -0,0 +1,173 @@
+package privacy
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/huboh/go-rest-api/internal/pkg/database"
+	"github.com/huboh/go-rest-api/internal/pkg/database/dbtest"
+)
+
+// testProvider is a Provider recording the users it erased, failing to erase those in failing.
+type testProvider struct {
+	erased  []string
+	failing map[string]bool
+}
+
+func (p *testProvider) Name() string {
+	return "test"
+}
+
+func (p *testProvider) Export(ctx context.Context, userId string) (any, error) {
+	return map[string]string{"id": userId}, nil
+}
+
+func (p *testProvider) Erase(ctx context.Context, userId string) error {
+	if p.failing[userId] {
+		return errors.New("erasure failed")
+	}
+
+	p.erased = append(p.erased, userId)
+
+	return nil
+}
+
+func TestEraseDue(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		now      = time.Now()
+		store    = NewMemoryErasureStore()
+		provider = &testProvider{failing: map[string]bool{"a": true}}
+		s        = NewService(NewMemoryExportStore(), store, &Configs{ErasureInterval: time.Minute})
+	)
+
+	s.Register(provider)
+
+	// "a" is due first and fails, it mustn't hold back "b"
+	for _, e := range []Erasure{
+		{UserId: "a", RequestedAt: now, EraseAt: now.Add(-time.Hour * 2)},
+		{UserId: "b", RequestedAt: now, EraseAt: now.Add(-time.Hour)},
+		{UserId: "c", RequestedAt: now, EraseAt: now.Add(time.Hour)},
+	} {
+		if err := store.Create(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	n, err := s.EraseDue(ctx)
+
+	if (err != nil) || (n != 1) || (len(provider.erased) != 1) || (provider.erased[0] != "b") {
+		t.Fatalf("EraseDue() = %d, %v, erased %v, want 1 erasure of b", n, err, provider.erased)
+	}
+
+	a, err := store.Get(ctx, "a")
+
+	if (err != nil) || (a.Attempts != 1) || !a.NextAttemptAt.After(now) {
+		t.Fatalf("failed erasure = %+v, %v, want 1 attempt retried later", a, err)
+	}
+
+	if _, err := store.Get(ctx, "b"); !errors.Is(err, ErrErasureNotFound) {
+		t.Errorf("erased request = %v, want ErrErasureNotFound", err)
+	}
+
+	// the failed erasure isn't retried before its next attempt
+	if n, err := s.EraseDue(ctx); (err != nil) || (n != 0) {
+		t.Errorf("EraseDue() before the next attempt = %d, %v, want 0", n, err)
+	}
+
+	if err := store.Retry(ctx, "a", a.Attempts, now); err != nil {
+		t.Fatal(err)
+	}
+
+	delete(provider.failing, "a")
+
+	if n, err := s.EraseDue(ctx); (err != nil) || (n != 1) {
+		t.Errorf("EraseDue() once the erasure is due again = %d, %v, want 1", n, err)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	s := NewService(nil, nil, &Configs{ErasureInterval: time.Minute * 10})
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Minute * 10},
+		{2, time.Minute * 20},
+		{4, time.Minute * 80},
+		{100, maxErasureRetryDelay},
+	}
+
+	for _, tt := range tests {
+		if got := s.retryDelay(tt.attempts); got != tt.want {
+			t.Errorf("retryDelay(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestMemoryErasureStore(t *testing.T) {
+	testErasureStore(t, NewMemoryErasureStore())
+}
+
+func TestSQLErasureStore(t *testing.T) {
+	dbtest.Run(t, func(t *testing.T, db *database.DB) {
+		testErasureStore(t, NewSQLErasureStore(db))
+	})
+}
+
+// testErasureStore checks that store meets the ErasureStore contract.
+func testErasureStore(t *testing.T, store ErasureStore) {
+	var (
+		ctx = context.Background()
+		now = time.Now().UTC().Truncate(time.Second)
+	)
+
+	for _, e := range []Erasure{
+		{UserId: "b", RequestedAt: now, EraseAt: now.Add(-time.Minute)},
+		{UserId: "a", RequestedAt: now, EraseAt: now.Add(-time.Hour)},
+		{UserId: "c", RequestedAt: now, EraseAt: now.Add(time.Hour)},
+	} {
+		if err := store.Create(ctx, e); err != nil {
+			t.Fatalf("Create() = %s", err)
+		}
+	}
+
+	if err := store.Create(ctx, Erasure{UserId: "a", RequestedAt: now, EraseAt: now}); !errors.Is(err, ErrErasureExists) {
+		t.Errorf("Create() of a second request = %v, want ErrErasureExists", err)
+	}
+
+	due, err := store.Due(ctx, now, 10)
+
+	if (err != nil) || (len(due) != 2) || (due[0].UserId != "a") || (due[1].UserId != "b") {
+		t.Fatalf("Due() = %+v, %v, want a then b", due, err)
+	}
+
+	if err := store.Retry(ctx, "a", 1, now.Add(time.Minute)); err != nil {
+		t.Fatalf("Retry() = %s", err)
+	}
+
+	if err := store.Retry(ctx, "d", 1, now); !errors.Is(err, ErrErasureNotFound) {
+		t.Errorf("Retry() of a missing request = %v, want ErrErasureNotFound", err)
+	}
+
+	if due, err := store.Due(ctx, now, 10); (err != nil) || (len(due) != 1) || (due[0].UserId != "b") {
+		t.Errorf("Due() with a request retried later = %+v, %v, want b", due, err)
+	}
+
+	a, err := store.Get(ctx, "a")
+
+	if (err != nil) || (a.Attempts != 1) || !a.NextAttemptAt.Equal(now.Add(time.Minute)) {
+		t.Errorf("Get() of a retried request = %+v, %v", a, err)
+	}
+
+	if err := store.Delete(ctx, "a"); err != nil {
+		t.Fatalf("Delete() = %s", err)
+	}
+
+	if err := store.Delete(ctx, "a"); !errors.Is(err, ErrErasureNotFound) {
+		t.Errorf("Delete() of a deleted request = %v, want ErrErasureNotFound", err)
+	}
+}
@@ -0,0 +1,238 @@
+package privacy
+
+import (
+	"context"
+	jsonEncoder "encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/huboh/go-rest-api/internal/pkg/utils"
+)
+
+var (
+	// ErrExportNotFound is returned when no export of the user matches a lookup
+	ErrExportNotFound = errors.New("export not found")
+
+	// ErrExportNotReady is returned when downloading an export whose archive isn't built
+	ErrExportNotReady = errors.New("export is not ready")
+)
+
+// ExportStatus is the state of an Export.
+type ExportStatus string
+
+// recognized ExportStatus
+const (
+	ExportPending = ExportStatus("pending")
+	ExportReady   = ExportStatus("ready")
+	ExportFailed  = ExportStatus("failed")
+)
+
+// Export is a request of a user to export its personal data.
+type Export struct {
+	Id        string       `json:"id"`
+	UserId    string       `json:"userId"`
+	Status    ExportStatus `json:"status"`
+	CreatedAt time.Time    `json:"createdAt"`
+	ExpiresAt time.Time    `json:"expiresAt"`
+
+	// Archive is the json encoded Archive, set once the export is ready.
+	Archive []byte `json:"-"`
+}
+
+// ExportStore persists exports.
+type ExportStore interface {
+	// Save stores e, replacing the export with the same id.
+	Save(ctx context.Context, e Export) error
+
+	// Get returns the unexpired export with the given id, or ErrExportNotFound.
+	Get(ctx context.Context, id string) (Export, error)
+
+	// DeleteByUser removes the exports of the user with the given id, if any.
+	DeleteByUser(ctx context.Context, userId string) error
+
+	// Pending returns the latest pending export of the user with the given id created after since, or
+	// ErrExportNotFound.
+	Pending(ctx context.Context, userId string, since time.Time) (Export, error)
+}
+
+// MemoryExportStore is an in-memory ExportStore. expired exports are removed as new ones are saved.
+type MemoryExportStore struct {
+	mu      sync.Mutex
+	exports map[string]Export
+}
+
+// NewMemoryExportStore creates an empty MemoryExportStore.
+func NewMemoryExportStore() *MemoryExportStore {
+	return &MemoryExportStore{
+		exports: map[string]Export{},
+	}
+}
+
+func (m *MemoryExportStore) Save(ctx context.Context, e Export) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+
+	for id, o := range m.exports {
+		if !o.ExpiresAt.After(now) {
+			delete(m.exports, id)
+		}
+	}
+
+	m.exports[e.Id] = e
+
+	return nil
+}
+
+func (m *MemoryExportStore) Get(ctx context.Context, id string) (Export, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.exports[id]
+
+	if !ok || !e.ExpiresAt.After(time.Now()) {
+		return Export{}, ErrExportNotFound
+	}
+
+	return e, nil
+}
+
+func (m *MemoryExportStore) DeleteByUser(ctx context.Context, userId string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, e := range m.exports {
+		if e.UserId == userId {
+			delete(m.exports, id)
+		}
+	}
+
+	return nil
+}
+
+func (m *MemoryExportStore) Pending(ctx context.Context, userId string, since time.Time) (Export, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var (
+		found  = false
+		now    = time.Now()
+		latest Export
+	)
+
+	for _, e := range m.exports {
+		if (e.UserId == userId) && (e.Status == ExportPending) && e.CreatedAt.After(since) && e.ExpiresAt.After(now) && (!found || e.CreatedAt.After(latest.CreatedAt)) {
+			found, latest = true, e
+		}
+	}
+
+	if !found {
+		return Export{}, ErrExportNotFound
+	}
+
+	return latest, nil
+}
+
+// RequestExport starts building the archive of the personal data of the user with the given id in the
+// background, and returns the pending export to poll with GetExport. the pending export of the user is
+// returned instead while its archive is being built, so repeated requests don't pile up builds.
+func (s *Service) RequestExport(ctx context.Context, userId string) (Export, error) {
+	s.exportMu.Lock()
+	defer s.exportMu.Unlock()
+
+	now := time.Now().UTC()
+
+	if e, err := s.exports.Pending(ctx, userId, now.Add(-exportTimeout)); err == nil {
+		return e, nil
+	} else if !errors.Is(err, ErrExportNotFound) {
+		return Export{}, err
+	}
+
+	e := Export{
+		Id:        utils.NewUUID(),
+		UserId:    userId,
+		Status:    ExportPending,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.configs.ExportTTL),
+	}
+
+	if err := s.exports.Save(ctx, e); err != nil {
+		return Export{}, err
+	}
+
+	// the export outlives the request it was created by
+	go s.buildExport(context.WithoutCancel(ctx), e)
+
+	return e, nil
+}
+
+// GetExport returns the export of the user with the given id, or ErrExportNotFound.
+func (s *Service) GetExport(ctx context.Context, userId string, id string) (Export, error) {
+	e, err := s.exports.Get(ctx, id)
+
+	if err != nil {
+		return Export{}, err
+	}
+
+	if e.UserId != userId {
+		return Export{}, ErrExportNotFound
+	}
+
+	return e, nil
+}
+
+// DownloadExport returns the ready export of the user with the given id, or ErrExportNotFound
+// and ErrExportNotReady.
+func (s *Service) DownloadExport(ctx context.Context, userId string, id string) (Export, error) {
+	e, err := s.GetExport(ctx, userId, id)
+
+	if err != nil {
+		return Export{}, err
+	}
+
+	if e.Status != ExportReady {
+		return Export{}, ErrExportNotReady
+	}
+
+	return e, nil
+}
+
+// buildExport builds and saves the archive of e, once fewer than maxExportBuilds archives are being built.
+// it fails when the archive isn't built within exportTimeout of the request, waiting included.
+func (s *Service) buildExport(ctx context.Context, e Export) {
+	ctx, cancel := context.WithDeadline(ctx, e.CreatedAt.Add(exportTimeout))
+	defer cancel()
+
+	archive, err := s.buildArchive(ctx, e.UserId)
+
+	if err == nil {
+		e.Archive, err = jsonEncoder.MarshalIndent(archive, "", "  ")
+	}
+
+	if err != nil {
+		log.Printf("export %s failed: %s\n", e.Id, err)
+		e.Status = ExportFailed
+	} else {
+		e.Status = ExportReady
+	}
+
+	if err := s.exports.Save(ctx, e); err != nil {
+		log.Printf("saving export %s failed: %s\n", e.Id, err)
+	}
+}
+
+// buildArchive collects the archive of the user with the given id, see archive, waiting for its turn
+// among the builds until ctx is done.
+func (s *Service) buildArchive(ctx context.Context, userId string) (*Archive, error) {
+	select {
+	case s.builds <- struct{}{}:
+		defer func() { <-s.builds }()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	return s.archive(ctx, userId)
+}
@@ -0,0 +1,80 @@
+package privacy
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/huboh/go-rest-api/internal/pkg/database"
+)
+
+// exportColumns are the columns selected by every export query, in scan order.
+const exportColumns = "id, user_id, status, archive, created_at, expires_at"
+
+// SQLExportStore is an ExportStore backed by a SQL database, sharing exports between the instances
+// of the app and across restarts. expired exports are removed as new ones are saved.
+type SQLExportStore struct {
+	db *database.DB
+}
+
+// NewSQLExportStore creates a SQLExportStore using db.
+func NewSQLExportStore(db *database.DB) *SQLExportStore {
+	return &SQLExportStore{db: db}
+}
+
+func (s *SQLExportStore) Save(ctx context.Context, e Export) error {
+	exec := s.db.Executor(ctx)
+
+	if _, err := exec.ExecContext(ctx, s.db.Rebind("DELETE FROM exports WHERE expires_at <= ?"), time.Now().UTC()); err != nil {
+		return err
+	}
+
+	_, err := exec.ExecContext(
+		ctx,
+		s.db.Rebind(
+			"INSERT INTO exports ("+exportColumns+") VALUES (?, ?, ?, ?, ?, ?) "+
+				"ON CONFLICT (id) DO UPDATE SET status = excluded.status, archive = excluded.archive, expires_at = excluded.expires_at",
+		),
+		e.Id, e.UserId, e.Status, e.Archive, e.CreatedAt.UTC(), e.ExpiresAt.UTC(),
+	)
+
+	return err
+}
+
+func (s *SQLExportStore) Get(ctx context.Context, id string) (Export, error) {
+	e := Export{}
+
+	err := s.db.Executor(ctx).QueryRowContext(
+		ctx,
+		s.db.Rebind("SELECT "+exportColumns+" FROM exports WHERE id = ? AND expires_at > ?"),
+		id, time.Now().UTC(),
+	).Scan(&e.Id, &e.UserId, &e.Status, &e.Archive, &e.CreatedAt, &e.ExpiresAt)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return Export{}, ErrExportNotFound
+	}
+
+	return e, err
+}
+
+func (s *SQLExportStore) DeleteByUser(ctx context.Context, userId string) error {
+	_, err := s.db.Executor(ctx).ExecContext(ctx, s.db.Rebind("DELETE FROM exports WHERE user_id = ?"), userId)
+	return err
+}
+
+func (s *SQLExportStore) Pending(ctx context.Context, userId string, since time.Time) (Export, error) {
+	e := Export{}
+
+	err := s.db.Executor(ctx).QueryRowContext(
+		ctx,
+		s.db.Rebind("SELECT "+exportColumns+" FROM exports WHERE user_id = ? AND status = ? AND created_at > ? AND expires_at > ? ORDER BY created_at DESC LIMIT 1"),
+		userId, ExportPending, since.UTC(), time.Now().UTC(),
+	).Scan(&e.Id, &e.UserId, &e.Status, &e.Archive, &e.CreatedAt, &e.ExpiresAt)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return Export{}, ErrExportNotFound
+	}
+
+	return e, err
+}
@@ -0,0 +1,174 @@
+package privacy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/huboh/go-rest-api/internal/pkg/database"
+	"github.com/huboh/go-rest-api/internal/pkg/database/dbtest"
+)
+
+func TestMemoryExportStore(t *testing.T) {
+	testExportStore(t, NewMemoryExportStore())
+}
+
+func TestSQLExportStore(t *testing.T) {
+	dbtest.Run(t, func(t *testing.T, db *database.DB) {
+		testExportStore(t, NewSQLExportStore(db))
+	})
+}
+
+// testExportStore checks that store meets the ExportStore contract.
+func testExportStore(t *testing.T, store ExportStore) {
+	var (
+		ctx = context.Background()
+		now = time.Now().UTC().Truncate(time.Second)
+		e   = Export{Id: "1", UserId: "jane", Status: ExportPending, CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+	)
+
+	if err := store.Save(ctx, e); err != nil {
+		t.Fatalf("Save() = %s", err)
+	}
+
+	if got, err := store.Pending(ctx, "jane", now.Add(-time.Minute)); (err != nil) || (got.Id != e.Id) {
+		t.Fatalf("Pending() = %+v, %v, want the pending export", got, err)
+	}
+
+	if _, err := store.Pending(ctx, "jane", now); !errors.Is(err, ErrExportNotFound) {
+		t.Errorf("Pending() of exports created before since = %v, want ErrExportNotFound", err)
+	}
+
+	if _, err := store.Pending(ctx, "john", now.Add(-time.Minute)); !errors.Is(err, ErrExportNotFound) {
+		t.Errorf("Pending() of another user = %v, want ErrExportNotFound", err)
+	}
+
+	e.Status = ExportReady
+	e.Archive = []byte(`{"userId":"jane"}`)
+
+	if err := store.Save(ctx, e); err != nil {
+		t.Fatalf("Save() of a built export = %s", err)
+	}
+
+	if _, err := store.Pending(ctx, "jane", now.Add(-time.Minute)); !errors.Is(err, ErrExportNotFound) {
+		t.Errorf("Pending() of a built export = %v, want ErrExportNotFound", err)
+	}
+
+	got, err := store.Get(ctx, e.Id)
+
+	if (err != nil) || (got.Status != ExportReady) || (string(got.Archive) != string(e.Archive)) || (got.UserId != "jane") {
+		t.Fatalf("Get() = %+v, %v, want the built export", got, err)
+	}
+
+	expired := Export{Id: "2", UserId: "jane", Status: ExportReady, CreatedAt: now.Add(-time.Hour * 2), ExpiresAt: now.Add(-time.Hour)}
+
+	if err := store.Save(ctx, expired); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Get(ctx, expired.Id); !errors.Is(err, ErrExportNotFound) {
+		t.Errorf("Get() of an expired export = %v, want ErrExportNotFound", err)
+	}
+
+	if err := store.DeleteByUser(ctx, "jane"); err != nil {
+		t.Fatalf("DeleteByUser() = %s", err)
+	}
+
+	if _, err := store.Get(ctx, e.Id); !errors.Is(err, ErrExportNotFound) {
+		t.Errorf("Get() of a deleted export = %v, want ErrExportNotFound", err)
+	}
+}
+
+// blockingProvider is a Provider whose exports wait for release, recording the most exports running at once.
+type blockingProvider struct {
+	mu      sync.Mutex
+	running int
+	most    int
+	release chan struct{}
+}
+
+func (p *blockingProvider) Name() string {
+	return "blocking"
+}
+
+func (p *blockingProvider) Export(ctx context.Context, userId string) (any, error) {
+	p.mu.Lock()
+	p.running++
+	p.most = max(p.most, p.running)
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		p.running--
+		p.mu.Unlock()
+	}()
+
+	select {
+	case <-p.release:
+		return userId, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (p *blockingProvider) Erase(ctx context.Context, userId string) error {
+	return nil
+}
+
+func TestRequestExport(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		exports  = NewMemoryExportStore()
+		provider = &blockingProvider{release: make(chan struct{})}
+		s        = NewService(exports, NewMemoryErasureStore(), &Configs{ExportTTL: time.Hour})
+	)
+
+	s.Register(provider)
+
+	first, err := s.RequestExport(ctx, "jane")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the pending export is reused rather than built again
+	if again, err := s.RequestExport(ctx, "jane"); (err != nil) || (again.Id != first.Id) {
+		t.Fatalf("RequestExport() while pending = %+v, %v, want export %s", again, err, first.Id)
+	}
+
+	ids := []string{first.Id}
+
+	for i := 0; i < maxExportBuilds*2; i++ {
+		e, err := s.RequestExport(ctx, fmt.Sprintf("user%d", i))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ids = append(ids, e.Id)
+	}
+
+	// let every build start that can
+	time.Sleep(time.Millisecond * 50)
+	close(provider.release)
+
+	for _, id := range ids {
+		for e, _ := exports.Get(ctx, id); e.Status == ExportPending; e, _ = exports.Get(ctx, id) {
+			time.Sleep(time.Millisecond)
+		}
+
+		if e, err := exports.Get(ctx, id); (err != nil) || (e.Status != ExportReady) {
+			t.Errorf("export %s = %+v, %v, want it ready", id, e, err)
+		}
+	}
+
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	if provider.most != maxExportBuilds {
+		t.Errorf("most builds at once = %d, want %d", provider.most, maxExportBuilds)
+	}
+}
@@ -0,0 +1,163 @@
+// Package privacy implements the rights of users over the personal data the app holds on them:
+// exporting it as a downloadable archive and erasing it.
+//
+// App packages storing personal data implement Provider and register it with the Service, which
+// runs exports in the background and erases data once the delay of an erasure request is over,
+// giving users a chance to change their mind.
+package privacy
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/huboh/go-rest-api/internal/pkg/env"
+	"github.com/huboh/go-rest-api/internal/pkg/utils"
+)
+
+const (
+	// defExportTTL is the default time export archives can be downloaded for.
+	defExportTTL = time.Hour * 24
+
+	// defErasureDelay is the default time between an erasure request and the erasure.
+	defErasureDelay = time.Hour * 72
+
+	// defErasureInterval is the default time between checks for due erasures.
+	defErasureInterval = time.Minute * 10
+
+	// exportTimeout is the maximum time taken to build an archive, from the export request. pending exports
+	// older than that were abandoned, e.g. by a restart.
+	exportTimeout = time.Minute
+
+	// maxExportBuilds is the maximum number of archives built at once, others waiting for their turn.
+	maxExportBuilds = 4
+)
+
+// Provider is implemented by the app packages storing personal data.
+type Provider interface {
+	// Name identifies the provider. it is the key of its data in archives.
+	Name() string
+
+	// Export returns the personal data held on the user with the given id. it must be json serializable.
+	Export(ctx context.Context, userId string) (any, error)
+
+	// Erase removes or anonymizes the personal data held on the user with the given id.
+	// it must succeed when there is no data left, so failed erasures can be retried.
+	Erase(ctx context.Context, userId string) error
+}
+
+// Archive holds the personal data of a user, by provider name.
+type Archive struct {
+	UserId    string         `json:"userId"`
+	CreatedAt time.Time      `json:"createdAt"`
+	Data      map[string]any `json:"data"`
+}
+
+// Configs holds the settings of a Service.
+type Configs struct {
+	// ExportTTL is the time export archives can be downloaded for.
+	ExportTTL time.Duration
+
+	// ErasureDelay is the time between an erasure request and the erasure.
+	ErasureDelay time.Duration
+
+	// ErasureInterval is the time between checks for due erasures.
+	ErasureInterval time.Duration
+}
+
+// NewConfigs initializes a new Configs instance by reading environment variables,
+// falling back to defaults when they are unset.
+func NewConfigs() *Configs {
+	c := &Configs{
+		ExportTTL:       defExportTTL,
+		ErasureDelay:    defErasureDelay,
+		ErasureInterval: defErasureInterval,
+	}
+
+	if v := env.Get("PRIVACY_EXPORT_TTL"); v != "" {
+		c.ExportTTL = utils.Must(time.ParseDuration(v))
+	}
+
+	if v := env.Get("PRIVACY_ERASURE_DELAY"); v != "" {
+		c.ErasureDelay = utils.Must(time.ParseDuration(v))
+	}
+
+	if v := env.Get("PRIVACY_ERASURE_INTERVAL"); v != "" {
+		c.ErasureInterval = utils.Must(time.ParseDuration(v))
+	}
+
+	return c
+}
+
+// Service exports and erases the personal data held by the registered providers.
+type Service struct {
+	mu        sync.RWMutex
+	providers []Provider
+	exports   ExportStore
+	erasures  ErasureStore
+	configs   *Configs
+
+	// exportMu serializes export requests, so users can't start several builds at once
+	exportMu sync.Mutex
+
+	// builds holds a value per archive being built, bounding them to maxExportBuilds
+	builds chan struct{}
+}
+
+// NewService creates a Service keeping export archives in exports and erasure requests in erasures.
+func NewService(exports ExportStore, erasures ErasureStore, configs *Configs) *Service {
+	return &Service{
+		exports:  exports,
+		erasures: erasures,
+		configs:  configs,
+		builds:   make(chan struct{}, maxExportBuilds),
+	}
+}
+
+// Register registers p. providers are erased in registration order.
+func (s *Service) Register(p Provider) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.providers = append(s.providers, p)
+}
+
+// archive collects the personal data of the user with the given id from every provider.
+func (s *Service) archive(ctx context.Context, userId string) (*Archive, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	a := &Archive{UserId: userId, CreatedAt: time.Now().UTC(), Data: map[string]any{}}
+
+	for _, p := range s.providers {
+		data, err := p.Export(ctx, userId)
+
+		if err != nil {
+			return nil, fmt.Errorf("exporting %s data: %w", p.Name(), err)
+		}
+
+		a.Data[p.Name()] = data
+	}
+
+	return a, nil
+}
+
+// Erase erases the personal data of the user with the given id from every provider right away, along
+// with the archives of its exports. it is used by due erasure requests, and by the purges of deleted accounts.
+func (s *Service) Erase(ctx context.Context, userId string) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, p := range s.providers {
+		if err := p.Erase(ctx, userId); err != nil {
+			return fmt.Errorf("erasing %s data: %w", p.Name(), err)
+		}
+	}
+
+	if err := s.exports.DeleteByUser(ctx, userId); err != nil {
+		return fmt.Errorf("deleting exports: %w", err)
+	}
+
+	return nil
+}
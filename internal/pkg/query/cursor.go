@@ -0,0 +1,122 @@
+package query
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	jsonEncoder "encoding/json"
+	"strings"
+)
+
+// Cursor is the position of a page.
+type Cursor struct {
+	// Values are the sort values of the item the page starts after, or ends before.
+	Values []any
+
+	// Before reports whether the page ends before the item, i.e. it is a previous page.
+	Before bool
+}
+
+// cursorPayload is the signed content of an encoded Cursor.
+type cursorPayload struct {
+	Values []string `json:"v"`
+	Before bool     `json:"b,omitempty"`
+
+	// Query is the fingerprint of the sort and filters the cursor was issued for.
+	Query string `json:"q"`
+}
+
+// cursorCodec encodes and decodes signed cursors.
+type cursorCodec struct {
+	secret []byte
+}
+
+// encode encodes c as "<payload>.<signature>", binding it to the sort and filters of p.
+func (cc *cursorCodec) encode(c Cursor, p *Params) string {
+	payload := cursorPayload{Before: c.Before, Query: fingerprint(p)}
+
+	for _, v := range c.Values {
+		payload.Values = append(payload.Values, formatValue(v))
+	}
+
+	data, _ := jsonEncoder.Marshal(payload)
+	enc := base64.RawURLEncoding.EncodeToString(data)
+
+	return enc + "." + base64.RawURLEncoding.EncodeToString(cc.sign(enc))
+}
+
+// decode decodes a cursor encoded for the same sort and filters as p.
+func (cc *cursorCodec) decode(s string, p *Params) (*Cursor, error) {
+	enc, sig, ok := strings.Cut(s, ".")
+
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+
+	rawSig, err := base64.RawURLEncoding.DecodeString(sig)
+
+	if (err != nil) || !hmac.Equal(rawSig, cc.sign(enc)) {
+		return nil, ErrInvalidCursor
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(enc)
+
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	payload := cursorPayload{}
+
+	if err := jsonEncoder.Unmarshal(data, &payload); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	if (payload.Query != fingerprint(p)) || (len(payload.Values) != len(p.Sort)) {
+		return nil, ErrInvalidCursor
+	}
+
+	c := &Cursor{Before: payload.Before}
+
+	for i, v := range payload.Values {
+		val, err := parseValue(p.Sort[i].Field.Type, v)
+
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+
+		c.Values = append(c.Values, val)
+	}
+
+	return c, nil
+}
+
+// sign returns the signature of s.
+func (cc *cursorCodec) sign(s string) []byte {
+	mac := hmac.New(sha256.New, cc.secret)
+	mac.Write([]byte(s))
+
+	return mac.Sum(nil)
+}
+
+// fingerprint returns a short digest of the sort and filters of p.
+func fingerprint(p *Params) string {
+	var sb strings.Builder
+
+	for _, s := range p.Sort {
+		sb.WriteString(s.Field.Name)
+
+		if s.Desc {
+			sb.WriteString("-")
+		}
+
+		sb.WriteString(",")
+	}
+
+	for _, f := range p.Filters {
+		sb.WriteString(f.Field.Name + "[" + string(f.Op) + "]=" + formatValue(f.Value) + "&")
+	}
+
+	sum := sha256.Sum256([]byte(sb.String()))
+
+	return base64.RawURLEncoding.EncodeToString(sum[:8])
+}
@@ -0,0 +1,80 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/huboh/go-rest-api/internal/pkg/router"
+)
+
+// operatorDocs describes the comparisons of each Operator
+var operatorDocs = map[Operator]string{
+	OpEq:   "equals",
+	OpNe:   "doesn't equal",
+	OpGt:   "is greater than",
+	OpGte:  "is greater than or equal to",
+	OpLt:   "is less than",
+	OpLte:  "is less than or equal to",
+	OpLike: "contains, case insensitively,",
+}
+
+// Parameters describes the query parameters accepted according to s, for the api docs of list routes.
+func (s Spec) Parameters() []router.Parameter {
+	sortable := []string{}
+
+	for _, f := range s.Fields {
+		if f.Sortable {
+			sortable = append(sortable, f.Name)
+		}
+	}
+
+	params := []router.Parameter{
+		{
+			Name:        "limit",
+			In:          "query",
+			Type:        "integer",
+			Description: fmt.Sprintf("the page size, %d by default and at most %d", s.DefaultLimit, s.MaxLimit),
+		},
+		{
+			Name:        "cursor",
+			In:          "query",
+			Description: "the next or previous cursor of a page returned by the same query",
+		},
+		{
+			Name:        "sort",
+			In:          "query",
+			Description: fmt.Sprintf("comma separated fields to sort by, prefixed with \"-\" to sort descending: %s. defaults to %s", strings.Join(sortable, ", "), s.DefaultSort),
+		},
+		{
+			Name:        "total",
+			In:          "query",
+			Type:        "boolean",
+			Description: "counts every item matching the filters",
+		},
+	}
+
+	for _, f := range s.Fields {
+		for _, op := range f.Operators {
+			name := fmt.Sprintf("filter[%s]", f.Name)
+
+			if op != OpEq {
+				name += fmt.Sprintf("[%s]", op)
+			}
+
+			typ := "string"
+
+			if f.Type == FieldInt {
+				typ = "integer"
+			}
+
+			params = append(params, router.Parameter{
+				Name:        name,
+				In:          "query",
+				Type:        typ,
+				Description: fmt.Sprintf("keeps items whose %s %s the value", f.Name, operatorDocs[op]),
+			})
+		}
+	}
+
+	return params
+}
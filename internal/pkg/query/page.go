@@ -0,0 +1,73 @@
+package query
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/huboh/go-rest-api/internal/pkg/json"
+)
+
+// NewPage returns the response meta and links of the page of items fetched for p from the request r.
+// hasMore is the one returned by Slice or Select along with items.
+func NewPage[T any](r *http.Request, p *Params, items []T, hasMore bool, value ValueFunc[T]) (*json.Meta, *json.Links) {
+	var (
+		meta    = &json.Meta{Limit: p.Limit}
+		links   = &json.Links{Self: r.URL.RequestURI()}
+		forward = (p.Cursor == nil) || !p.Cursor.Before
+	)
+
+	if len(items) == 0 {
+		return meta, links
+	}
+
+	// a previous page was just left when going forward, and a next one when going backward
+	hasNext := hasMore || !forward
+	hasPrev := (hasMore && !forward) || (forward && (p.Cursor != nil))
+
+	if hasNext {
+		meta.NextCursor = p.codec.encode(Cursor{Values: sortValues(p.Sort, items[len(items)-1], value)}, p)
+		links.Next = withCursor(r, meta.NextCursor)
+	}
+
+	if hasPrev {
+		meta.PrevCursor = p.codec.encode(Cursor{Values: sortValues(p.Sort, items[0], value), Before: true}, p)
+		links.Prev = withCursor(r, meta.PrevCursor)
+	}
+
+	return meta, links
+}
+
+// NewOffsetPage returns the response meta and links of a page of limit items starting at offset, out
+// of total items. it is meant for results that can't be keyset paginated, such as ranked search hits.
+func NewOffsetPage(r *http.Request, limit int, offset int, total int) (*json.Meta, *json.Links) {
+	var (
+		meta  = &json.Meta{Limit: limit, Offset: offset, Total: &total}
+		links = &json.Links{Self: r.URL.RequestURI()}
+	)
+
+	if offset+limit < total {
+		links.Next = withParam(r, "offset", strconv.Itoa(offset+limit))
+	}
+
+	if offset > 0 {
+		links.Prev = withParam(r, "offset", strconv.Itoa(max(offset-limit, 0)))
+	}
+
+	return meta, links
+}
+
+// withCursor returns the request uri of r with its cursor parameter replaced by cursor.
+func withCursor(r *http.Request, cursor string) string {
+	return withParam(r, "cursor", cursor)
+}
+
+// withParam returns the request uri of r with its key parameter replaced by val.
+func withParam(r *http.Request, key string, val string) string {
+	u := *r.URL
+	q := u.Query()
+
+	q.Set(key, val)
+	u.RawQuery = q.Encode()
+
+	return u.RequestURI()
+}
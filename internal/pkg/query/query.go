@@ -0,0 +1,336 @@
+// Package query parses the pagination, sorting and filtering parameters of list requests.
+//
+// List requests accept the following query parameters:
+//
+//	limit=20                      the page size
+//	cursor=<cursor>               the next or previous cursor of a page returned by the same query
+//	sort=-createdAt,name          comma separated fields to sort by, prefixed with "-" to sort descending
+//	filter[email]=a@b.c           keeps items whose field equals a value
+//	filter[name][like]=jo         keeps items whose field matches a value using an operator
+//	total=true                    counts every item matching the filters
+//
+// Pages are fetched using keyset pagination: cursors hold the sort values of the first or last
+// item of a page and are signed, so clients can't forge or reuse them with a different query.
+package query
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/huboh/go-rest-api/internal/pkg/env"
+)
+
+var (
+	// ErrInvalidQuery is returned when a list request has invalid query parameters
+	ErrInvalidQuery = errors.New("invalid query")
+
+	// ErrInvalidCursor is returned when a cursor is malformed, forged or was issued for another query
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// filterRegexp matches filter parameter keys, capturing the field and the optional operator.
+var filterRegexp = regexp.MustCompile(`^filter\[(\w+)\](?:\[(\w+)\])?$`)
+
+// FieldType is the type of the values of a Field.
+type FieldType string
+
+// recognized FieldType
+const (
+	FieldString = FieldType("string")
+	FieldInt    = FieldType("int")
+	FieldTime   = FieldType("time")
+)
+
+// Operator compares the value of a field with the value of a Filter.
+type Operator string
+
+// recognized Operator
+const (
+	OpEq  = Operator("eq")
+	OpNe  = Operator("ne")
+	OpGt  = Operator("gt")
+	OpGte = Operator("gte")
+	OpLt  = Operator("lt")
+	OpLte = Operator("lte")
+
+	// OpLike is a case insensitive substring match of string fields.
+	OpLike = Operator("like")
+)
+
+// Field is a field of the listed items that can be sorted or filtered by.
+type Field struct {
+	// Name is the name of the field in query parameters.
+	Name string
+
+	// Column is the sql column of the field.
+	Column string
+
+	// Type is the type of the field values.
+	Type FieldType
+
+	// Sortable reports whether items can be sorted by the field.
+	Sortable bool
+
+	// Operators are the operators the field can be filtered with. the field can't be filtered when empty.
+	Operators []Operator
+}
+
+// Spec describes the parameters accepted by a list endpoint.
+type Spec struct {
+	Fields []Field
+
+	// Key is the name of a sortable field with unique values. it is appended to every sort so items
+	// with equal sort values are still consistently ordered.
+	Key string
+
+	// DefaultSort is the sort used when the sort parameter is unset.
+	DefaultSort string
+
+	// DefaultLimit is the page size used when the limit parameter is unset.
+	DefaultLimit int
+
+	// MaxLimit is the maximum page size.
+	MaxLimit int
+}
+
+// Sort is a field to sort by.
+type Sort struct {
+	Field Field
+	Desc  bool
+}
+
+// Filter keeps the items whose field value compares to Value using Op.
+type Filter struct {
+	Field Field
+	Op    Operator
+	Value any
+}
+
+// Params are the parsed parameters of a list request.
+type Params struct {
+	// Limit is the page size.
+	Limit int
+
+	// Sort are the fields to sort by. it always ends with the Spec key.
+	Sort []Sort
+
+	// Filters are the filters items must all match.
+	Filters []Filter
+
+	// Cursor is the position of the page. it is nil for the first page.
+	Cursor *Cursor
+
+	// Total reports whether the total count of items matching the filters was requested.
+	Total bool
+
+	codec *cursorCodec
+}
+
+// Parser parses the query parameters of list requests according to a Spec.
+type Parser struct {
+	spec  Spec
+	codec *cursorCodec
+}
+
+// NewParser creates a Parser for spec. cursors are signed with the QUERY_CURSOR_SECRET environment variable.
+func NewParser(spec Spec) *Parser {
+	return &Parser{
+		spec:  spec,
+		codec: &cursorCodec{secret: []byte(env.MustGet("QUERY_CURSOR_SECRET"))},
+	}
+}
+
+// NewParams returns the Params of a list request without query parameters, selecting the first page
+// of items in the default sort of spec. it is mainly useful to iterate over every item along with After.
+func NewParams(spec Spec) *Params {
+	p, _ := (&Parser{spec: spec}).parse(url.Values{})
+	return p
+}
+
+// Parse parses the query parameters of r.
+func (ps *Parser) Parse(r *http.Request) (*Params, error) {
+	return ps.parse(r.URL.Query())
+}
+
+// parse parses the query parameters q.
+func (ps *Parser) parse(q url.Values) (*Params, error) {
+	var (
+		err error
+		p   = &Params{Limit: ps.spec.DefaultLimit, codec: ps.codec}
+	)
+
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+
+		if (err != nil) || (n < 1) {
+			return nil, fmt.Errorf("%w: limit must be a positive integer", ErrInvalidQuery)
+		}
+
+		p.Limit = min(n, ps.spec.MaxLimit)
+	}
+
+	if v := q.Get("total"); v != "" {
+		if p.Total, err = strconv.ParseBool(v); err != nil {
+			return nil, fmt.Errorf("%w: total must be a boolean", ErrInvalidQuery)
+		}
+	}
+
+	sort := q.Get("sort")
+
+	if sort == "" {
+		sort = ps.spec.DefaultSort
+	}
+
+	if p.Sort, err = ps.parseSort(sort); err != nil {
+		return nil, err
+	}
+
+	for key, vals := range q {
+		matches := filterRegexp.FindStringSubmatch(key)
+
+		if matches == nil {
+			continue
+		}
+
+		for _, v := range vals {
+			f, err := ps.parseFilter(matches[1], Operator(matches[2]), v)
+
+			if err != nil {
+				return nil, err
+			}
+
+			p.Filters = append(p.Filters, f)
+		}
+	}
+
+	// keep filters in a stable order, cursors are bound to them
+	slices.SortFunc(p.Filters, func(a, b Filter) int {
+		return strings.Compare(a.Field.Name+string(a.Op)+formatValue(a.Value), b.Field.Name+string(b.Op)+formatValue(b.Value))
+	})
+
+	if v := q.Get("cursor"); v != "" {
+		if p.Cursor, err = ps.codec.decode(v, p); err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+// parseSort parses a comma separated list of fields, appending the Spec key when it's missing.
+func (ps *Parser) parseSort(sort string) ([]Sort, error) {
+	sorts := []Sort{}
+
+	for _, name := range strings.Split(sort, ",") {
+		s := Sort{}
+		name = strings.TrimSpace(name)
+
+		if name == "" {
+			continue
+		}
+
+		if strings.HasPrefix(name, "-") {
+			s.Desc = true
+			name = name[1:]
+		}
+
+		f, ok := ps.field(name)
+
+		if !ok || !f.Sortable {
+			return nil, fmt.Errorf("%w: can't sort by \"%s\"", ErrInvalidQuery, name)
+		}
+
+		if slices.ContainsFunc(sorts, func(s Sort) bool { return s.Field.Name == name }) {
+			return nil, fmt.Errorf("%w: \"%s\" is sorted by more than once", ErrInvalidQuery, name)
+		}
+
+		s.Field = f
+		sorts = append(sorts, s)
+	}
+
+	if !slices.ContainsFunc(sorts, func(s Sort) bool { return s.Field.Name == ps.spec.Key }) {
+		key, _ := ps.field(ps.spec.Key)
+		sorts = append(sorts, Sort{Field: key})
+	}
+
+	return sorts, nil
+}
+
+// parseFilter parses the filter of the named field. an empty op defaults to OpEq.
+func (ps *Parser) parseFilter(name string, op Operator, val string) (Filter, error) {
+	if op == "" {
+		op = OpEq
+	}
+
+	f, ok := ps.field(name)
+
+	if !ok || !slices.Contains(f.Operators, op) {
+		return Filter{}, fmt.Errorf("%w: can't filter \"%s\" with \"%s\"", ErrInvalidQuery, name, op)
+	}
+
+	v, err := parseValue(f.Type, val)
+
+	if err != nil {
+		return Filter{}, fmt.Errorf("%w: invalid value of \"%s\": %w", ErrInvalidQuery, name, err)
+	}
+
+	return Filter{Field: f, Op: op, Value: v}, nil
+}
+
+// field returns the Spec field with the given name.
+func (ps *Parser) field(name string) (Field, bool) {
+	i := slices.IndexFunc(ps.spec.Fields, func(f Field) bool { return f.Name == name })
+
+	if i < 0 {
+		return Field{}, false
+	}
+
+	return ps.spec.Fields[i], true
+}
+
+// order returns the sort used to fetch the page of p, which is reversed for previous pages.
+func (p *Params) order() []Sort {
+	if (p.Cursor == nil) || !p.Cursor.Before {
+		return p.Sort
+	}
+
+	sorts := slices.Clone(p.Sort)
+
+	for i := range sorts {
+		sorts[i].Desc = !sorts[i].Desc
+	}
+
+	return sorts
+}
+
+// parseValue parses a value of type t.
+func parseValue(t FieldType, v string) (any, error) {
+	switch t {
+	case FieldInt:
+		return strconv.ParseInt(v, 10, 64)
+	case FieldTime:
+		tm, err := time.Parse(time.RFC3339, v)
+		return tm.UTC(), err
+	}
+
+	return v, nil
+}
+
+// formatValue formats a value parsed by parseValue.
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	}
+
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,186 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type item struct {
+	Id    string
+	Name  string
+	Score int64
+}
+
+var itemSpec = Spec{
+	Fields: []Field{
+		{Name: "id", Column: "id", Type: FieldString, Sortable: true},
+		{Name: "name", Column: "name", Type: FieldString, Sortable: true, Operators: []Operator{OpEq, OpLike}},
+		{Name: "score", Column: "score", Type: FieldInt, Sortable: true, Operators: []Operator{OpGte}},
+	},
+	Key:          "id",
+	DefaultSort:  "id",
+	DefaultLimit: 2,
+	MaxLimit:     10,
+}
+
+func itemValue(it item, field string) any {
+	switch field {
+	case "name":
+		return it.Name
+	case "score":
+		return it.Score
+	}
+
+	return it.Id
+}
+
+// newTestParser creates a Parser of itemSpec signing its cursors with a test secret.
+func newTestParser(t *testing.T) *Parser {
+	t.Helper()
+	t.Setenv("QUERY_CURSOR_SECRET", "secret")
+
+	return NewParser(itemSpec)
+}
+
+// ids returns the ids of items.
+func ids(items []item) []string {
+	s := []string{}
+
+	for _, it := range items {
+		s = append(s, it.Id)
+	}
+
+	return s
+}
+
+// fetch parses the query q and selects its page of items, returning it along with the next and previous cursors.
+func fetch(t *testing.T, ps *Parser, items []item, q string) (page []string, next string, prev string) {
+	t.Helper()
+
+	r := httptest.NewRequest("GET", "/items?"+q, nil)
+	p, err := ps.Parse(r)
+
+	if err != nil {
+		t.Fatalf("Parse(%q) = %s", q, err)
+	}
+
+	selected, hasMore := Select(items, p, itemValue)
+	meta, _ := NewPage(r, p, selected, hasMore, itemValue)
+
+	return ids(selected), meta.NextCursor, meta.PrevCursor
+}
+
+func TestCursorPagination(t *testing.T) {
+	var (
+		ps    = newTestParser(t)
+		items = []item{
+			{"e", "eve", 3}, {"a", "ann", 5}, {"c", "cid", 5}, {"b", "bob", 1}, {"d", "dan", 5},
+		}
+	)
+
+	page, next, prev := fetch(t, ps, items, "sort=-score")
+
+	if want := []string{"a", "c"}; !reflect.DeepEqual(page, want) || (prev != "") {
+		t.Fatalf("first page = %v (prev %q), want %v without prev", page, prev, want)
+	}
+
+	page, next, prev = fetch(t, ps, items, "sort=-score&cursor="+next)
+
+	if want := []string{"d", "e"}; !reflect.DeepEqual(page, want) || (next == "") || (prev == "") {
+		t.Fatalf("second page = %v (next %q, prev %q), want %v with both cursors", page, next, prev, want)
+	}
+
+	if page, _, _ := fetch(t, ps, items, "sort=-score&cursor="+next); !reflect.DeepEqual(page, []string{"b"}) {
+		t.Errorf("last page = %v, want [b]", page)
+	}
+
+	if page, _, prev := fetch(t, ps, items, "sort=-score&cursor="+prev); !reflect.DeepEqual(page, []string{"a", "c"}) || (prev != "") {
+		t.Errorf("previous page = %v (prev %q), want [a c] without prev", page, prev)
+	}
+}
+
+func TestInvalidCursor(t *testing.T) {
+	var (
+		ps    = newTestParser(t)
+		items = []item{{"a", "ann", 1}, {"b", "bob", 2}, {"c", "cid", 3}}
+	)
+
+	_, next, _ := fetch(t, ps, items, "filter[score][gte]=1")
+	payload, sig, _ := strings.Cut(next, ".")
+
+	for name, q := range map[string]string{
+		"other filters": "filter[score][gte]=2&cursor=" + next,
+		"other sort":    "sort=-id&cursor=" + next,
+		"forged":        "filter[score][gte]=1&cursor=" + payload + "." + strings.Repeat("A", len(sig)),
+		"malformed":     "filter[score][gte]=1&cursor=" + payload,
+	} {
+		_, err := ps.Parse(httptest.NewRequest("GET", "/items?"+q, nil))
+
+		if !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("Parse() of a cursor with %s = %v, want ErrInvalidCursor", name, err)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	ps := newTestParser(t)
+
+	for _, q := range []string{
+		"limit=0",
+		"limit=ten",
+		"total=maybe",
+		"sort=unknown",
+		"sort=name,-name",
+		"filter[id]=a",
+		"filter[name][gte]=a",
+		"filter[score][gte]=high",
+	} {
+		if _, err := ps.Parse(httptest.NewRequest("GET", "/items?"+q, nil)); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("Parse(%q) = %v, want ErrInvalidQuery", q, err)
+		}
+	}
+
+	p, err := ps.Parse(httptest.NewRequest("GET", "/items?limit=100", nil))
+
+	if (err != nil) || (p.Limit != itemSpec.MaxLimit) {
+		t.Errorf("Parse() of a limit above the maximum = %v, %v, want a limit of %d", p, err, itemSpec.MaxLimit)
+	}
+}
+
+func TestWhere(t *testing.T) {
+	var (
+		ps     = newTestParser(t)
+		r      = httptest.NewRequest("GET", "/items?sort=-score&filter[name][like]=5%25_", nil)
+		p, err = ps.Parse(r)
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	meta, _ := NewPage(r, p, []item{{"c", "cid", 5}}, true, itemValue)
+	p, err = ps.Parse(httptest.NewRequest("GET", "/items?sort=-score&filter[name][like]=5%25_&cursor="+meta.NextCursor, nil))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	where, args := p.Where()
+	wantWhere := `1 = 1 AND LOWER(name) LIKE ? ESCAPE '\' AND ((score < ?) OR (score = ? AND id > ?))`
+
+	if where != wantWhere {
+		t.Errorf("Where() = %q, want %q", where, wantWhere)
+	}
+
+	if want := []any{`%5\%\_%`, int64(5), int64(5), "c"}; fmt.Sprint(args) != fmt.Sprint(want) {
+		t.Errorf("Where() args = %v, want %v", args, want)
+	}
+
+	if orderBy := p.OrderBy(); orderBy != "score DESC, id ASC" {
+		t.Errorf("OrderBy() = %q, want %q", orderBy, "score DESC, id ASC")
+	}
+}
@@ -0,0 +1,156 @@
+package query
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ValueFunc returns the value of the named field of item: a string, an int64 or a time.Time depending on its FieldType.
+type ValueFunc[T any] func(item T, field string) any
+
+// Slice trims the rows fetched for p down to its page, restoring their order for previous pages.
+// hasMore reports whether more items follow the page in the direction it was fetched.
+func Slice[T any](p *Params, rows []T) (page []T, hasMore bool) {
+	if len(rows) > p.Limit {
+		rows, hasMore = rows[:p.Limit], true
+	}
+
+	if (p.Cursor != nil) && p.Cursor.Before {
+		slices.Reverse(rows)
+	}
+
+	return rows, hasMore
+}
+
+// Select returns the page of items selected by p, the same way a database would for p.Where and p.OrderBy.
+// it is meant for in-memory stores.
+func Select[T any](items []T, p *Params, value ValueFunc[T]) (page []T, hasMore bool) {
+	var (
+		rows  = []T{}
+		sorts = p.order()
+	)
+
+	for _, item := range items {
+		if !match(p, item, value) {
+			continue
+		}
+
+		if (p.Cursor != nil) && (compareSorted(sorts, p.Cursor.Values, sortValues(sorts, item, value)) >= 0) {
+			continue
+		}
+
+		rows = append(rows, item)
+	}
+
+	slices.SortFunc(rows, func(a, b T) int {
+		return compareSorted(sorts, sortValues(sorts, a, value), sortValues(sorts, b, value))
+	})
+
+	return Slice(p, rows[:min(len(rows), p.Fetch())])
+}
+
+// After returns a copy of p selecting the page following item.
+func After[T any](p *Params, item T, value ValueFunc[T]) *Params {
+	next := *p
+	next.Cursor = &Cursor{Values: sortValues(p.Sort, item, value)}
+
+	return &next
+}
+
+// Count returns the number of items matching the filters of p.
+func Count[T any](items []T, p *Params, value ValueFunc[T]) int {
+	n := 0
+
+	for _, item := range items {
+		if match(p, item, value) {
+			n++
+		}
+	}
+
+	return n
+}
+
+// match reports whether item matches every filter of p.
+func match[T any](p *Params, item T, value ValueFunc[T]) bool {
+	for _, f := range p.Filters {
+		v := value(item, f.Field.Name)
+
+		if f.Op == OpLike {
+			if !strings.Contains(strings.ToLower(v.(string)), strings.ToLower(f.Value.(string))) {
+				return false
+			}
+
+			continue
+		}
+
+		if !compareOp(f.Op, compare(v, f.Value)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// compareOp reports whether the result c of comparing two values satisfies op.
+func compareOp(op Operator, c int) bool {
+	switch op {
+	case OpEq:
+		return c == 0
+	case OpNe:
+		return c != 0
+	case OpGt:
+		return c > 0
+	case OpGte:
+		return c >= 0
+	case OpLt:
+		return c < 0
+	case OpLte:
+		return c <= 0
+	}
+
+	return false
+}
+
+// sortValues returns the values of the sorted fields of item.
+func sortValues[T any](sorts []Sort, item T, value ValueFunc[T]) []any {
+	vals := make([]any, len(sorts))
+
+	for i, s := range sorts {
+		vals[i] = value(item, s.Field.Name)
+	}
+
+	return vals
+}
+
+// compareSorted compares two lists of sort values in the directions of sorts.
+func compareSorted(sorts []Sort, a []any, b []any) int {
+	for i, s := range sorts {
+		c := compare(a[i], b[i])
+
+		if s.Desc {
+			c = -c
+		}
+
+		if c != 0 {
+			return c
+		}
+	}
+
+	return 0
+}
+
+// compare compares two values of the same FieldType.
+func compare(a any, b any) int {
+	switch a := a.(type) {
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	case int64:
+		return cmp.Compare(a, b.(int64))
+	case string:
+		return strings.Compare(a, b.(string))
+	}
+
+	return 0
+}
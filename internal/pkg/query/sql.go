@@ -0,0 +1,96 @@
+package query
+
+import (
+	"strings"
+)
+
+// sqlOperators maps operators to their sql comparison operator.
+var sqlOperators = map[Operator]string{
+	OpEq:  "=",
+	OpNe:  "<>",
+	OpGt:  ">",
+	OpGte: ">=",
+	OpLt:  "<",
+	OpLte: "<=",
+}
+
+// likeEscaper escapes the wildcards of LIKE patterns.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// FilterWhere returns the sql condition matching the filters of p along with its arguments.
+// the condition uses "?" placeholders and is "1 = 1" when there are no filters.
+func (p *Params) FilterWhere() (string, []any) {
+	conds := []string{"1 = 1"}
+	args := []any{}
+
+	for _, f := range p.Filters {
+		if f.Op == OpLike {
+			conds = append(conds, "LOWER("+f.Field.Column+`) LIKE ? ESCAPE '\'`)
+			args = append(args, "%"+likeEscaper.Replace(strings.ToLower(f.Value.(string)))+"%")
+			continue
+		}
+
+		conds = append(conds, f.Field.Column+" "+sqlOperators[f.Op]+" ?")
+		args = append(args, f.Value)
+	}
+
+	return strings.Join(conds, " AND "), args
+}
+
+// Where returns the sql condition matching the filters of p and the items following its cursor,
+// along with its arguments. the condition uses "?" placeholders.
+func (p *Params) Where() (string, []any) {
+	where, args := p.FilterWhere()
+
+	if p.Cursor == nil {
+		return where, args
+	}
+
+	// (a > x) OR (a = x AND b > y) OR ..., comparing each field in its sort direction
+	var (
+		sorts = p.order()
+		ors   = []string{}
+	)
+
+	for i, s := range sorts {
+		ands := []string{}
+
+		for j := range i {
+			ands = append(ands, sorts[j].Field.Column+" = ?")
+			args = append(args, p.Cursor.Values[j])
+		}
+
+		op := " > ?"
+
+		if s.Desc {
+			op = " < ?"
+		}
+
+		ands = append(ands, s.Field.Column+op)
+		args = append(args, p.Cursor.Values[i])
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+
+	return where + " AND (" + strings.Join(ors, " OR ") + ")", args
+}
+
+// OrderBy returns the sql ORDER BY expression of the page of p.
+func (p *Params) OrderBy() string {
+	cols := []string{}
+
+	for _, s := range p.order() {
+		if s.Desc {
+			cols = append(cols, s.Field.Column+" DESC")
+		} else {
+			cols = append(cols, s.Field.Column+" ASC")
+		}
+	}
+
+	return strings.Join(cols, ", ")
+}
+
+// Fetch returns the number of rows to fetch for the page of p. it is one more than the limit,
+// the extra row tells whether there are more items and is dropped by Slice.
+func (p *Params) Fetch() int {
+	return p.Limit + 1
+}
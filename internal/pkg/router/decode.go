@@ -0,0 +1,249 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/huboh/go-rest-api/internal/pkg/json"
+)
+
+// decodeRequest decodes r into v, a pointer to the request of a typed handler.
+//
+// struct fields tagged `path:"name"` are set to the path parameter name and fields tagged `query:"name"`
+// to the query parameter name, slice fields taking every value of the parameter. the other exported
+// fields are decoded from the json body, which is required by POST, PUT and PATCH requests. fields read
+// from the path or the query should be tagged `json:"-"`.
+func decodeRequest(r *http.Request, v any) error {
+	var (
+		rv = reflect.ValueOf(v).Elem()
+		rt = rv.Type()
+	)
+
+	if hasBody(rt) && (bodyRequired(r.Method) || ((r.Body != nil) && (r.Body != http.NoBody))) {
+		if err := json.UnmarshalBody(r, v); err != nil {
+			return err
+		}
+	}
+
+	if rt.Kind() != reflect.Struct {
+		return nil
+	}
+
+	query := r.URL.Query()
+
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+
+		// rejected by Handle, see checkRequestType
+		if !f.IsExported() {
+			continue
+		}
+
+		if name, ok := f.Tag.Lookup("path"); ok {
+			if err := setValues(rv.Field(i), []string{r.PathValue(name)}); err != nil {
+				return fmt.Errorf("path parameter %q: %w", name, err)
+			}
+		}
+
+		if name, ok := f.Tag.Lookup("query"); ok && query.Has(name) {
+			if err := setValues(rv.Field(i), query[name]); err != nil {
+				return fmt.Errorf("query parameter %q: %w", name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkRequestType returns an error when requests of type t can't be decoded, because a field tagged
+// with "path" or "query" is unexported or of a type parameters can't be decoded into.
+func checkRequestType(t reflect.Type) error {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		for _, key := range []string{"path", "query"} {
+			if _, ok := f.Tag.Lookup(key); !ok {
+				continue
+			}
+
+			if !f.IsExported() {
+				return fmt.Errorf("field %s of %s is tagged with %q but unexported", f.Name, t, key)
+			}
+
+			if !isParamType(f.Type) {
+				return fmt.Errorf("field %s of %s is tagged with %q but parameters can't be decoded into %s", f.Name, t, key, f.Type)
+			}
+		}
+	}
+
+	return nil
+}
+
+// isParamType reports whether parameters can be decoded into values of type t, see setValues.
+func isParamType(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return true
+	}
+
+	return false
+}
+
+// hasBody reports whether requests of type t are decoded from a json body, which is the case of every
+// type but structs whose exported fields are all read from the path or the query.
+func hasBody(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return true
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		_, path := f.Tag.Lookup("path")
+		_, query := f.Tag.Lookup("query")
+
+		if f.IsExported() && !path && !query && (f.Tag.Get("json") != "-") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// bodyRequired reports whether requests of the given method must have a body when their type has one.
+func bodyRequired(method string) bool {
+	return (method == http.MethodPost) || (method == http.MethodPut) || (method == http.MethodPatch)
+}
+
+// queryParams describes the query parameters of requests of type t.
+func queryParams(t reflect.Type) []Parameter {
+	params := []Parameter{}
+
+	if t.Kind() != reflect.Struct {
+		return params
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		if name, ok := f.Tag.Lookup("query"); ok && f.IsExported() {
+			params = append(params, Parameter{Name: name, In: "query", Type: paramType(f.Type)})
+		}
+	}
+
+	return params
+}
+
+// paramType returns the json schema type of the values of parameters decoded into values of type t.
+func paramType(t reflect.Type) string {
+	for (t.Kind() == reflect.Pointer) || (t.Kind() == reflect.Slice) {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	}
+
+	return "string"
+}
+
+// setValues sets v, a field of a request, to the parameter values vals.
+func setValues(v reflect.Value, vals []string) error {
+	switch v.Kind() {
+	case reflect.Slice:
+		s := reflect.MakeSlice(v.Type(), len(vals), len(vals))
+
+		for i, val := range vals {
+			if err := setValue(s.Index(i), val); err != nil {
+				return err
+			}
+		}
+
+		v.Set(s)
+
+		return nil
+
+	case reflect.Pointer:
+		p := reflect.New(v.Type().Elem())
+
+		if err := setValues(p.Elem(), vals); err != nil {
+			return err
+		}
+
+		v.Set(p)
+
+		return nil
+	}
+
+	// the first value wins, like url.Values.Get
+	return setValue(v, vals[0])
+}
+
+// setValue parses val into v according to its kind.
+func setValue(v reflect.Value, val string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(val)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(val)
+
+		if err != nil {
+			return fmt.Errorf("%q isn't a boolean", val)
+		}
+
+		v.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(val, 10, v.Type().Bits())
+
+		if err != nil {
+			return fmt.Errorf("%q isn't an integer", val)
+		}
+
+		v.SetInt(n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(val, 10, v.Type().Bits())
+
+		if err != nil {
+			return fmt.Errorf("%q isn't a non-negative integer", val)
+		}
+
+		v.SetUint(n)
+
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(val, v.Type().Bits())
+
+		if err != nil {
+			return fmt.Errorf("%q isn't a number", val)
+		}
+
+		v.SetFloat(n)
+
+	default:
+		return fmt.Errorf("can't decode parameters into %s", v.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/huboh/go-rest-api/internal/pkg/json"
+)
+
+// serveUnmatched handles req, which matches no route of r. fallback handlers aren't wrapped by the
+// router middlewares, so e.g. preflight requests don't have to be authenticated.
+func (r *Router) serveUnmatched(w http.ResponseWriter, req *http.Request) {
+	allowed := r.allowedMethods(req)
+
+	if len(allowed) == 0 {
+		orDefault(r.NotFound, http.HandlerFunc(notFound)).ServeHTTP(w, req)
+		return
+	}
+
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+
+	if req.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	orDefault(r.MethodNotAllowed, http.HandlerFunc(methodNotAllowed)).ServeHTTP(w, req)
+}
+
+// allowedMethods returns the sorted methods of the routes matching the path of req, including
+// HEAD for GET routes and OPTIONS, or nil when none does.
+func (r *Router) allowedMethods(req *http.Request) []string {
+	var (
+		allowed = []string{}
+		probe   = req.WithContext(req.Context())
+	)
+
+	for _, m := range r.methods() {
+		probe.Method = m
+
+		if _, pattern := r.mux.Handler(probe); pattern != "" {
+			allowed = append(allowed, m)
+		}
+	}
+
+	if len(allowed) == 0 {
+		return nil
+	}
+
+	allowed = append(allowed, http.MethodOptions)
+	slices.Sort(allowed)
+
+	return slices.Compact(allowed)
+}
+
+// methods returns the methods routes of r are registered for, HEAD being served by GET routes.
+func (r *Router) methods() []string {
+	methods := []string{}
+
+	for _, route := range r.Routes {
+		if route.Method == "" || slices.Contains(methods, route.Method) {
+			continue
+		}
+
+		methods = append(methods, route.Method)
+
+		if route.Method == http.MethodGet {
+			methods = append(methods, http.MethodHead)
+		}
+	}
+
+	return methods
+}
+
+// notFound is the default NotFound handler.
+func notFound(w http.ResponseWriter, req *http.Request) {
+	json.Write(w, json.Response{
+		StatusCode: http.StatusNotFound,
+		Error:      json.NewError(http.StatusText(http.StatusNotFound), fmt.Sprintf("no route matches %s %s", req.Method, req.URL.Path), "", ""),
+	})
+}
+
+// methodNotAllowed is the default MethodNotAllowed handler.
+func methodNotAllowed(w http.ResponseWriter, req *http.Request) {
+	json.Write(w, json.Response{
+		StatusCode: http.StatusMethodNotAllowed,
+		Error:      json.NewError(http.StatusText(http.StatusMethodNotAllowed), fmt.Sprintf("method %s is not allowed on %s", req.Method, req.URL.Path), "", ""),
+	})
+}
+
+// orDefault returns h, or def when h is nil.
+func orDefault(h http.Handler, def http.Handler) http.Handler {
+	if h == nil {
+		return def
+	}
+
+	return h
+}
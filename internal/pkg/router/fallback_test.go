@@ -0,0 +1,66 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/huboh/go-rest-api/internal/pkg/middleware"
+)
+
+func TestFallback(t *testing.T) {
+	guarded := false
+
+	guard := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			guarded = true
+			next.ServeHTTP(w, req)
+		})
+	}
+
+	r := New("", []middleware.Middleware{guard}, []Route{
+		{Path: "/users", Method: http.MethodGet, Handler: ok(http.StatusOK)},
+		{Path: "/users", Method: http.MethodPost, Handler: ok(http.StatusCreated)},
+	})
+
+	tests := []struct {
+		method string
+		path   string
+		want   int
+		allow  string
+		routed bool
+	}{
+		{http.MethodGet, "/users", http.StatusOK, "", true},
+		{http.MethodHead, "/users", http.StatusOK, "", true},
+		{http.MethodDelete, "/users", http.StatusMethodNotAllowed, "GET, HEAD, OPTIONS, POST", false},
+		{http.MethodOptions, "/users", http.StatusNoContent, "GET, HEAD, OPTIONS, POST", false},
+		{http.MethodGet, "/orgs", http.StatusNotFound, "", false},
+	}
+
+	for _, tt := range tests {
+		guarded = false
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+		if w.Code != tt.want {
+			t.Errorf("%s %s = %d, want %d", tt.method, tt.path, w.Code, tt.want)
+		}
+
+		if got := w.Header().Get("Allow"); got != tt.allow {
+			t.Errorf("%s %s Allow = %q, want %q", tt.method, tt.path, got, tt.allow)
+		}
+
+		// fallbacks aren't wrapped by the router middlewares
+		if guarded != tt.routed {
+			t.Errorf("%s %s went through the router middlewares = %t", tt.method, tt.path, guarded)
+		}
+	}
+
+	r.NotFound = ok(http.StatusTeapot)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orgs", nil))
+
+	if w.Code != http.StatusTeapot {
+		t.Errorf("GET /orgs with a NotFound handler = %d, want %d", w.Code, http.StatusTeapot)
+	}
+}
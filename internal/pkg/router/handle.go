@@ -0,0 +1,211 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/huboh/go-rest-api/internal/pkg/json"
+	"github.com/huboh/go-rest-api/internal/pkg/validate"
+)
+
+// ErrInvalidRequest wraps the errors of requests typed handlers can't decode or validate
+var ErrInvalidRequest = errors.New("invalid request")
+
+// ValidationErrorName is the name of the errors of requests failing the validation of their fields,
+// unless WithErrorName names them.
+const ValidationErrorName = "validation_failed"
+
+// Validator is implemented by the requests of typed handlers checking their own values once decoded.
+type Validator interface {
+	Validate() error
+}
+
+// Empty is the request or response type of typed handlers reading no request or responding without data.
+type Empty struct{}
+
+// HandleOption configures a typed handler created by Handle.
+type HandleOption func(*handleConfig)
+
+// handleConfig holds the options of a typed handler.
+type handleConfig struct {
+	status    int
+	message   string
+	errStatus func(error) int
+	errName   func(error) string
+}
+
+// WithStatus sets the status code of successful responses, http.StatusOK by default.
+func WithStatus(code int) HandleOption {
+	return func(c *handleConfig) {
+		c.status = code
+	}
+}
+
+// WithMessage sets the message of successful responses, the status text by default.
+func WithMessage(msg string) HandleOption {
+	return func(c *handleConfig) {
+		c.message = msg
+	}
+}
+
+// WithErrorStatus sets the function mapping the errors returned by handlers to status codes. by default
+// errors implementing interface{ StatusCode() int } use their status code and others are internal errors.
+func WithErrorStatus(fn func(error) int) HandleOption {
+	return func(c *handleConfig) {
+		c.errStatus = fn
+	}
+}
+
+// WithErrorName sets the function naming the errors of responses, including those wrapping ErrInvalidRequest.
+// errors are unnamed by default, except those of requests failing validation, see ValidationErrorName.
+func WithErrorName(fn func(error) string) HandleOption {
+	return func(c *handleConfig) {
+		c.errName = fn
+	}
+}
+
+// Handle returns a handler calling fn with the request decoded into a Req, and writing the Res it returns
+// as the data of a json.Response.
+//
+// the json body, query parameters and path parameters of requests are decoded into the fields of Req,
+// see decodeRequest, then Req is validated by the rules of its `validate` tags, see validate.Struct, and
+// by its Validate method when it implements Validator. requests that can't be decoded are rejected with
+// a bad request status without calling fn, and requests failing validation with an unprocessable entity
+// status listing their invalid fields, or a bad request status when Validate returns other errors than
+// validate.Errors.
+//
+// it panics when Req has a field tagged with "path" or "query" that is unexported or of a type parameters
+// can't be decoded into, or when its `validate` tags name unknown rules.
+func Handle[Req any, Res any](fn func(context.Context, Req) (Res, error), opts ...HandleOption) http.Handler {
+	c := handleConfig{
+		status:    http.StatusOK,
+		errStatus: defaultErrStatus,
+		errName:   func(error) string { return "" },
+	}
+
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	if err := checkRequestType(reflect.TypeFor[Req]()); err != nil {
+		panic(fmt.Sprintf("router: %s", err))
+	}
+
+	if err := validate.Check(reflect.TypeFor[Req]()); err != nil {
+		panic(fmt.Sprintf("router: %s", err))
+	}
+
+	return &typedHandler[Req, Res]{fn: fn, config: c}
+}
+
+// typedHandler is the handler returned by Handle.
+type typedHandler[Req any, Res any] struct {
+	fn     func(context.Context, Req) (Res, error)
+	config handleConfig
+}
+
+func (h *typedHandler[Req, Res]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req Req
+
+	if err := decodeRequest(r, &req); err != nil {
+		h.writeErr(w, fmt.Errorf("%w: %w", ErrInvalidRequest, err), http.StatusBadRequest)
+		return
+	}
+
+	if err := validateRequest(&req); err != nil {
+		h.writeErr(w, fmt.Errorf("%w: %w", ErrInvalidRequest, err), http.StatusBadRequest)
+		return
+	}
+
+	res, err := h.fn(r.Context(), req)
+
+	if err != nil {
+		h.writeErr(w, err, h.config.errStatus(err))
+		return
+	}
+
+	data := any(res)
+
+	if _, ok := data.(Empty); ok {
+		data = nil
+	}
+
+	json.Write(w, json.Response{
+		StatusCode: h.config.status,
+		Message:    h.config.message,
+		Data:       data,
+	})
+}
+
+// writeErr responds with err and the status code, or the unprocessable entity status and the invalid
+// fields of err when it wraps validate.Errors, the error being named ValidationErrorName by default.
+func (h *typedHandler[Req, Res]) writeErr(w http.ResponseWriter, err error, code int) {
+	var (
+		fields  validate.Errors
+		jsonErr = json.ErrorFromErr(err, h.config.errName(err), "")
+	)
+
+	if errors.As(err, &fields) {
+		code = http.StatusUnprocessableEntity
+
+		if jsonErr.Name == "" {
+			jsonErr.Name = ValidationErrorName
+		}
+
+		for _, f := range fields {
+			jsonErr.Fields = append(jsonErr.Fields, json.FieldError{Field: f.Field, Code: f.Code, Message: f.Message})
+		}
+	}
+
+	json.Write(w, json.Response{
+		StatusCode: code,
+		Error:      jsonErr,
+	})
+}
+
+// validateRequest validates req, a pointer to the request of a typed handler, by its tags then by its
+// Validate method when it implements Validator, returning the first error.
+func validateRequest(req any) error {
+	if err := validate.Struct(req); err != nil {
+		return err
+	}
+
+	if v, ok := req.(Validator); ok {
+		return v.Validate()
+	}
+
+	return nil
+}
+
+// describe returns the values describing the request body and response data of h for the api docs, nil
+// when it reads no body or responds without data, along with its query parameters.
+func (h *typedHandler[Req, Res]) describe() (req any, res any, params []Parameter) {
+	var (
+		reqType = reflect.TypeFor[Req]()
+		resType = reflect.TypeFor[Res]()
+	)
+
+	if hasBody(reqType) {
+		req = reflect.Zero(reqType).Interface()
+	}
+
+	if resType != reflect.TypeFor[Empty]() {
+		res = reflect.Zero(resType).Interface()
+	}
+
+	return req, res, queryParams(reqType)
+}
+
+// defaultErrStatus is the default function mapping errors to status codes.
+func defaultErrStatus(err error) int {
+	var coded interface{ StatusCode() int }
+
+	if errors.As(err, &coded) {
+		return coded.StatusCode()
+	}
+
+	return http.StatusInternalServerError
+}
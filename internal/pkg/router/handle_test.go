@@ -0,0 +1,206 @@
+package router
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	jsonEncoder "encoding/json"
+)
+
+type getItemRequest struct {
+	Id    string   `path:"id"`
+	Limit int      `query:"limit"`
+	Tags  []string `query:"tag"`
+	Note  string   `json:"note"`
+}
+
+type itemResponse struct {
+	Id    string   `json:"id"`
+	Limit int      `json:"limit"`
+	Tags  []string `json:"tags"`
+	Note  string   `json:"note"`
+}
+
+func getItem(ctx context.Context, req getItemRequest) (itemResponse, error) {
+	return itemResponse(req), nil
+}
+
+// serve serves a request with the given method, path and body by r, returning the decoded response.
+func serve(t *testing.T, r http.Handler, method string, path string, body string) (int, map[string]any) {
+	t.Helper()
+
+	var (
+		w   = httptest.NewRecorder()
+		res = map[string]any{}
+	)
+
+	var reader io.Reader
+
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	r.ServeHTTP(w, req)
+
+	if err := jsonEncoder.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("%s %s responded %q: %s", method, path, w.Body, err)
+	}
+
+	return w.Code, res
+}
+
+func TestHandleDecodesRequest(t *testing.T) {
+	r := New("", nil, []Route{
+		{Path: "/items/{id}", Method: http.MethodPost, Handler: Handle(getItem)},
+	})
+
+	code, res := serve(t, r, http.MethodPost, "/items/42?limit=10&tag=a&tag=b", `{"note":"hi"}`)
+
+	if code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %v", code, http.StatusOK, res)
+	}
+
+	want := map[string]any{"id": "42", "limit": float64(10), "tags": []any{"a", "b"}, "note": "hi"}
+
+	if got := res["data"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("data = %v, want %v", got, want)
+	}
+
+	if code, _ := serve(t, r, http.MethodPost, "/items/42?limit=ten", `{}`); code != http.StatusBadRequest {
+		t.Errorf("status of an invalid query parameter = %d, want %d", code, http.StatusBadRequest)
+	}
+}
+
+func TestHandlePanicsOnUndecodableParams(t *testing.T) {
+	type unexported struct {
+		id string `path:"id"`
+	}
+
+	type unsupported struct {
+		Filter map[string]string `query:"filter"`
+	}
+
+	tests := []struct {
+		name   string
+		handle func()
+	}{
+		{"unexported", func() {
+			Handle(func(context.Context, unexported) (Empty, error) { return Empty{}, nil })
+		}},
+		{"unsupported", func() {
+			Handle(func(context.Context, unsupported) (Empty, error) { return Empty{}, nil })
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Handle() didn't panic")
+				}
+			}()
+
+			tt.handle()
+		})
+	}
+}
+
+func TestWalkDescribesTypedHandlers(t *testing.T) {
+	r := New("", nil, []Route{
+		{Path: "/items/{id}", Method: http.MethodPost, Handler: Handle(getItem)},
+	})
+
+	err := r.Walk(func(info RouteInfo) error {
+		if _, ok := info.Request.(getItemRequest); !ok {
+			t.Errorf("Request = %#v, want a getItemRequest", info.Request)
+		}
+
+		if _, ok := info.Response.(itemResponse); !ok {
+			t.Errorf("Response = %#v, want an itemResponse", info.Response)
+		}
+
+		names := []string{}
+
+		for _, p := range info.Params {
+			names = append(names, p.Name)
+		}
+
+		if !reflect.DeepEqual(names, []string{"limit", "tag"}) {
+			t.Errorf("Params = %v, want limit and tag", names)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+type createItemRequest struct {
+	Name    string `json:"name" validate:"required"`
+	Email   string `json:"email" validate:"required,email"`
+	Members []struct {
+		Role string `json:"role" validate:"oneof=admin member"`
+	} `json:"members"`
+}
+
+func TestHandleRespondsInvalidFields(t *testing.T) {
+	r := New("", nil, []Route{
+		{
+			Path:    "/items",
+			Method:  http.MethodPost,
+			Handler: Handle(func(context.Context, createItemRequest) (Empty, error) { return Empty{}, nil }),
+		},
+	})
+
+	code, res := serve(t, r, http.MethodPost, "/items", `{"email":"jane","members":[{"role":"admin"},{"role":"owner"}]}`)
+
+	if code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want %d: %v", code, http.StatusUnprocessableEntity, res)
+	}
+
+	resErr, _ := res["error"].(map[string]any)
+
+	if resErr["name"] != ValidationErrorName {
+		t.Errorf("error name = %v, want %q", resErr["name"], ValidationErrorName)
+	}
+
+	fields := []string{}
+
+	for _, f := range resErr["fields"].([]any) {
+		f := f.(map[string]any)
+		fields = append(fields, f["field"].(string)+":"+f["code"].(string))
+	}
+
+	want := []string{"name:required", "email:email", "members[1].role:oneof"}
+
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("fields = %v, want %v", fields, want)
+	}
+}
+
+func TestHandlePanicsOnUnknownRules(t *testing.T) {
+	type request struct {
+		Name string `json:"name" validate:"required,unknown"`
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Handle() didn't panic")
+		}
+	}()
+
+	Handle(func(context.Context, request) (Empty, error) { return Empty{}, nil })
+}
@@ -0,0 +1,16 @@
+package router
+
+// Module is implemented by the feature packages of an app, adding their routes to a router at startup.
+// modules are created with the services and middlewares their routes depend on, so that nothing is built
+// before the app is configured.
+type Module interface {
+	// RegisterRoutes adds the routes of the module to r, usually by mounting its routers.
+	RegisterRoutes(r *Router)
+}
+
+// Register lets modules add their routes to r, in order.
+func (r *Router) Register(modules ...Module) {
+	for _, m := range modules {
+		m.RegisterRoutes(r)
+	}
+}
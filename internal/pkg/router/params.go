@@ -0,0 +1,230 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/huboh/go-rest-api/internal/pkg/json"
+)
+
+// ErrInvalidParam is returned by the typed getters when a path parameter doesn't hold a value of the requested type
+var ErrInvalidParam = errors.New("invalid path parameter")
+
+var (
+	paramTypesMu sync.RWMutex
+
+	// paramTypes are the path parameter types routes can declare, e.g. "{id:uuid}"
+	paramTypes = map[string]func(string) bool{
+		"int":  func(v string) bool { _, err := strconv.Atoi(v); return err == nil },
+		"uint": func(v string) bool { _, err := strconv.ParseUint(v, 10, 0); return err == nil },
+		"uuid": uuidRegexp.MatchString,
+	}
+
+	// uuidRegexp matches UUIDs in their canonical string form, regardless of case
+	uuidRegexp = regexp.MustCompile(`(?i)^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+	// typeNameRegexp matches the constraints naming a type rather than a pattern
+	typeNameRegexp = regexp.MustCompile(`^[a-z][a-z0-9]*$`)
+)
+
+// RegisterParamType makes name usable as a path parameter type, match reporting whether a value is of the type.
+// routes must be registered after the types they use.
+func RegisterParamType(name string, match func(string) bool) {
+	if !typeNameRegexp.MatchString(name) {
+		panic(fmt.Sprintf("router: invalid path parameter type name %q", name))
+	}
+
+	paramTypesMu.Lock()
+	defer paramTypesMu.Unlock()
+
+	paramTypes[name] = match
+}
+
+// param is a constrained wildcard of a route path.
+type param struct {
+	// name is the wildcard name, as given to Request.PathValue
+	name string
+
+	// constraint is the type name or the pattern following the wildcard name
+	constraint string
+
+	// typed reports whether constraint names a type rather than a pattern
+	typed bool
+
+	// match reports whether a value satisfies constraint
+	match func(string) bool
+}
+
+// check writes an error response and returns false when the value of p in req doesn't satisfy its constraint.
+// a value of the wrong type is a bad request, while a value not matching a pattern is a path the route doesn't serve.
+func (p param) check(w http.ResponseWriter, req *http.Request) bool {
+	if p.match(req.PathValue(p.name)) {
+		return true
+	}
+
+	if p.typed {
+		json.Write(w, json.Response{
+			StatusCode: http.StatusBadRequest,
+			Error:      json.ErrorFromErr(fmt.Errorf("%w %q: must be of type %s", ErrInvalidParam, p.name, p.constraint), "", ""),
+		})
+
+		return false
+	}
+
+	json.Write(w, json.Response{
+		StatusCode: http.StatusNotFound,
+		Error:      json.ErrorFromErr(fmt.Errorf("%w %q: must match %s", ErrInvalidParam, p.name, p.constraint), "", ""),
+	})
+
+	return false
+}
+
+// parsePattern strips the constraints of the wildcards of the route pattern p, e.g. "/users/{id:uuid}"
+// becomes "/users/{id}", returning the ServeMux pattern and the constrained wildcards.
+//
+// a constraint is either the name of a type, see RegisterParamType, or a regular expression values
+// must fully match, e.g. "{code:[A-Z]{3}}". like ServeMux wildcards, constrained ones are whole
+// path segments, so patterns can't contain "/".
+func parsePattern(p string) (string, []param) {
+	var (
+		params   = []param{}
+		segments = strings.Split(p, "/")
+	)
+
+	for i, seg := range segments {
+		if !strings.HasPrefix(seg, "{") || !strings.HasSuffix(seg, "}") {
+			continue
+		}
+
+		name, constraint, ok := strings.Cut(seg[1:len(seg)-1], ":")
+
+		if !ok {
+			continue
+		}
+
+		segments[i] = "{" + name + "}"
+		params = append(params, newParam(strings.TrimSuffix(name, "..."), constraint))
+	}
+
+	return strings.Join(segments, "/"), params
+}
+
+// pathParams returns the ServeMux pattern of the route path p, along with all its wildcards.
+func pathParams(p string) (string, []PathParam) {
+	var (
+		params      = []PathParam{}
+		pattern, cs = parsePattern(p)
+	)
+
+	for _, seg := range strings.Split(pattern, "/") {
+		if !strings.HasPrefix(seg, "{") || !strings.HasSuffix(seg, "}") || (seg == "{$}") {
+			continue
+		}
+
+		pp := PathParam{Name: strings.TrimSuffix(seg[1:len(seg)-1], "...")}
+
+		if i := slices.IndexFunc(cs, func(c param) bool { return c.name == pp.Name }); i >= 0 {
+			if cs[i].typed {
+				pp.Type = cs[i].constraint
+			} else {
+				pp.Pattern = cs[i].constraint
+			}
+		}
+
+		params = append(params, pp)
+	}
+
+	return pattern, params
+}
+
+// newParam creates the param named name. it panics on unknown types and invalid patterns,
+// the same way ServeMux panics on invalid route patterns.
+func newParam(name string, constraint string) param {
+	if typeNameRegexp.MatchString(constraint) {
+		paramTypesMu.RLock()
+		defer paramTypesMu.RUnlock()
+
+		match, ok := paramTypes[constraint]
+
+		if !ok {
+			panic(fmt.Sprintf("router: unknown type %q of path parameter %q", constraint, name))
+		}
+
+		return param{name: name, constraint: constraint, typed: true, match: match}
+	}
+
+	re, err := regexp.Compile("^(?:" + constraint + ")$")
+
+	if err != nil {
+		panic(fmt.Sprintf("router: invalid pattern of path parameter %q: %v", name, err))
+	}
+
+	return param{name: name, constraint: constraint, match: re.MatchString}
+}
+
+// checkParams returns a handler checking the constrained path parameters before calling h.
+func checkParams(params []param, h http.Handler) http.Handler {
+	if len(params) == 0 {
+		return h
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		for _, p := range params {
+			if !p.check(w, req) {
+				return
+			}
+		}
+
+		h.ServeHTTP(w, req)
+	})
+}
+
+// Param returns the value of the path parameter name, like Request.PathValue.
+func Param(r *http.Request, name string) string {
+	return r.PathValue(name)
+}
+
+// ParamInt returns the value of the path parameter name as an int.
+//
+// return ErrInvalidParam when the value isn't an int, which can't happen for parameters declared as "{name:int}"
+func ParamInt(r *http.Request, name string) (int, error) {
+	n, err := strconv.Atoi(r.PathValue(name))
+
+	if err != nil {
+		return 0, fmt.Errorf("%w %q: must be of type int", ErrInvalidParam, name)
+	}
+
+	return n, nil
+}
+
+// ParamUint returns the value of the path parameter name as a uint.
+//
+// return ErrInvalidParam when the value isn't a uint, which can't happen for parameters declared as "{name:uint}"
+func ParamUint(r *http.Request, name string) (uint, error) {
+	n, err := strconv.ParseUint(r.PathValue(name), 10, 0)
+
+	if err != nil {
+		return 0, fmt.Errorf("%w %q: must be of type uint", ErrInvalidParam, name)
+	}
+
+	return uint(n), nil
+}
+
+// ParamUUID returns the value of the path parameter name as a lowercase UUID.
+//
+// return ErrInvalidParam when the value isn't a UUID, which can't happen for parameters declared as "{name:uuid}"
+func ParamUUID(r *http.Request, name string) (string, error) {
+	v := r.PathValue(name)
+
+	if !uuidRegexp.MatchString(v) {
+		return "", fmt.Errorf("%w %q: must be of type uuid", ErrInvalidParam, name)
+	}
+
+	return strings.ToLower(v), nil
+}
@@ -0,0 +1,79 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParamConstraints(t *testing.T) {
+	var got string
+
+	echo := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		got = Param(req, "v")
+	})
+
+	RegisterParamType("even", func(v string) bool { return (v != "") && ((v[len(v)-1]-'0')%2 == 0) })
+
+	r := New("", nil, []Route{
+		{Path: "/int/{v:int}", Method: http.MethodGet, Handler: echo},
+		{Path: "/uint/{v:uint}", Method: http.MethodGet, Handler: echo},
+		{Path: "/uuid/{v:uuid}", Method: http.MethodGet, Handler: echo},
+		{Path: "/even/{v:even}", Method: http.MethodGet, Handler: echo},
+		{Path: "/code/{v:[A-Z]{3}}", Method: http.MethodGet, Handler: echo},
+	})
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/int/-42", http.StatusOK},
+		{"/int/4.2", http.StatusBadRequest},
+		{"/uint/42", http.StatusOK},
+		{"/uint/-42", http.StatusBadRequest},
+		{"/uuid/6F1C7A52-8F0E-4A7E-9A53-3A3D0C8E6A10", http.StatusOK},
+		{"/uuid/42", http.StatusBadRequest},
+		{"/even/42", http.StatusOK},
+		{"/even/43", http.StatusBadRequest},
+		{"/code/EUR", http.StatusOK},
+		{"/code/EURO", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		got = ""
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+		if w.Code != tt.want {
+			t.Errorf("GET %s = %d, want %d", tt.path, w.Code, tt.want)
+		}
+
+		if (tt.want == http.StatusOK) && (got == "") {
+			t.Errorf("GET %s didn't reach the handler", tt.path)
+		}
+	}
+}
+
+func TestParamGetters(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.SetPathValue("n", "42")
+	req.SetPathValue("id", "6F1C7A52-8F0E-4A7E-9A53-3A3D0C8E6A10")
+	req.SetPathValue("neg", "-1")
+
+	if n, err := ParamInt(req, "n"); (n != 42) || (err != nil) {
+		t.Errorf("ParamInt() = %d, %v, want 42", n, err)
+	}
+
+	if _, err := ParamUint(req, "neg"); !errors.Is(err, ErrInvalidParam) {
+		t.Errorf("ParamUint() of a negative number = %v, want ErrInvalidParam", err)
+	}
+
+	if id, err := ParamUUID(req, "id"); (id != "6f1c7a52-8f0e-4a7e-9a53-3a3d0c8e6a10") || (err != nil) {
+		t.Errorf("ParamUUID() = %q, %v, want the lowercase uuid", id, err)
+	}
+
+	if _, err := ParamUUID(req, "n"); !errors.Is(err, ErrInvalidParam) {
+		t.Errorf("ParamUUID() of a number = %v, want ErrInvalidParam", err)
+	}
+}
@@ -1,12 +1,19 @@
 package router
 
-import "net/http"
+import (
+	"net/http"
+
+	"github.com/huboh/go-rest-api/internal/pkg/middleware"
+)
 
 // Route represents an api route
 type Route struct {
 	// Tag is used to add metadata to the route
 	Tag []string
 
+	// Name is the name of the route, unique among the routes of a version of the app, for URL to build its path.
+	Name string
+
 	// Path is request Path
 	Path string
 
@@ -15,6 +22,61 @@ type Route struct {
 
 	// Handler is the route http request Handler or a router to handle request received to `path`
 	Handler http.Handler
+
+	// Middlewares wrap Handler, outermost first, inside the middlewares of the router.
+	Middlewares []middleware.Middleware
+
+	// Summary is a short description of the route, used by the api docs.
+	Summary string
+
+	// Request is a value of the type of the request body, used by the api docs. it is nil for routes reading no body.
+	Request any
+
+	// Response is a value of the type of the response data, used by the api docs. it is nil for responses without data.
+	Response any
+
+	// Params describes the query and header parameters of the route, used by the api docs.
+	// path parameters are described by Path.
+	Params []Parameter
+
+	// Deprecated marks the route as deprecated in the api docs. the routes of a router inherit the
+	// deprecation of the route mounting it.
+	Deprecated bool
+
+	// Security are the names of the security schemes authenticating requests, used by the api docs.
+	// the routes of a router inherit the security of the route mounting it.
+	Security []string
+
+	// Auth are the requirements requests must meet to go through the guards among Middlewares, e.g.
+	// "admin", outermost first, for route listings and the checks telling guarded routes from routes missing
+	// an auth guard. the routes of a router inherit the requirements of the route mounting it.
+	Auth []string
+
+	// Public marks the route as served to unauthenticated requests on purpose, e.g. a login route, for
+	// the checks of route listings telling them from routes missing an auth guard. the routes of a
+	// router inherit it from the route mounting it.
+	Public bool
+
+	// version is the name of the version the route was added to, see Router.Version.
+	version string
+}
+
+// Parameter describes a query or header parameter of a route.
+type Parameter struct {
+	// Name is the parameter name.
+	Name string `json:"name"`
+
+	// In is the location of the parameter, either "query" or "header".
+	In string `json:"in"`
+
+	// Description describes the parameter.
+	Description string `json:"description,omitempty"`
+
+	// Required reports whether requests must have the parameter.
+	Required bool `json:"required,omitempty"`
+
+	// Type is the json schema type of the parameter values, "string" when empty.
+	Type string `json:"type,omitempty"`
 }
 
 func NewRoute(m string, p string, h http.Handler) Route {
@@ -2,14 +2,15 @@
 package router
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
-	"net/url"
+	"path"
+	"slices"
 	"strings"
 
 	"github.com/huboh/go-rest-api/internal/pkg/middleware"
-	"github.com/huboh/go-rest-api/internal/pkg/utils"
 )
 
 // Router
@@ -23,8 +24,35 @@ type Router struct {
 	// Routes
 	Routes []Route
 
-	// Middlewares
+	// Middlewares wrap every route of the router, outermost first: requests go through Middlewares[0]
+	// first, and through the Middlewares of their route after the router ones.
 	Middlewares []middleware.Middleware
+
+	// NotFound handles the requests matching no route. defaults to a json 404 response.
+	NotFound http.Handler
+
+	// MethodNotAllowed handles the requests only matching routes of other methods, with the Allow
+	// header already set. defaults to a json 405 response.
+	MethodNotAllowed http.Handler
+
+	// DefaultVersion names the version serving the requests to unversioned paths that don't name one,
+	// see Version. when empty, such requests are served as is.
+	DefaultVersion string
+
+	// versions are the versions added by Version, by normalized name.
+	versions map[string]Version
+
+	// names maps the names of the routes of the router, and of the routers it mounts, to their path.
+	names map[routeName]string
+
+	// version is the version of the routes added to a group, see Version.
+	version Version
+
+	// parent is the router the routes of groups are added to, see With and Group.
+	parent *Router
+
+	// mounted reports whether the router was mounted, see Mount.
+	mounted bool
 }
 
 func New(prefix string, mws []middleware.Middleware, routes []Route) *Router {
@@ -42,41 +70,217 @@ func New(prefix string, mws []middleware.Middleware, routes []Route) *Router {
 }
 
 // ServeHTTP makes Router implements http.Handler interface
+//
+// requests matching no route are handled by NotFound, or by MethodNotAllowed when routes of other
+// methods match their path. OPTIONS requests to such paths are answered with their Allow header,
+// while HEAD requests are served by the GET routes.
 func (r *Router) ServeHTTP(writer http.ResponseWriter, req *http.Request) {
-	r.mux.ServeHTTP(writer, req)
+	if _, ok := fromContext(req.Context()); !ok {
+		// the outermost router resolves route names, see URL
+		req = req.WithContext(context.WithValue(req.Context(), routerKey{}, r))
+	}
+
+	if len(r.versions) > 0 {
+		if req = r.selectVersion(writer, req); req == nil {
+			return
+		}
+	}
+
+	if _, pattern := r.mux.Handler(req); pattern != "" {
+		r.mux.ServeHTTP(writer, req)
+		return
+	}
+
+	r.serveUnmatched(writer, req)
+}
+
+// Use appends mws to the middlewares of r, wrapping its routes inside the middlewares it already has.
+// called on a group, mws only wrap the routes added to the group afterwards.
+// like every method adding routes or middlewares, it must not be called once r serves requests.
+func (r *Router) Use(mws ...middleware.Middleware) {
+	r.Middlewares = append(r.Middlewares, mws...)
+
+	if r.parent == nil {
+		// the handlers of the routes are wrapped again
+		r.mux = new(http.ServeMux)
+		r.registerRoutes()
+	}
+}
+
+// Add adds routes to r. routes added to a group are added to the router of the group, their path
+// prefixed by the prefix of the group and their middlewares by those of the group.
+func (r *Router) Add(routes ...Route) {
+	if r.parent != nil {
+		for _, route := range routes {
+			if r.Prefix != "" {
+				route.Path = joinPath(r.Prefix, route.Path)
+			}
+
+			route.Middlewares = append(slices.Clone(r.Middlewares), route.Middlewares...)
+			route.Deprecated = route.Deprecated || !r.version.Deprecated.IsZero()
+
+			if route.version == "" {
+				route.version = r.version.Name
+			}
+
+			r.parent.Add(route)
+		}
+
+		return
+	}
+
+	for _, route := range routes {
+		r.Routes = append(r.Routes, route)
+		r.registerRoute(route)
+	}
+}
+
+// With returns a group of r whose routes are wrapped by mws, inside the middlewares of r.
+// e.g. r.With(s.AdminGuardMiddleware).Add(routes...)
+func (r *Router) With(mws ...middleware.Middleware) *Router {
+	return &Router{
+		Middlewares: slices.Clone(mws),
+		version:     r.version,
+		parent:      r,
+	}
+}
+
+// Group calls fn with a group of r whose routes are prefixed by prefix, which may be empty. the group
+// has no middlewares of its own until fn calls Use.
+//
+// groups only add routes to r, they can't serve requests nor be mounted.
+func (r *Router) Group(prefix string, fn func(g *Router)) {
+	fn(&Router{Prefix: prefix, version: r.version, parent: r})
+}
+
+// Mount adds a route mounting sub at path, configured by opts. sub serves the requests whose path starts
+// with path, its routes being prefixed by it whatever the prefix sub was created with. it panics when sub
+// is a group or is already mounted, by r or another router.
+//
+// e.g. r.Mount("/users", user.NewRouter(s, mws), router.Tagged("users"))
+func (r *Router) Mount(path string, sub *Router, opts ...MountOption) {
+	if sub.parent != nil {
+		panic("router: groups can't be mounted")
+	}
+
+	if sub.mounted {
+		// mounting it again would rebase the routes it already serves at its first path
+		panic(fmt.Sprintf("router: the router with prefix %q is already mounted", sub.Prefix))
+	}
+
+	sub.mounted = true
+
+	route := Route{Path: path, Handler: sub}
+
+	for _, opt := range opts {
+		opt(&route)
+	}
+
+	r.Add(route)
+}
+
+// MountOption configures the route mounting a router, see Mount.
+type MountOption func(*Route)
+
+// Tagged adds tags to the route mounting a router, which its routes inherit.
+func Tagged(tags ...string) MountOption {
+	return func(route *Route) {
+		route.Tag = append(route.Tag, tags...)
+	}
+}
+
+// Secured adds security schemes to the route mounting a router, which its routes inherit.
+func Secured(schemes ...string) MountOption {
+	return func(route *Route) {
+		route.Security = append(route.Security, schemes...)
+	}
+}
+
+// Guarded adds the requirements of the guards among the middlewares of a router to the route mounting
+// it, which its routes inherit, see Route.Auth.
+func Guarded(requirements ...string) MountOption {
+	return func(route *Route) {
+		route.Auth = append(route.Auth, requirements...)
+	}
+}
+
+// Public marks the route mounting a router as public, which its routes inherit, see Route.Public.
+func Public() MountOption {
+	return func(route *Route) {
+		route.Public = true
+	}
+}
+
+// rebase sets the prefix of r, registering its routes again when it changes. the routers it mounts
+// are rebased too.
+func (r *Router) rebase(prefix string) {
+	if r.Prefix == prefix {
+		return
+	}
+
+	r.Prefix = prefix
+	r.mux = new(http.ServeMux)
+	r.registerRoutes()
 }
 
 // registerRoutes registers the router routes with the specified handlers
 func (r *Router) registerRoutes() {
 	log.Printf("registering routes for %s router\n", r.Prefix)
 
+	r.names = map[routeName]string{}
+
 	for _, route := range r.Routes {
-		var (
-			path     = utils.Must(url.JoinPath(r.Prefix, route.Path))
-			handler  = route.Handler
-			fullPath = strings.TrimSpace(fmt.Sprintf("%s %s", route.Method, path))
-		)
-
-		if _, ok := handler.(*Router); ok && !strings.HasSuffix(fullPath, "/") {
-			fullPath += "/"
-		}
+		r.registerRoute(route)
+	}
+}
 
-		// map to path handler
-		r.mux.Handle(fullPath, r.registerMiddlewares(handler))
+// registerRoute registers route, wrapped by the router middlewares then by its own.
+// routers mounted by route are rebased on its path.
+func (r *Router) registerRoute(route Route) {
+	if sub, ok := route.Handler.(*Router); ok {
+		sub.rebase(joinPath(r.Prefix, route.Path))
+	}
 
-		switch handler.(type) {
-		case *Router:
+	r.nameRoute(route)
 
-		case http.Handler, http.HandlerFunc:
-			log.Printf("new handler mapped for %s\n", fullPath)
-		}
+	var (
+		path, params = parsePattern(joinPath(r.Prefix, route.Path))
+		handler      = route.Handler
+		fullPath     = strings.TrimSpace(fmt.Sprintf("%s %s", route.Method, path))
+		checked      = r.registerMiddlewares(wrap(checkParams(params, handler), route.Middlewares))
+	)
+
+	if _, ok := handler.(*Router); ok && !strings.HasSuffix(fullPath, "/") {
+		// the sub router also serves its bare prefix, e.g. "/users" along with "/users/"
+		r.mux.Handle(fullPath, checked)
+		fullPath += "/"
 	}
+
+	// map to path handler
+	r.mux.Handle(fullPath, checked)
+}
+
+// joinPath joins the router prefix and a route path. unlike url.JoinPath it doesn't escape
+// the path, so ServeMux wildcards such as "{id}" and "{$}" are kept as is.
+func joinPath(prefix string, p string) string {
+	joined := path.Join("/", prefix, p)
+
+	if strings.HasSuffix(p, "/") && !strings.HasSuffix(joined, "/") {
+		joined += "/"
+	}
+
+	return joined
 }
 
 // registerMiddlewares registers the router middlewares
 func (r *Router) registerMiddlewares(h http.Handler) http.Handler {
-	for _, middleware := range r.Middlewares {
-		h = middleware(h)
+	return wrap(h, r.Middlewares)
+}
+
+// wrap wraps h by mws, mws[0] being the outermost middleware.
+func wrap(h http.Handler, mws []middleware.Middleware) http.Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
 	}
 
 	return h
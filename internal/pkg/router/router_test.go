@@ -0,0 +1,130 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/huboh/go-rest-api/internal/pkg/middleware"
+)
+
+// ok responds with the given status, to tell routes apart.
+func ok(status int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(status) })
+}
+
+func TestMount(t *testing.T) {
+	var (
+		root = New("", nil, nil)
+		sub  = New("/ignored", nil, []Route{{Path: "/{id}", Method: http.MethodGet, Handler: ok(http.StatusAccepted)}})
+	)
+
+	root.Mount("/users", sub)
+
+	for path, want := range map[string]int{
+		"/users/1":   http.StatusAccepted,
+		"/ignored/1": http.StatusNotFound,
+	} {
+		w := httptest.NewRecorder()
+		root.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+		if w.Code != want {
+			t.Errorf("GET %s = %d, want %d", path, w.Code, want)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("mounting a router twice didn't panic")
+		}
+	}()
+
+	New("", nil, nil).Mount("/members", sub)
+}
+
+// trace returns a middleware appending name to the X-Trace header of requests.
+func trace(name string) middleware.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			req.Header.Add("X-Trace", name)
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+// echoTrace responds with the X-Trace header of requests.
+var echoTrace = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+	w.Write([]byte(strings.Join(req.Header.Values("X-Trace"), ",")))
+})
+
+func TestMiddlewareOrder(t *testing.T) {
+	r := New("", []middleware.Middleware{trace("router")}, []Route{
+		{Path: "/route", Method: http.MethodGet, Handler: echoTrace, Middlewares: []middleware.Middleware{trace("route")}},
+	})
+
+	r.Use(trace("use"))
+	r.With(trace("with")).Add(Route{Path: "/with", Method: http.MethodGet, Handler: echoTrace, Middlewares: []middleware.Middleware{trace("route")}})
+
+	r.Group("/group", func(g *Router) {
+		g.Add(Route{Path: "/before", Method: http.MethodGet, Handler: echoTrace})
+		g.Use(trace("group"))
+		g.Add(Route{Path: "/after", Method: http.MethodGet, Handler: echoTrace, Middlewares: []middleware.Middleware{trace("route")}})
+	})
+
+	sub := New("", []middleware.Middleware{trace("sub")}, []Route{
+		{Path: "/", Method: http.MethodGet, Handler: echoTrace, Middlewares: []middleware.Middleware{trace("route")}},
+	})
+
+	r.Mount("/sub", sub)
+
+	tests := map[string][]string{
+		"/route":        {"router", "use", "route"},
+		"/with":         {"router", "use", "with", "route"},
+		"/group/before": {"router", "use"},
+		"/group/after":  {"router", "use", "group", "route"},
+		"/sub/":         {"router", "use", "sub", "route"},
+	}
+
+	for path, want := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+		if got := strings.Split(w.Body.String(), ","); !reflect.DeepEqual(got, want) {
+			t.Errorf("GET %s went through %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestWalkAuth(t *testing.T) {
+	var (
+		root  = New("", nil, nil)
+		users = New("/users", nil, []Route{
+			{Path: "/me", Method: http.MethodGet, Handler: ok(http.StatusOK)},
+			{Path: "/{id}", Method: http.MethodGet, Handler: ok(http.StatusOK), Auth: []string{"admin"}},
+		})
+	)
+
+	root.Mount("/users", users, Guarded("authenticated"))
+
+	got := map[string][]string{}
+
+	err := root.Walk(func(info RouteInfo) error {
+		got[info.Path] = info.Auth
+		return nil
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]string{
+		"/users/me":   {"authenticated"},
+		"/users/{id}": {"authenticated", "admin"},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Walk() auth = %v, want %v", got, want)
+	}
+}
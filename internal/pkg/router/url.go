@@ -0,0 +1,183 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrUnknownRoute is returned when building the url of a route name no route has
+var ErrUnknownRoute = errors.New("unknown route")
+
+// routeName identifies a named route among the routes of a router, names being unique per version.
+type routeName struct {
+	version string
+	name    string
+}
+
+// routerKey is the context key of the outermost router serving a request.
+type routerKey struct{}
+
+// fromContext returns the outermost router serving the request of ctx, if any.
+func fromContext(ctx context.Context) (*Router, bool) {
+	r, ok := ctx.Value(routerKey{}).(*Router)
+	return r, ok
+}
+
+// nameRoute records the path of route when it is named, and the paths of the named routes of the
+// router it mounts. it panics when a name is already taken, the same way ServeMux panics on
+// conflicting patterns.
+func (r *Router) nameRoute(route Route) {
+	if r.names == nil {
+		r.names = map[routeName]string{}
+	}
+
+	add := func(n routeName, path string) {
+		if _, ok := r.names[n]; ok {
+			if n.version != "" {
+				panic(fmt.Sprintf("router: duplicate route name %q in version %q", n.name, n.version))
+			}
+
+			panic(fmt.Sprintf("router: duplicate route name %q", n.name))
+		}
+
+		r.names[n] = path
+	}
+
+	if route.Name != "" {
+		add(routeName{version: route.version, name: route.Name}, joinPath(r.Prefix, route.Path))
+	}
+
+	if sub, ok := route.Handler.(*Router); ok {
+		for n, path := range sub.names {
+			if n.version == "" {
+				n.version = route.version
+			}
+
+			add(n, path)
+		}
+	}
+}
+
+// URL returns the path of the route of r named name, its wildcards replaced by params given as name and
+// value pairs, e.g. r.URL("v1", "user.getExport", "id", id). the routes of the version named version are looked up
+// first, then the unversioned ones. the routes of mounted routers must be named before they are mounted.
+//
+// it returns ErrUnknownRoute when no route is named name, and ErrInvalidParam when params miss a
+// wildcard, name an unknown one or hold a value its constraint rejects.
+func (r *Router) URL(version string, name string, params ...string) (string, error) {
+	path, ok := r.names[routeName{version: version, name: name}]
+
+	if !ok {
+		if path, ok = r.names[routeName{name: name}]; !ok {
+			return "", fmt.Errorf("%w %q", ErrUnknownRoute, name)
+		}
+	}
+
+	return fillPath(path, params)
+}
+
+// URL returns the path of the route named name of the router serving req, see Router.URL. the routes of
+// the version serving req are looked up first, or those of the default version of the router.
+func URL(req *http.Request, name string, params ...string) (string, error) {
+	r, ok := fromContext(req.Context())
+
+	if !ok {
+		return "", fmt.Errorf("%w %q: the request isn't served by a router", ErrUnknownRoute, name)
+	}
+
+	version := r.DefaultVersion
+
+	if v, ok := VersionFromContext(req.Context()); ok {
+		version = v.Name
+	}
+
+	return r.URL(version, name, params...)
+}
+
+// AbsoluteURL returns the url of the route named name of the router serving req, see URL, with the
+// scheme and host of req.
+func AbsoluteURL(req *http.Request, name string, params ...string) (string, error) {
+	path, err := URL(req, name, params...)
+
+	if err != nil {
+		return "", err
+	}
+
+	scheme := "http"
+
+	if req.TLS != nil {
+		scheme = "https"
+	}
+
+	// path is already escaped
+	return scheme + "://" + req.Host + path, nil
+}
+
+// fillPath replaces the wildcards of the route path p by params, given as name and value pairs.
+func fillPath(p string, params []string) (string, error) {
+	if len(params)%2 != 0 {
+		return "", fmt.Errorf("%w: missing the value of %q", ErrInvalidParam, params[len(params)-1])
+	}
+
+	var (
+		values   = map[string]string{}
+		segments = strings.Split(strings.TrimSuffix(p, "{$}"), "/")
+	)
+
+	for i := 0; i < len(params); i += 2 {
+		values[params[i]] = params[i+1]
+	}
+
+	for i, seg := range segments {
+		if !strings.HasPrefix(seg, "{") || !strings.HasSuffix(seg, "}") {
+			continue
+		}
+
+		var (
+			name, constraint, _ = strings.Cut(seg[1:len(seg)-1], ":")
+			rest                = strings.HasSuffix(name, "...")
+		)
+
+		name = strings.TrimSuffix(name, "...")
+		val, ok := values[name]
+
+		if !ok {
+			return "", fmt.Errorf("%w %q: missing", ErrInvalidParam, name)
+		}
+
+		if constraint != "" {
+			if p := newParam(name, constraint); !p.match(val) {
+				if p.typed {
+					return "", fmt.Errorf("%w %q: must be of type %s", ErrInvalidParam, name, constraint)
+				}
+
+				return "", fmt.Errorf("%w %q: must match %s", ErrInvalidParam, name, constraint)
+			}
+		}
+
+		if rest {
+			// the remaining segments, whose slashes are kept
+			parts := strings.Split(val, "/")
+
+			for j := range parts {
+				parts[j] = url.PathEscape(parts[j])
+			}
+
+			segments[i] = strings.Join(parts, "/")
+		} else {
+			segments[i] = url.PathEscape(val)
+		}
+
+		delete(values, name)
+	}
+
+	for name := range values {
+		return "", fmt.Errorf("%w %q: the route has no such wildcard", ErrInvalidParam, name)
+	}
+
+	return strings.Join(segments, "/"), nil
+}
@@ -0,0 +1,117 @@
+package router
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestURL(t *testing.T) {
+	var (
+		root  = New("/api", nil, nil)
+		users = New("", nil, []Route{
+			{Name: "user.get", Path: "/{id:uuid}", Method: http.MethodGet, Handler: ok(http.StatusOK)},
+			{Name: "user.file", Path: "/{id}/files/{path...}", Method: http.MethodGet, Handler: ok(http.StatusOK)},
+		})
+	)
+
+	root.Add(Route{Name: "healthz", Path: "/healthz", Method: http.MethodGet, Handler: ok(http.StatusOK)})
+
+	root.Version(Version{Name: "v1"}, func(g *Router) {
+		g.Group("/admin", func(g *Router) {
+			g.Add(Route{Name: "stats", Path: "/stats", Method: http.MethodGet, Handler: ok(http.StatusOK)})
+		})
+	})
+
+	root.Mount("/users", users)
+
+	const id = "6f1c7a52-8f0e-4a7e-9a53-3a3d0c8e6a10"
+
+	tests := []struct {
+		version string
+		name    string
+		params  []string
+		want    string
+		err     error
+	}{
+		{"", "healthz", nil, "/api/healthz", nil},
+		{"v1", "healthz", nil, "/api/healthz", nil},
+		{"v1", "stats", nil, "/api/v1/admin/stats", nil},
+		{"", "stats", nil, "", ErrUnknownRoute},
+		{"", "user.get", []string{"id", id}, "/api/users/" + id, nil},
+		{"", "user.get", []string{"id", "42"}, "", ErrInvalidParam},
+		{"", "user.get", nil, "", ErrInvalidParam},
+		{"", "user.get", []string{"id", id, "other", "1"}, "", ErrInvalidParam},
+		{"", "user.file", []string{"id", "a b", "path", "docs/a b.txt"}, "/api/users/a%20b/files/docs/a%20b.txt", nil},
+	}
+
+	for _, tt := range tests {
+		got, err := root.URL(tt.version, tt.name, tt.params...)
+
+		if (got != tt.want) || !errors.Is(err, tt.err) {
+			t.Errorf("URL(%q, %q, %q) = %q, %v, want %q, %v", tt.version, tt.name, tt.params, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestURLFromRequest(t *testing.T) {
+	var (
+		root = New("", nil, nil)
+		got  string
+	)
+
+	root.DefaultVersion = "v1"
+
+	root.Version(Version{Name: "v1"}, func(g *Router) {
+		g.Add(Route{Name: "self", Path: "/self", Method: http.MethodGet, Handler: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			got, _ = AbsoluteURL(req, "self")
+		})})
+	})
+
+	root.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://example.com/self", nil))
+
+	if want := "http://example.com/v1/self"; got != want {
+		t.Errorf("AbsoluteURL() = %q, want %q", got, want)
+	}
+}
+
+func TestDuplicateRouteNamesPanic(t *testing.T) {
+	tests := map[string]func(){
+		"same router": func() {
+			New("", nil, []Route{
+				{Name: "a", Path: "/a", Handler: ok(http.StatusOK)},
+				{Name: "a", Path: "/b", Handler: ok(http.StatusOK)},
+			})
+		},
+		"mounted router": func() {
+			r := New("", nil, []Route{{Name: "a", Path: "/a", Handler: ok(http.StatusOK)}})
+			r.Mount("/sub", New("", nil, []Route{{Name: "a", Path: "/a", Handler: ok(http.StatusOK)}}))
+		},
+		"same version": func() {
+			r := New("", nil, nil)
+
+			r.Version(Version{Name: "v1"}, func(g *Router) {
+				g.Add(Route{Name: "a", Path: "/a", Handler: ok(http.StatusOK)})
+				g.Add(Route{Name: "a", Path: "/b", Handler: ok(http.StatusOK)})
+			})
+		},
+	}
+
+	for name, fn := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("duplicate route names didn't panic")
+				}
+			}()
+
+			fn()
+		})
+	}
+
+	// versions have their own names
+	r := New("", nil, nil)
+	r.Version(Version{Name: "v1"}, func(g *Router) { g.Add(Route{Name: "a", Path: "/a", Handler: ok(http.StatusOK)}) })
+	r.Version(Version{Name: "v2"}, func(g *Router) { g.Add(Route{Name: "a", Path: "/a", Handler: ok(http.StatusOK)}) })
+}
@@ -0,0 +1,209 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/huboh/go-rest-api/internal/pkg/json"
+	"github.com/huboh/go-rest-api/internal/pkg/middleware"
+)
+
+// VersionHeader is the header naming the api version requests are served by, see Router.Version.
+const VersionHeader = "API-Version"
+
+// Version describes a version of an api, whose routes are prefixed by "/" + Name.
+type Version struct {
+	// Name is the version name, e.g. "v1".
+	Name string
+
+	// Deprecated is the time the version was deprecated at, zero when it isn't.
+	Deprecated time.Time
+
+	// Sunset is the time the version stops being served at, zero when it isn't planned.
+	Sunset time.Time
+
+	// Docs is the url of the docs about the deprecation of the version, e.g. a migration guide. it is
+	// only linked once the version is deprecated.
+	Docs string
+}
+
+// versionKey is the context key of the Version serving a request.
+type versionKey struct{}
+
+// VersionFromContext returns the Version serving the request of ctx, if any.
+func VersionFromContext(ctx context.Context) (Version, bool) {
+	v, ok := ctx.Value(versionKey{}).(Version)
+	return v, ok
+}
+
+// Version calls fn with a group of r whose routes are prefixed by "/" + v.Name, e.g. "/v1", and whose
+// responses name v in the API-Version header, along with its Deprecation, Sunset and Link headers.
+// the routes of deprecated versions are marked as such in the api docs.
+//
+// requests to unversioned paths are served by the version they name, in the API-Version header or
+// the version parameter of their Accept header, e.g. "application/json; version=2", or by the
+// DefaultVersion of r. versions are named with or without their "v", e.g. "2" or "v2". requests
+// naming an unknown version are rejected with a bad request status, and unversioned paths the
+// version doesn't serve are served by r as is.
+//
+// it panics when called on a group, or when r already has a version named v.Name.
+func (r *Router) Version(v Version, fn func(g *Router)) {
+	if r.parent != nil {
+		panic("router: versions can't be added to groups")
+	}
+
+	name := versionName(v.Name)
+
+	if _, ok := r.versions[name]; ok {
+		panic(fmt.Sprintf("router: duplicate version %q", v.Name))
+	}
+
+	if r.versions == nil {
+		r.versions = map[string]Version{}
+	}
+
+	r.versions[name] = v
+
+	fn(&Router{
+		Prefix:      "/" + v.Name,
+		Middlewares: []middleware.Middleware{v.serve},
+		version:     v,
+		parent:      r,
+	})
+}
+
+// serve is the middleware of the routes of v, storing v in the request context and setting its headers.
+func (v Version) serve(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, req *http.Request) {
+			h := w.Header()
+			h.Set(VersionHeader, v.Name)
+
+			if !v.Deprecated.IsZero() {
+				// RFC 9745 structured date
+				h.Set("Deprecation", fmt.Sprintf("@%d", v.Deprecated.Unix()))
+			}
+
+			if !v.Sunset.IsZero() {
+				// RFC 8594
+				h.Set("Sunset", v.Sunset.UTC().Format(http.TimeFormat))
+			}
+
+			if !v.Deprecated.IsZero() && (v.Docs != "") {
+				h.Add("Link", fmt.Sprintf("<%s>; rel=\"deprecation\"", v.Docs))
+			}
+
+			next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), versionKey{}, v)))
+		},
+	)
+}
+
+// selectVersion returns req, or a copy of it whose path is prefixed by the version it names when its path
+// is unversioned, see Version. it responds and returns nil when req names an unknown version and a
+// version serves its path.
+func (r *Router) selectVersion(w http.ResponseWriter, req *http.Request) *http.Request {
+	for _, v := range r.versions {
+		if hasPathPrefix(req.URL.Path, joinPath(r.Prefix, "/"+v.Name)) {
+			return req
+		}
+	}
+
+	// the route serving unversioned paths depends on these headers
+	w.Header().Add("Vary", VersionHeader+", Accept")
+
+	name := requestedVersion(req)
+
+	if name == "" {
+		name = r.DefaultVersion
+	}
+
+	if name == "" {
+		return req
+	}
+
+	v, ok := r.versions[versionName(name)]
+
+	if !ok {
+		// e.g. "/healthz", which no version serves
+		if !r.hasVersionedRoute(req) {
+			return req
+		}
+
+		json.Write(w, json.Response{
+			StatusCode: http.StatusBadRequest,
+			Error:      json.NewError(http.StatusText(http.StatusBadRequest), fmt.Sprintf("unsupported api version %q", name), "", ""),
+		})
+
+		return nil
+	}
+
+	if versioned, ok := r.versioned(req, v); ok {
+		return versioned
+	}
+
+	return req
+}
+
+// versioned returns a copy of req whose path is prefixed by v, and whether a route of r serves it.
+func (r *Router) versioned(req *http.Request, v Version) (*http.Request, bool) {
+	var (
+		u         = *req.URL
+		versioned = new(http.Request)
+	)
+
+	u.Path = joinPath(joinPath(r.Prefix, "/"+v.Name), strings.TrimPrefix(req.URL.Path, strings.TrimSuffix(r.Prefix, "/")))
+	u.RawPath = ""
+	*versioned = *req
+	versioned.URL = &u
+
+	_, pattern := r.mux.Handler(versioned)
+
+	return versioned, pattern != ""
+}
+
+// hasVersionedRoute reports whether a version of r serves the unversioned path of req.
+func (r *Router) hasVersionedRoute(req *http.Request) bool {
+	for _, v := range r.versions {
+		if _, ok := r.versioned(req, v); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// requestedVersion returns the version named by the API-Version header of req or the version parameter
+// of its Accept header, or an empty string.
+func requestedVersion(req *http.Request) string {
+	if v := strings.TrimSpace(req.Header.Get(VersionHeader)); v != "" {
+		return v
+	}
+
+	for _, accept := range strings.Split(req.Header.Get("Accept"), ",") {
+		if _, params, err := mime.ParseMediaType(accept); err == nil && params["version"] != "" {
+			return params["version"]
+		}
+	}
+
+	return ""
+}
+
+// versionName normalizes the version name, e.g. "2" and "V2" become "v2".
+func versionName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	if !strings.HasPrefix(name, "v") {
+		name = "v" + name
+	}
+
+	return name
+}
+
+// hasPathPrefix reports whether path is prefix or a path under it.
+func hasPathPrefix(path string, prefix string) bool {
+	return (path == prefix) || strings.HasPrefix(path, strings.TrimSuffix(prefix, "/")+"/")
+}
@@ -0,0 +1,86 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newVersionedRouter creates a router serving /healthz unversioned, and /users in the deprecated
+// version v1 and in v2, the default version.
+func newVersionedRouter() *Router {
+	r := New("", nil, []Route{{Path: "/healthz", Method: http.MethodGet, Handler: ok(http.StatusOK)}})
+	r.DefaultVersion = "v2"
+
+	r.Version(Version{Name: "v1", Deprecated: time.Unix(1700000000, 0), Docs: "https://example.com/v2"}, func(g *Router) {
+		g.Add(Route{Path: "/users", Method: http.MethodGet, Handler: ok(http.StatusAccepted)})
+	})
+
+	r.Version(Version{Name: "v2", Docs: "https://example.com/v2"}, func(g *Router) {
+		g.Add(Route{Path: "/users", Method: http.MethodGet, Handler: ok(http.StatusCreated)})
+	})
+
+	return r
+}
+
+func TestVersionSelection(t *testing.T) {
+	r := newVersionedRouter()
+
+	tests := []struct {
+		path    string
+		header  string
+		accept  string
+		want    int
+		version string
+	}{
+		{path: "/users", want: http.StatusCreated, version: "v2"},
+		{path: "/users", header: "1", want: http.StatusAccepted, version: "v1"},
+		{path: "/users", accept: "application/json; version=v1", want: http.StatusAccepted, version: "v1"},
+		{path: "/users", header: "3", want: http.StatusBadRequest},
+		{path: "/v1/users", header: "2", want: http.StatusAccepted, version: "v1"},
+		{path: "/healthz", header: "2", want: http.StatusOK},
+		{path: "/healthz", header: "3", want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		req.Header.Set(VersionHeader, tt.header)
+		req.Header.Set("Accept", tt.accept)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != tt.want {
+			t.Errorf("GET %s (version %q, accept %q) = %d, want %d", tt.path, tt.header, tt.accept, w.Code, tt.want)
+		}
+
+		if got := w.Header().Get(VersionHeader); got != tt.version {
+			t.Errorf("GET %s (version %q, accept %q) served by version %q, want %q", tt.path, tt.header, tt.accept, got, tt.version)
+		}
+	}
+}
+
+func TestVersionDeprecationHeaders(t *testing.T) {
+	r := newVersionedRouter()
+
+	for _, tt := range []struct {
+		path        string
+		deprecation string
+		link        string
+	}{
+		{"/v1/users", "@1700000000", `<https://example.com/v2>; rel="deprecation"`},
+		{"/v2/users", "", ""},
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+		if got := w.Header().Get("Deprecation"); got != tt.deprecation {
+			t.Errorf("GET %s Deprecation = %q, want %q", tt.path, got, tt.deprecation)
+		}
+
+		if got := w.Header().Get("Link"); got != tt.link {
+			t.Errorf("GET %s Link = %q, want %q", tt.path, got, tt.link)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package router
+
+import (
+	"reflect"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/huboh/go-rest-api/internal/pkg/middleware"
+)
+
+// RouteInfo describes a route served by a router, nested routers being flattened.
+type RouteInfo struct {
+	// Name is the route name, if any.
+	Name string `json:"name,omitempty"`
+
+	// Method is the route method, empty for routes serving every method.
+	Method string `json:"method,omitempty"`
+
+	// Path is the full route path as declared, wildcard constraints included.
+	Path string `json:"path"`
+
+	// Tags are the tags of the route and of the routes mounting its routers.
+	Tags []string `json:"tags"`
+
+	// Middlewares are the names of the router middlewares requests go through, outermost first.
+	Middlewares []string `json:"middlewares"`
+
+	// Auth are the requirements of the route and of the routes mounting its routers, outermost first, see Route.Auth.
+	Auth []string `json:"auth"`
+
+	// Pattern is the ServeMux pattern path of the route, without wildcard constraints.
+	Pattern string `json:"-"`
+
+	// PathParams are the wildcards of Path.
+	PathParams []PathParam `json:"-"`
+
+	// Summary, Request, Response and Params are those of the route.
+	Summary  string      `json:"-"`
+	Request  any         `json:"-"`
+	Response any         `json:"-"`
+	Params   []Parameter `json:"-"`
+
+	// Security are the security schemes of the route and of the routes mounting its routers.
+	Security []string `json:"-"`
+
+	// Deprecated reports whether the route, or a route mounting its routers, is deprecated.
+	Deprecated bool `json:"deprecated,omitempty"`
+
+	// Public reports whether the route, or a route mounting its routers, is public, see Route.Public.
+	Public bool `json:"public,omitempty"`
+}
+
+// describer is implemented by the handlers describing their requests and responses, see Handle.
+type describer interface {
+	describe() (req any, res any, params []Parameter)
+}
+
+// describe completes the docs metadata of info with the description of its handler h.
+// the metadata of routes takes precedence.
+func (info *RouteInfo) describe(h describer) {
+	req, res, params := h.describe()
+
+	if info.Request == nil {
+		info.Request = req
+	}
+
+	if info.Response == nil {
+		info.Response = res
+	}
+
+	for _, p := range params {
+		if !slices.ContainsFunc(info.Params, func(o Parameter) bool { return o.Name == p.Name }) {
+			info.Params = append(info.Params, p)
+		}
+	}
+}
+
+// PathParam is a wildcard of a route path.
+type PathParam struct {
+	// Name is the wildcard name, as given to Request.PathValue.
+	Name string
+
+	// Type is the type the wildcard is constrained to, e.g. "uuid". it is empty for unconstrained wildcards
+	// and for wildcards constrained by Pattern.
+	Type string
+
+	// Pattern is the regular expression the wildcard is constrained to, if any.
+	Pattern string
+}
+
+// WalkFunc is called by Walk for every route. returning an error stops the walk.
+type WalkFunc func(info RouteInfo) error
+
+// closureRegexp matches the suffixes runtime function names get for closures and method values
+var closureRegexp = regexp.MustCompile(`(\.func\d+)+$|-fm$`)
+
+// MiddlewareName returns the name of the function mw, e.g. "middleware.Logger" or "auth.(*Service).AuthGuardMiddleware".
+// middlewares returned by constructors are named after the constructor.
+func MiddlewareName(mw middleware.Middleware) string {
+	name := runtime.FuncForPC(reflect.ValueOf(mw).Pointer()).Name()
+	name = name[strings.LastIndex(name, "/")+1:]
+
+	return closureRegexp.ReplaceAllString(name, "")
+}
+
+// Walk calls fn for every route of r and of its nested routers, in registration order.
+func (r *Router) Walk(fn WalkFunc) error {
+	return r.walk(RouteInfo{Tags: []string{}, Middlewares: []string{}, Auth: []string{}}, fn)
+}
+
+// walk calls fn for the routes of r, mounted by the route described by parent.
+func (r *Router) walk(parent RouteInfo, fn WalkFunc) error {
+	mws := middlewareNames(parent.Middlewares, r.Middlewares)
+
+	for _, route := range r.Routes {
+		mws := middlewareNames(mws, route.Middlewares)
+
+		info := RouteInfo{
+			Name:        route.Name,
+			Method:      route.Method,
+			Path:        joinPath(r.Prefix, route.Path),
+			Tags:        append(slices.Clone(parent.Tags), route.Tag...),
+			Middlewares: mws,
+			Auth:        append(slices.Clone(parent.Auth), route.Auth...),
+			Summary:     route.Summary,
+			Request:     route.Request,
+			Response:    route.Response,
+			Params:      route.Params,
+			Security:    append(slices.Clone(parent.Security), route.Security...),
+			Deprecated:  parent.Deprecated || route.Deprecated,
+			Public:      parent.Public || route.Public,
+		}
+
+		info.Pattern, info.PathParams = pathParams(info.Path)
+
+		if h, ok := route.Handler.(describer); ok {
+			info.describe(h)
+		}
+
+		if sub, ok := route.Handler.(*Router); ok {
+			if err := sub.walk(info, fn); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if err := fn(info); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// middlewareNames returns the names of the middlewares requests go through, those of mws being appended to names.
+func middlewareNames(names []string, mws []middleware.Middleware) []string {
+	names = slices.Clone(names)
+
+	for _, mw := range mws {
+		names = append(names, MiddlewareName(mw))
+	}
+
+	return names
+}
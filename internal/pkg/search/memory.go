@@ -0,0 +1,258 @@
+package search
+
+import (
+	"cmp"
+	"context"
+	"html"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// defThreshold is the share of the query trigrams a field must contain to match.
+const defThreshold = 0.5
+
+// trigramSet is a set of trigrams.
+type trigramSet map[string]struct{}
+
+// indexedDoc is a Document along with the trigrams of its fields.
+type indexedDoc struct {
+	doc      Document
+	trigrams map[string]trigramSet
+}
+
+// MemoryIndex is an in-process trigram Index. it matches partial words and tolerates typos:
+// a field matches when it contains at least half of the trigrams of the query, and documents
+// are scored by the weighted share of query trigrams found in each of their fields.
+type MemoryIndex struct {
+	mu       sync.RWMutex
+	weights  map[string]float64
+	docs     map[string]indexedDoc
+	postings map[string]map[string]struct{}
+}
+
+// NewMemoryIndex creates an empty MemoryIndex scoring matches in each field by its weight in weights.
+// fields missing from weights have a weight of 1.
+func NewMemoryIndex(weights map[string]float64) *MemoryIndex {
+	return &MemoryIndex{
+		weights:  weights,
+		docs:     map[string]indexedDoc{},
+		postings: map[string]map[string]struct{}{},
+	}
+}
+
+func (m *MemoryIndex) Index(ctx context.Context, doc Document) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.remove(doc.Id)
+
+	idoc := indexedDoc{doc: doc, trigrams: map[string]trigramSet{}}
+
+	for field, text := range doc.Fields {
+		idoc.trigrams[field] = trigrams(text)
+
+		for t := range idoc.trigrams[field] {
+			if m.postings[t] == nil {
+				m.postings[t] = map[string]struct{}{}
+			}
+
+			m.postings[t][doc.Id] = struct{}{}
+		}
+	}
+
+	m.docs[doc.Id] = idoc
+
+	return nil
+}
+
+func (m *MemoryIndex) Remove(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.remove(id)
+
+	return nil
+}
+
+func (m *MemoryIndex) Search(ctx context.Context, q Query) (Result, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	qgrams := trigrams(q.Text)
+	hits := []Hit{}
+
+	if len(qgrams) == 0 {
+		return Result{Hits: hits}, nil
+	}
+
+	candidates := map[string]struct{}{}
+
+	for t := range qgrams {
+		for id := range m.postings[t] {
+			candidates[id] = struct{}{}
+		}
+	}
+
+	for id := range candidates {
+		var (
+			best  float64
+			score float64
+		)
+
+		for field, fgrams := range m.docs[id].trigrams {
+			n := 0
+
+			for t := range qgrams {
+				if _, ok := fgrams[t]; ok {
+					n++
+				}
+			}
+
+			share := float64(n) / float64(len(qgrams))
+			best = max(best, share)
+			score += share * m.weight(field)
+		}
+
+		if best >= defThreshold {
+			hits = append(hits, Hit{Id: id, Score: score})
+		}
+	}
+
+	slices.SortFunc(hits, func(a, b Hit) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.Id, b.Id)
+	})
+
+	total := len(hits)
+	start := min(max(q.Offset, 0), total)
+	hits = hits[start:min(start+max(q.Limit, 0), total)]
+
+	for i := range hits {
+		hits[i].Highlights = highlights(m.docs[hits[i].Id].doc, q.Text)
+	}
+
+	return Result{Hits: hits, Total: total}, nil
+}
+
+// remove removes the document with the given id and its postings. callers must hold m.mu.
+func (m *MemoryIndex) remove(id string) {
+	idoc, ok := m.docs[id]
+
+	if !ok {
+		return
+	}
+
+	for _, fgrams := range idoc.trigrams {
+		for t := range fgrams {
+			delete(m.postings[t], id)
+
+			if len(m.postings[t]) == 0 {
+				delete(m.postings, t)
+			}
+		}
+	}
+
+	delete(m.docs, id)
+}
+
+// weight returns the weight of field.
+func (m *MemoryIndex) weight(field string) float64 {
+	if w, ok := m.weights[field]; ok {
+		return w
+	}
+
+	return 1
+}
+
+// words splits text into its lowercased words.
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// trigrams returns the trigrams of the words of text. words are padded with two leading
+// spaces and a trailing one, so short words and word boundaries produce trigrams too.
+func trigrams(text string) trigramSet {
+	set := trigramSet{}
+
+	for _, w := range words(text) {
+		r := []rune("  " + w + " ")
+
+		for i := 0; i+3 <= len(r); i++ {
+			set[string(r[i:i+3])] = struct{}{}
+		}
+	}
+
+	return set
+}
+
+// highlights returns the fields of doc containing words of text, html escaped and with the words highlighted.
+func highlights(doc Document, text string) map[string]string {
+	hl := map[string]string{}
+	qwords := words(text)
+
+	for field, val := range doc.Fields {
+		if h, ok := highlight(val, qwords); ok {
+			hl[field] = h
+		}
+	}
+
+	return hl
+}
+
+// highlight surrounds the case insensitive occurrences of qwords in text with
+// HighlightStart and HighlightEnd. it reports whether any word occurred.
+func highlight(text string, qwords []string) (string, bool) {
+	var (
+		runes  = []rune(text)
+		lower  = []rune(strings.ToLower(text))
+		marked = make([]bool, len(runes))
+		found  bool
+	)
+
+	if len(lower) != len(runes) {
+		return "", false
+	}
+
+	for _, w := range qwords {
+		wr := []rune(w)
+
+		for i := 0; i+len(wr) <= len(lower); i++ {
+			if string(lower[i:i+len(wr)]) != w {
+				continue
+			}
+
+			found = true
+
+			for j := i; j < i+len(wr); j++ {
+				marked[j] = true
+			}
+		}
+	}
+
+	if !found {
+		return "", false
+	}
+
+	var sb strings.Builder
+
+	for i, r := range runes {
+		if marked[i] && ((i == 0) || !marked[i-1]) {
+			sb.WriteString(HighlightStart)
+		}
+
+		sb.WriteString(html.EscapeString(string(r)))
+
+		if marked[i] && ((i == len(runes)-1) || !marked[i+1]) {
+			sb.WriteString(HighlightEnd)
+		}
+	}
+
+	return sb.String(), true
+}
@@ -0,0 +1,137 @@
+package search
+
+import (
+	"context"
+	"html"
+	"strings"
+
+	"github.com/huboh/go-rest-api/internal/pkg/database"
+)
+
+const (
+	// headlineStart and headlineEnd surround the matches of headlines until they are html escaped and
+	// replaced by HighlightStart and HighlightEnd. they are private use characters, which fields don't hold.
+	headlineStart = "\ue000"
+	headlineEnd   = "\ue001"
+
+	// headlineOptions are the ts_headline options highlighting every match of the query.
+	headlineOptions = "StartSel=" + headlineStart + ", StopSel=" + headlineEnd + ", HighlightAll=true"
+)
+
+// headlineReplacer replaces the markers of escaped headlines by HighlightStart and HighlightEnd.
+var headlineReplacer = strings.NewReplacer(headlineStart, HighlightStart, headlineEnd, HighlightEnd)
+
+// PostgresIndex is an Index using the native full-text search of postgres over the rows of a table.
+//
+// The table must have an "id" column and a tsvector column of its searchable fields, typically a
+// generated column covered by a GIN index. postgres keeps that column up to date, so Index and Remove
+// are no-ops. Every word of the query is matched as a prefix and hits are ranked by ts_rank.
+// Like MemoryIndex, highlighted fields are html escaped.
+type PostgresIndex struct {
+	db     *database.DB
+	table  string
+	vector string
+	filter string
+	fields []string
+}
+
+// NewPostgresIndex creates a PostgresIndex searching the vector column of the rows of table matching
+// the sql condition filter, e.g. "deleted_at IS NULL", and highlighting the columns in fields. every
+// row is searched when filter is empty. table, vector, filter and fields must be trusted sql.
+func NewPostgresIndex(db *database.DB, table string, vector string, filter string, fields ...string) *PostgresIndex {
+	return &PostgresIndex{
+		db:     db,
+		table:  table,
+		vector: vector,
+		filter: filter,
+		fields: fields,
+	}
+}
+
+func (p *PostgresIndex) Index(ctx context.Context, doc Document) error {
+	return nil
+}
+
+func (p *PostgresIndex) Remove(ctx context.Context, id string) error {
+	return nil
+}
+
+func (p *PostgresIndex) Search(ctx context.Context, q Query) (Result, error) {
+	tsquery := prefixQuery(q.Text)
+	result := Result{Hits: []Hit{}}
+
+	if tsquery == "" {
+		return result, nil
+	}
+
+	var (
+		exec = p.db.Executor(ctx)
+		from = " FROM " + p.table + ", to_tsquery('simple', $1) q WHERE " + p.vector + " @@ q"
+	)
+
+	if p.filter != "" {
+		// rows the filter excludes are neither counted nor take the place of hits
+		from += " AND (" + p.filter + ")"
+	}
+
+	// the total is counted apart, pages past the last having no rows to count it over
+	if err := exec.QueryRowContext(ctx, "SELECT COUNT(*)"+from, tsquery).Scan(&result.Total); err != nil {
+		return result, err
+	}
+
+	cols := []string{"id", "ts_rank(" + p.vector + ", q)"}
+
+	for _, f := range p.fields {
+		cols = append(cols, "ts_headline('simple', "+f+", q, '"+headlineOptions+"')")
+	}
+
+	rows, err := exec.QueryContext(
+		ctx,
+		"SELECT "+strings.Join(cols, ", ")+from+" ORDER BY 2 DESC, id LIMIT $2 OFFSET $3",
+		tsquery, max(q.Limit, 0), max(q.Offset, 0),
+	)
+
+	if err != nil {
+		return result, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			hit       = Hit{Highlights: map[string]string{}}
+			headlines = make([]string, len(p.fields))
+			dest      = []any{&hit.Id, &hit.Score}
+		)
+
+		for i := range headlines {
+			dest = append(dest, &headlines[i])
+		}
+
+		if err := rows.Scan(dest...); err != nil {
+			return result, err
+		}
+
+		for i, h := range headlines {
+			if strings.Contains(h, headlineStart) {
+				hit.Highlights[p.fields[i]] = headlineReplacer.Replace(html.EscapeString(h))
+			}
+		}
+
+		result.Hits = append(result.Hits, hit)
+	}
+
+	return result, rows.Err()
+}
+
+// prefixQuery returns a tsquery matching documents containing every word of text as a prefix.
+// words only hold letters and digits, so they can be quoted as is.
+func prefixQuery(text string) string {
+	terms := []string{}
+
+	for _, w := range words(text) {
+		terms = append(terms, "'"+w+"':*")
+	}
+
+	return strings.Join(terms, " & ")
+}
@@ -0,0 +1,60 @@
+// Package search provides full-text search indexes.
+package search
+
+import "context"
+
+const (
+	// HighlightStart and HighlightEnd surround the matched terms of highlighted fields.
+	HighlightStart = "<mark>"
+	HighlightEnd   = "</mark>"
+)
+
+// Document is an item to index, made of named text fields.
+type Document struct {
+	Id     string
+	Fields map[string]string
+}
+
+// Query selects the documents to search for.
+type Query struct {
+	// Text is the searched text. documents must match its words, partially or as prefixes depending on the Index.
+	Text string
+
+	// Limit is the maximum number of hits returned.
+	Limit int
+
+	// Offset is the number of hits skipped.
+	Offset int
+}
+
+// Hit is a document matching a Query.
+type Hit struct {
+	Id string
+
+	// Score is the relevance of the document. hits are ordered by decreasing score.
+	Score float64
+
+	// Highlights are the fields of the document containing matches, with the matched terms
+	// surrounded by HighlightStart and HighlightEnd.
+	Highlights map[string]string
+}
+
+// Result holds a page of hits.
+type Result struct {
+	Hits []Hit
+
+	// Total is the number of hits across all pages.
+	Total int
+}
+
+// Index indexes documents and searches them.
+type Index interface {
+	// Index adds doc to the index, replacing the document with the same id.
+	Index(ctx context.Context, doc Document) error
+
+	// Remove removes the document with the given id. removing a missing document isn't an error.
+	Remove(ctx context.Context, id string) error
+
+	// Search returns the page of documents matching q, ranked by relevance.
+	Search(ctx context.Context, q Query) (Result, error)
+}
@@ -0,0 +1,114 @@
+package search
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/huboh/go-rest-api/internal/pkg/database"
+	"github.com/huboh/go-rest-api/internal/pkg/database/dbtest"
+)
+
+func TestMemoryIndex(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		index = NewMemoryIndex(map[string]float64{"name": 2})
+	)
+
+	docs := []Document{
+		{Id: "1", Fields: map[string]string{"name": "Jane <script>", "email": "jane@example.com"}},
+		{Id: "2", Fields: map[string]string{"name": "Janet", "email": "janet@example.com"}},
+		{Id: "3", Fields: map[string]string{"name": "John", "email": "john@example.com"}},
+	}
+
+	for _, doc := range docs {
+		if err := index.Index(ctx, doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testIndex(t, index)
+
+	if err := index.Remove(ctx, "1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if res, _ := index.Search(ctx, Query{Text: "jane", Limit: 10}); (res.Total != 1) || (res.Hits[0].Id != "2") {
+		t.Errorf("Search() after Remove() = %+v, want janet only", res)
+	}
+}
+
+func TestPostgresIndex(t *testing.T) {
+	var (
+		ctx = context.Background()
+		db  = dbtest.Open(t, database.DriverPostgres)
+		now = time.Now()
+	)
+
+	for id, u := range map[string][2]string{
+		"00000000-0000-0000-0000-000000000001": {"Jane <script>", "jane"},
+		"00000000-0000-0000-0000-000000000002": {"Janet", "janet"},
+		"00000000-0000-0000-0000-000000000003": {"John", "john"},
+		"00000000-0000-0000-0000-000000000004": {"Janelle", "janelle"},
+	} {
+		_, err := db.ExecContext(
+			ctx,
+			"INSERT INTO users (id, name, email, username, password_hash, created_at, updated_at) VALUES ($1, $2, $3, $4, '', $5, $5)",
+			id, u[0], u[1]+"@example.com", u[1], now,
+		)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// filtered out of the results and their total
+	if _, err := db.ExecContext(ctx, "UPDATE users SET deleted_at = $1 WHERE username = 'janelle'", now); err != nil {
+		t.Fatal(err)
+	}
+
+	testIndex(t, NewPostgresIndex(db, "users", "search", "deleted_at IS NULL", "name", "email"))
+}
+
+// testIndex checks the search results of index, holding the documents of jane, janet and john.
+func testIndex(t *testing.T, index Index) {
+	t.Helper()
+
+	ctx := context.Background()
+	res, err := index.Search(ctx, Query{Text: "jane", Limit: 1})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if (res.Total != 2) || (len(res.Hits) != 1) {
+		t.Fatalf("Search() of the first page = %d hits, total %d, want 1 hit, total 2", len(res.Hits), res.Total)
+	}
+
+	res, err = index.Search(ctx, Query{Text: "jane", Limit: 10, Offset: 5})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if (res.Total != 2) || (len(res.Hits) != 0) {
+		t.Errorf("Search() past the last page = %d hits, total %d, want no hits, total 2", len(res.Hits), res.Total)
+	}
+
+	res, err = index.Search(ctx, Query{Text: "script", Limit: 10})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res.Hits) != 1 {
+		t.Fatalf("Search() = %+v, want jane only", res)
+	}
+
+	name := res.Hits[0].Highlights["name"]
+
+	if strings.Contains(name, "<script>") || !strings.Contains(name, HighlightStart) || !strings.Contains(name, "&lt;") {
+		t.Errorf("highlighted name = %q, want it escaped with its match highlighted", name)
+	}
+}
@@ -0,0 +1,147 @@
+// Package tenant carries the active tenant of requests, so data owned by tenants can be scoped to it.
+//
+// The tenant of a request is resolved, in order, from the tenant claim of its access token, a header
+// and the subdomain of its host. Resolving only names a candidate, the app package owning tenants
+// verifies that the user belongs to it before storing it with ContextWithTenant. a request naming a
+// tenant in its header or subdomain other than the one claimed by its token is rejected.
+package tenant
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/huboh/go-rest-api/internal/pkg/env"
+)
+
+var (
+	// ErrNoTenant is returned when tenant scoped data is accessed without an active tenant
+	ErrNoTenant = errors.New("no active tenant")
+
+	// ErrTenantMismatch is returned when a request names a tenant other than the one of its access token
+	ErrTenantMismatch = errors.New("requested tenant doesn't match the tenant of the access token")
+)
+
+// defHeader is the default header naming the tenant of a request.
+const defHeader = "X-Tenant-ID"
+
+type tenantKey string
+
+const (
+	tenant = tenantKey("tenant")
+	claim  = tenantKey("claim")
+)
+
+// Source is where a request names its tenant.
+type Source string
+
+// recognized Source
+const (
+	SourceHeader    = Source("header")
+	SourceSubdomain = Source("subdomain")
+)
+
+// Request is the tenant named by a request.
+type Request struct {
+	// Claim is the id of the tenant claimed by the access token of the request, if any.
+	Claim string
+
+	// Ref is the id or slug of the tenant named by the header or the subdomain of the request, if any.
+	Ref string
+
+	// Source is where Ref was found.
+	Source Source
+}
+
+// ContextWithTenant returns a copy of c in which id is the active tenant.
+func ContextWithTenant(c context.Context, id string) context.Context {
+	return context.WithValue(c, tenant, id)
+}
+
+// FromContext returns the active tenant of ctx, if any.
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(tenant).(string)
+	return id, ok && (id != "")
+}
+
+// Require returns the active tenant of ctx, or ErrNoTenant. tenant scoped repositories use it
+// to filter every query, so data can't be read or written outside of the active tenant.
+func Require(ctx context.Context) (string, error) {
+	id, ok := FromContext(ctx)
+
+	if !ok {
+		return "", ErrNoTenant
+	}
+
+	return id, nil
+}
+
+// ContextWithClaim returns a copy of c holding id, the tenant claim of the request's access token.
+func ContextWithClaim(c context.Context, id string) context.Context {
+	return context.WithValue(c, claim, id)
+}
+
+// ClaimFromContext returns the tenant claim stored by ContextWithClaim, if any.
+func ClaimFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(claim).(string)
+	return id, ok && (id != "")
+}
+
+// Resolver finds the tenant named by requests.
+type Resolver struct {
+	// Header is the header naming the tenant.
+	Header string
+
+	// BaseDomain is the domain tenant subdomains belong to, e.g. "example.com" for "acme.example.com".
+	// subdomains are ignored when it is empty.
+	BaseDomain string
+}
+
+// NewResolver initializes a new Resolver by reading environment variables, falling back to defaults when they are unset.
+func NewResolver() *Resolver {
+	r := &Resolver{
+		Header:     defHeader,
+		BaseDomain: strings.ToLower(strings.Trim(env.Get("TENANT_BASE_DOMAIN"), ".")),
+	}
+
+	if h := env.Get("TENANT_HEADER"); h != "" {
+		r.Header = h
+	}
+
+	return r
+}
+
+// Resolve returns the tenant named by r. the header wins over the subdomain.
+func (res *Resolver) Resolve(r *http.Request) Request {
+	req := Request{}
+	req.Claim, _ = ClaimFromContext(r.Context())
+
+	if h := strings.TrimSpace(r.Header.Get(res.Header)); h != "" {
+		req.Ref, req.Source = h, SourceHeader
+	} else if sub := res.subdomain(r.Host); sub != "" {
+		req.Ref, req.Source = sub, SourceSubdomain
+	}
+
+	return req
+}
+
+// subdomain returns the label of host directly below the base domain, if any.
+func (res *Resolver) subdomain(host string) string {
+	if res.BaseDomain == "" {
+		return ""
+	}
+
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	sub, ok := strings.CutSuffix(strings.ToLower(host), "."+res.BaseDomain)
+
+	if !ok || (sub == "") || strings.Contains(sub, ".") {
+		return ""
+	}
+
+	return sub
+}
@@ -0,0 +1,109 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// errInvalidParam is the panic value of rules whose parameter is invalid
+	errInvalidParam = errors.New("validate: invalid rule parameter")
+
+	// uuidRegexp matches uuids in their canonical textual form
+	uuidRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// email checks that strings are bare email addresses, e.g. "jane@example.com" but not "Jane <jane@example.com>".
+func email(v reflect.Value, _ string) error {
+	if v.Kind() == reflect.String {
+		if addr, err := mail.ParseAddress(v.String()); (err == nil) && (addr.Address == v.String()) {
+			return nil
+		}
+	}
+
+	return errors.New("must be a valid email address")
+}
+
+// uuid checks that strings are uuids.
+func uuid(v reflect.Value, _ string) error {
+	if (v.Kind() == reflect.String) && uuidRegexp.MatchString(v.String()) {
+		return nil
+	}
+
+	return errors.New("must be a valid uuid")
+}
+
+// minimum checks that strings have at least param characters, slices and maps param elements, and
+// numbers are at least param.
+func minimum(v reflect.Value, param string) error {
+	return compare(v, param, func(n, bound float64) bool { return n >= bound }, "at least")
+}
+
+// maximum checks that strings have at most param characters, slices and maps param elements, and
+// numbers are at most param.
+func maximum(v reflect.Value, param string) error {
+	return compare(v, param, func(n, bound float64) bool { return n <= bound }, "at most")
+}
+
+// length checks that strings have exactly param characters, and slices and maps param elements.
+func length(v reflect.Value, param string) error {
+	return compare(v, param, func(n, bound float64) bool { return n == bound }, "exactly")
+}
+
+// oneOf checks that values are one of the space separated values of param, e.g. "oneof=admin member".
+func oneOf(v reflect.Value, param string) error {
+	allowed := strings.Fields(param)
+
+	if slices.Contains(allowed, fmt.Sprint(v.Interface())) {
+		return nil
+	}
+
+	return fmt.Errorf("must be one of %s", strings.Join(allowed, ", "))
+}
+
+// compare checks the size of v, the length of strings, slices and maps or the value of numbers, against
+// the bound param with ok. qualifier describes the comparison in messages, e.g. "at least".
+func compare(v reflect.Value, param string, ok func(n, bound float64) bool, qualifier string) error {
+	bound, err := strconv.ParseFloat(param, 64)
+
+	if err != nil {
+		panic(fmt.Errorf("%w %q", errInvalidParam, param))
+	}
+
+	var (
+		n    float64
+		unit string
+	)
+
+	switch v.Kind() {
+	case reflect.String:
+		n, unit = float64(utf8.RuneCountInString(v.String())), "characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		n, unit = float64(v.Len()), "items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		n = v.Float()
+	default:
+		panic(fmt.Sprintf("validate: can't compare the size of %s", v.Type()))
+	}
+
+	if ok(n, bound) {
+		return nil
+	}
+
+	if unit == "" {
+		return fmt.Errorf("must be %s %s", qualifier, param)
+	}
+
+	return fmt.Errorf("must have %s %s %s", qualifier, param, unit)
+}
@@ -0,0 +1,292 @@
+// Package validate validates structs according to the rules of their `validate` field tags, e.g.
+//
+//	Email    string `json:"email" validate:"required,email"`
+//	Password string `json:"password" validate:"required,min=8"`
+//
+// rules are separated by commas, their parameter following "=". fields without the required rule are
+// optional, their other rules are only checked when they aren't empty. pointer fields tagged omitnil are
+// only validated when they aren't nil, e.g. the fields of partial updates, which may be left out but
+// not set empty:
+//
+//	Name *string `json:"name" validate:"omitnil,required"`
+//
+// nested structs, and slices of structs, are validated as well.
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FieldError describes an invalid field.
+type FieldError struct {
+	// Field is the path of the field, named after its json name, e.g. "email" or "members[0].role".
+	Field string `json:"field"`
+
+	// Code is the name of the rule the field breaks, e.g. "required".
+	Code string `json:"code"`
+
+	// Message describes the value the rule expects.
+	Message string `json:"message"`
+}
+
+// Errors lists the invalid fields of a struct. it is the error returned by Struct.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+
+	for i, f := range e {
+		msgs[i] = fmt.Sprintf("%s %s", f.Field, f.Message)
+	}
+
+	return strings.Join(msgs, ", ")
+}
+
+// Rule checks the value v of a field, param being the parameter of the rule, e.g. "8" for "min=8".
+// the message of the returned error describes the expected value, e.g. "must be a valid email address".
+// v is never a pointer, pointers are dereferenced first.
+type Rule func(v reflect.Value, param string) error
+
+var (
+	rulesMu sync.RWMutex
+
+	// rules are the rules fields can be tagged with, by name
+	rules = map[string]Rule{
+		"email": email,
+		"min":   minimum,
+		"max":   maximum,
+		"len":   length,
+		"oneof": oneOf,
+		"uuid":  uuid,
+	}
+)
+
+// Register makes rule usable in tags under name, replacing any rule with the same name.
+func Register(name string, rule Rule) {
+	if (name == "") || (name == "required") || (name == "omitnil") || strings.ContainsAny(name, ",=") {
+		panic(fmt.Sprintf("validate: invalid rule name %q", name))
+	}
+
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+
+	rules[name] = rule
+}
+
+// Struct validates v, a struct or a pointer to a struct, returning the Errors of its invalid fields or nil.
+// it panics when a tag names an unknown rule.
+func Struct(v any) error {
+	rv := reflect.ValueOf(v)
+
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+
+	errs := Errors{}
+	validateStruct(rv, "", &errs)
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return errs
+}
+
+// Check returns an error when the tags of t, a struct or a pointer to a struct, or of the structs it
+// nests, name unknown rules. it lets callers reject invalid tags once rather than when validating values.
+func Check(t reflect.Type) error {
+	return checkType(t, map[reflect.Type]bool{})
+}
+
+// checkType returns an error when the tags of t or of the structs it nests name unknown rules. checked
+// holds the struct types already checked, which may nest themselves.
+func checkType(t reflect.Type, checked map[reflect.Type]bool) error {
+	for (t.Kind() == reflect.Pointer) || (t.Kind() == reflect.Slice) || (t.Kind() == reflect.Array) {
+		t = t.Elem()
+	}
+
+	if (t.Kind() != reflect.Struct) || (t == reflect.TypeFor[time.Time]()) || checked[t] {
+		return nil
+	}
+
+	checked[t] = true
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		if (!f.IsExported() && !f.Anonymous) || (f.Tag.Get("json") == "-") {
+			continue
+		}
+
+		for _, r := range strings.Split(f.Tag.Get("validate"), ",") {
+			name, _, _ := strings.Cut(strings.TrimSpace(r), "=")
+
+			if (name == "") || (name == "required") || (name == "omitnil") {
+				continue
+			}
+
+			rulesMu.RLock()
+			_, ok := rules[name]
+			rulesMu.RUnlock()
+
+			if !ok {
+				return fmt.Errorf("validate: unknown rule %q in the tag of field %s of %s", name, f.Name, t)
+			}
+		}
+
+		if err := checkType(f.Type, checked); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateStruct appends the errors of the fields of the struct value v to errs, prefixing their path with prefix.
+func validateStruct(v reflect.Value, prefix string, errs *Errors) {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+
+		if name == "-" {
+			continue
+		}
+
+		if f.Anonymous && (name == "") {
+			if fv := indirect(v.Field(i)); fv.IsValid() && (fv.Kind() == reflect.Struct) {
+				validateStruct(fv, prefix, errs)
+			}
+
+			continue
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		validateField(v.Field(i), prefix+name, f.Tag.Get("validate"), errs)
+	}
+}
+
+// validateField appends the errors of the field value v at path to errs, checking the rules of tag.
+func validateField(v reflect.Value, path string, tag string, errs *Errors) {
+	var (
+		fv       = indirect(v)
+		required = false
+		omitNil  = false
+		checks   = []string{}
+	)
+
+	for _, r := range strings.Split(tag, ",") {
+		switch r = strings.TrimSpace(r); r {
+		case "":
+		case "required":
+			required = true
+		case "omitnil":
+			omitNil = true
+		default:
+			checks = append(checks, r)
+		}
+	}
+
+	if omitNil && !fv.IsValid() {
+		return
+	}
+
+	if isEmpty(fv) {
+		switch {
+		case required && fv.IsValid():
+			*errs = append(*errs, FieldError{Field: path, Code: "required", Message: "must not be empty"})
+		case required:
+			*errs = append(*errs, FieldError{Field: path, Code: "required", Message: "is required"})
+		}
+
+		return
+	}
+
+	for _, check := range checks {
+		name, param, _ := strings.Cut(check, "=")
+
+		if err := lookup(name)(fv, param); err != nil {
+			*errs = append(*errs, FieldError{Field: path, Code: name, Message: err.Error()})
+		}
+	}
+
+	switch {
+	case (fv.Kind() == reflect.Struct) && (fv.Type() != reflect.TypeFor[time.Time]()):
+		validateStruct(fv, path+".", errs)
+
+	case (fv.Kind() == reflect.Slice) || (fv.Kind() == reflect.Array):
+		for i := 0; i < fv.Len(); i++ {
+			if elem := indirect(fv.Index(i)); elem.IsValid() && (elem.Kind() == reflect.Struct) {
+				validateStruct(elem, fmt.Sprintf("%s[%d].", path, i), errs)
+			}
+		}
+	}
+}
+
+// lookup returns the rule named name. it panics when there is none.
+func lookup(name string) Rule {
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+
+	rule, ok := rules[name]
+
+	if !ok {
+		panic(fmt.Sprintf("validate: unknown rule %q", name))
+	}
+
+	return rule
+}
+
+// indirect dereferences v until it isn't a pointer. it returns the zero Value for nil pointers.
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+
+		v = v.Elem()
+	}
+
+	return v
+}
+
+// isEmpty reports whether v is missing: the zero Value of nil pointers, a blank string, an empty
+// slice or map, or the zero value of other kinds.
+func isEmpty(v reflect.Value) bool {
+	if !v.IsValid() {
+		return true
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(v.String()) == ""
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		// false and 0 are values of their own, only pointers to them can be missing
+		return false
+	}
+
+	return v.IsZero()
+}
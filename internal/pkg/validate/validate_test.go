@@ -0,0 +1,115 @@
+package validate
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type member struct {
+	Id   string `json:"id" validate:"required,uuid"`
+	Role string `json:"role" validate:"oneof=admin member"`
+}
+
+type org struct {
+	Name     string     `json:"name" validate:"required,min=3,max=8"`
+	Email    *string    `json:"email" validate:"email"`
+	Seats    *int       `json:"seats" validate:"required,min=1"`
+	Members  []member   `json:"members" validate:"max=2"`
+	Owner    *member    `json:"owner"`
+	Since    time.Time  `json:"since"`
+	Internal string     `json:"-" validate:"required"`
+	Parent   *org       `json:"parent"`
+	Expires  *time.Time `json:"expires"`
+}
+
+func TestStruct(t *testing.T) {
+	var (
+		email = "jane"
+		zero  = 0
+	)
+
+	err := Struct(&org{
+		Name:    "ab",
+		Email:   &email,
+		Seats:   &zero,
+		Members: []member{{Id: "6f1c7a52-8f0e-4a7e-9a53-3a3d0c8e6a10", Role: "admin"}, {Role: "owner"}},
+		Owner:   &member{Id: "1"},
+	})
+
+	var errs Errors
+
+	if !errors.As(err, &errs) {
+		t.Fatalf("Struct() = %v, want Errors", err)
+	}
+
+	got := []FieldError{}
+
+	for _, f := range errs {
+		got = append(got, FieldError{Field: f.Field, Code: f.Code})
+	}
+
+	want := []FieldError{
+		{Field: "name", Code: "min"},
+		{Field: "email", Code: "email"},
+		{Field: "seats", Code: "min"},
+		{Field: "members[1].id", Code: "required"},
+		{Field: "members[1].role", Code: "oneof"},
+		{Field: "owner.id", Code: "uuid"},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Struct() fields = %+v, want %+v", got, want)
+	}
+
+	if err := Struct(org{Name: "acme", Seats: &[]int{1}[0]}); err != nil {
+		t.Errorf("Struct() of a valid org = %v, want nil", err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	if err := Check(reflect.TypeFor[*org]()); err != nil {
+		t.Errorf("Check() = %v, want nil", err)
+	}
+
+	type unknown struct {
+		Members []struct {
+			Role string `validate:"required,role"`
+		}
+	}
+
+	if err := Check(reflect.TypeFor[unknown]()); err == nil {
+		t.Errorf("Check() of an unknown rule = nil, want an error")
+	}
+}
+
+func TestStructOmitNil(t *testing.T) {
+	type update struct {
+		Name  *string `json:"name" validate:"omitnil,required"`
+		Email *string `json:"email" validate:"omitnil,required,email"`
+	}
+
+	if err := Struct(update{}); err != nil {
+		t.Errorf("Struct() of nil fields = %v, want nil", err)
+	}
+
+	var (
+		blank = " "
+		email = "jane"
+		errs  Errors
+	)
+
+	if err := Struct(update{Name: &blank, Email: &email}); !errors.As(err, &errs) {
+		t.Fatalf("Struct() = %v, want Errors", err)
+	}
+
+	want := Errors{
+		{Field: "name", Code: "required", Message: "must not be empty"},
+		{Field: "email", Code: "email", Message: "must be a valid email address"},
+	}
+
+	if !reflect.DeepEqual(errs, want) {
+		t.Errorf("Struct() = %+v, want %+v", errs, want)
+	}
+}
@@ -1,31 +1,92 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"os"
 	"time"
 
 	"github.com/huboh/go-rest-api/internal/app/auth"
+	"github.com/huboh/go-rest-api/internal/app/org"
 	"github.com/huboh/go-rest-api/internal/app/user"
 
+	"github.com/huboh/go-rest-api/internal/pkg/database"
 	"github.com/huboh/go-rest-api/internal/pkg/env"
+	"github.com/huboh/go-rest-api/internal/pkg/mail"
 	"github.com/huboh/go-rest-api/internal/pkg/middleware"
+	"github.com/huboh/go-rest-api/internal/pkg/openapi"
+	"github.com/huboh/go-rest-api/internal/pkg/privacy"
 	"github.com/huboh/go-rest-api/internal/pkg/router"
+	"github.com/huboh/go-rest-api/internal/pkg/search"
 	"github.com/huboh/go-rest-api/internal/pkg/server"
+	"github.com/huboh/go-rest-api/internal/pkg/utils"
 )
 
 func main() {
-	if err := start(); err != nil {
+	var err error
+
+	if cmd, ok := getCommand(os.Args[1:]); ok {
+		err = cmd(os.Args[2:])
+	} else {
+		err = start()
+	}
+
+	if err != nil {
 		log.Fatal(err)
 	}
 }
 
+// getCommand returns the subcommand named by the first of args, if any.
+func getCommand(args []string) (command, bool) {
+	if len(args) == 0 {
+		return nil, false
+	}
+
+	cmd, ok := commands[args[0]]
+
+	return cmd, ok
+}
+
 func start() error {
 	if err := env.Load(); err != nil {
 		return err
 	}
 
+	stores, err := openStores(context.Background(), database.NewConfig())
+	if err != nil {
+		return err
+	}
+
+	defer stores.Close()
+
+	mailer, err := mail.NewSender()
+	if err != nil {
+		return err
+	}
+
+	// ctx is canceled before the stores are closed, stopping the background jobs using them
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	services := newServices(stores, mailer)
+
+	// purge the users deleted for longer than the retention period in the background
+	go user.NewPurgeJob(stores.users, services.privacy, user.NewPurgeConfigs()).Run(ctx)
+
+	// erase the personal data of users whose erasure delay is over in the background
+	go services.privacy.Run(ctx)
+
+	root := getRouter(stores, services)
+
+	if err := checkRoutesAuth(root); err != nil {
+		return err
+	}
+
+	if err := printRoutes(log.Writer(), root); err != nil {
+		return err
+	}
+
 	server := server.New(
 		server.NewConfig(
 			// host
@@ -35,7 +96,7 @@ func start() error {
 			os.Getenv("PORT"),
 
 			// router
-			router.New("/", getMiddlewares(), getRoutes()),
+			root,
 		),
 	)
 
@@ -50,29 +111,254 @@ func start() error {
 	return nil
 }
 
-func getRoutes() []router.Route {
-	return []router.Route{
+// services holds the services of the app packages.
+type services struct {
+	privacy *privacy.Service
+	auth    *auth.Service
+	user    *user.Service
+	org     *org.Service
+}
+
+// newServices creates the services of the app packages backed by stores.
+func newServices(stores *stores, mailer mail.Sender) *services {
+	var (
+		privacyService = privacy.NewService(stores.exports, stores.erasures, privacy.NewConfigs())
+		authService    = auth.NewService(stores.users, stores.devices, stores.revocations, stores.tx, auth.NewTokenConfigs())
+		userService    = user.NewService(stores.users, stores.userIndex, authService, stores.tx, privacyService)
+		orgService     = org.NewService(stores.orgs, stores.members, stores.invitations, stores.users, authService, mailer, stores.tx)
+	)
+
+	// every app package holding personal data registers its provider. tokens are revoked before accounts are purged
+	privacyService.Register(authService.PrivacyProvider())
+	privacyService.Register(orgService.PrivacyProvider())
+	privacyService.Register(userService.PrivacyProvider())
+
+	return &services{
+		privacy: privacyService,
+		auth:    authService,
+		user:    userService,
+		org:     orgService,
+	}
+}
+
+// stores holds the persistence backends shared by the app packages.
+type stores struct {
+	// db is the database the stores are backed by. it is nil for in-memory stores.
+	db *database.DB
+
+	tx          database.TxManager
+	users       user.UserRepository
+	devices     auth.DeviceGrantStore
+	revocations auth.RevocationStore
+	exports     privacy.ExportStore
+	erasures    privacy.ErasureStore
+	orgs        org.OrgRepository
+	members     org.MemberRepository
+	invitations org.InvitationRepository
+
+	// userIndex is the search index of users, kept in sync by users.
+	userIndex search.Index
+}
+
+// openStores creates the stores for the driver selected in c.
+func openStores(ctx context.Context, c *database.Config) (_ *stores, err error) {
+	if c.Driver == database.DriverMemory {
+		index := search.NewMemoryIndex(user.SearchWeights)
+		orgs := org.NewMemoryRepository()
+
+		return &stores{
+			tx:          database.NoopTxManager{},
+			users:       user.NewIndexedRepository(user.NewMemoryRepository(), index),
+			devices:     auth.NewMemoryDeviceGrantStore(),
+			revocations: auth.NewMemoryRevocationStore(),
+			exports:     privacy.NewMemoryExportStore(),
+			erasures:    privacy.NewMemoryErasureStore(),
+			orgs:        orgs,
+			members:     orgs,
+			invitations: orgs,
+			userIndex:   index,
+		}, nil
+	}
+
+	db, err := database.Open(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		// the stores own db once they are returned
+		if err != nil {
+			db.Close()
+		}
+	}()
+
+	if env.Get("DB_MIGRATE_ON_START") == "true" {
+		migrator, err := newMigrator(db)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := migrator.Up(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	users := user.UserRepository(user.NewSQLRepository(db))
+	orgs := org.NewSQLRepository(db)
+
+	if db.Driver == database.DriverPostgres {
+		return &stores{
+			db:          db,
+			tx:          db,
+			users:       users,
+			devices:     auth.NewSQLDeviceGrantStore(db),
+			revocations: auth.NewSQLRevocationStore(db),
+			exports:     privacy.NewSQLExportStore(db),
+			erasures:    privacy.NewSQLErasureStore(db),
+			orgs:        orgs,
+			members:     orgs,
+			invitations: orgs,
+			userIndex:   search.NewPostgresIndex(db, "users", "search", "deleted_at IS NULL", "name", "email", "username"),
+		}, nil
+	}
+
+	// sqlite has no suitable full-text search, users are indexed in-process
+	index := search.NewMemoryIndex(user.SearchWeights)
+
+	if err := user.IndexUsers(ctx, users, index); err != nil {
+		return nil, err
+	}
+
+	return &stores{
+		db:          db,
+		tx:          db,
+		users:       user.NewIndexedRepository(users, index),
+		devices:     auth.NewSQLDeviceGrantStore(db),
+		revocations: auth.NewSQLRevocationStore(db),
+		exports:     privacy.NewSQLExportStore(db),
+		erasures:    privacy.NewSQLErasureStore(db),
+		orgs:        orgs,
+		members:     orgs,
+		invitations: orgs,
+		userIndex:   index,
+	}, nil
+}
+
+// Close closes the database backing the stores, if any.
+func (s *stores) Close() error {
+	if s.db != nil {
+		return s.db.Close()
+	}
+
+	return nil
+}
+
+var (
+	// apiInfo describes the api in its OpenAPI document
+	apiInfo = openapi.Info{
+		Title:   "go-rest-api",
+		Version: "1.0.0",
+	}
+
+	// apiV1 is the first version of the api
+	apiV1 = router.Version{Name: "v1"}
+
+	// apiSecuritySchemes are the security schemes named by the Security of routes
+	apiSecuritySchemes = map[string]openapi.SecurityScheme{
+		"bearer": {Type: "http", Scheme: "bearer", Description: "an access token issued by the auth routes"},
+	}
+)
+
+// getRouter creates the root router serving the routes of services.
+func getRouter(stores *stores, services *services) *router.Router {
+	root := router.New("/", getMiddlewares(), []router.Route{})
+
+	// the routes of the app packages are versioned, requests to unversioned paths being served by
+	// the version they name or by the default one
+	root.DefaultVersion = apiV1.Name
+	root.Version(apiV1, func(v1 *router.Router) {
+		v1.Register(getModules(services)...)
+	})
+
+	root.Add(getRoutes(stores, services, root)...)
+
+	return root
+}
+
+// getModules returns the modules of the app packages, adding their routes to the root router.
+func getModules(services *services) []router.Module {
+	// routes of the app packages below are only reachable by authenticated users
+	authenticated := []middleware.Middleware{services.auth.AuthGuardMiddleware}
+
+	return []router.Module{
+		user.NewModule(services.user, authenticated),
+		org.NewModule(services.org, authenticated),
+		auth.NewModule(services.auth),
+	}
+}
+
+// getRoutes returns the routes of the app itself, root being the router serving them.
+func getRoutes(stores *stores, services *services, root *router.Router) []router.Route {
+	routes := []router.Route{
 		{
-			Path:    user.RouterPath,
-			Handler: user.Router,
+			Path:    "/healthz",
+			Method:  http.MethodGet,
+			Handler: getHealthzHandler(stores.db),
+			Summary: "Report the health of the server and its database",
+			Tag:     []string{"meta"},
+			Public:  true,
 		},
 		{
-			Path:    auth.RouterPath,
-			Handler: auth.Router,
+			Path:    "/openapi.json",
+			Method:  http.MethodGet,
+			Handler: openapi.Handler(func() (*openapi.Document, error) { return openapi.Generate(root, apiInfo, apiSecuritySchemes) }),
+			Summary: "Get the OpenAPI document of the api",
+			Tag:     []string{"meta"},
+			Public:  true,
 		},
 		{
-			Path:    "/healthz",
-			Method:  http.MethodGet,
-			Handler: http.HandlerFunc(handleGetHealthz),
+			Path:     "/_debug",
+			Tag:      []string{"debug"},
+			Security: []string{"bearer"},
+			Auth:     []string{"authenticated", "admin"},
+			Handler: router.New(
+				"/_debug",
+				// the admin guard reads the user stored by the auth guard, which must run first
+				[]middleware.Middleware{services.auth.AuthGuardMiddleware, services.user.AdminGuardMiddleware},
+				[]router.Route{
+					{
+						Path:     "/routes",
+						Method:   http.MethodGet,
+						Handler:  getRoutesHandler(root),
+						Summary:  "List the routes served by the server",
+						Response: []router.RouteInfo{},
+					},
+				},
+			),
 		},
 	}
+
+	if !utils.IsProd() {
+		// the docs page loads its scripts from a CDN, it is only meant for local use
+		routes = append(routes, router.Route{
+			Path:    "/docs",
+			Method:  http.MethodGet,
+			Handler: openapi.DocsHandler(apiInfo.Title, "/openapi.json"),
+			Summary: "Browse the api docs",
+			Tag:     []string{"meta"},
+			Public:  true,
+		})
+	}
+
+	return routes
 }
 
+// getMiddlewares returns the middlewares wrapping every route, outermost first: panics of the
+// others, and of the middlewares of nested routers, are recovered. the auth guard isn't one of them,
+// public routes such as the login and device flow ones couldn't be served otherwise. it is applied
+// by the routers needing it instead, see getModules and checkRoutesAuth.
 func getMiddlewares() []middleware.Middleware {
 	return []middleware.Middleware{
-		// auth middlewares
-		auth.AuthGuardMiddleware,
-
 		// standard middlewares
 		middleware.PanicRecoverer,
 		middleware.Logger,